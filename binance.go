@@ -1,8 +1,12 @@
 package binance
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/MartianPay/go-binance/client"
 	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/utils"
 )
 
 type BinanceClient struct {
@@ -15,8 +19,22 @@ type BinanceClient struct {
 }
 
 func NewClient(apiKey, secretKey string) *BinanceClient {
-	c := client.NewClient(apiKey, secretKey)
-	
+	return newBinanceClient(client.NewClient(apiKey, secretKey))
+}
+
+// NewClientWithSigner creates a client that signs requests with signer, for
+// RSA and Ed25519 API keys
+func NewClientWithSigner(signer *utils.Signer) *BinanceClient {
+	return newBinanceClient(client.NewClientWithSigner(signer))
+}
+
+// NewClientWithProvider creates a client that asks provider for credentials on
+// every request, so keys can be rotated without recreating the client
+func NewClientWithProvider(provider utils.CredentialProvider) *BinanceClient {
+	return newBinanceClient(client.NewClientWithProvider(provider))
+}
+
+func newBinanceClient(c *client.Client) *BinanceClient {
 	return &BinanceClient{
 		client:     c,
 		Deposit:    endpoints.NewDepositService(c),
@@ -29,4 +47,18 @@ func NewClient(apiKey, secretKey string) *BinanceClient {
 
 func (b *BinanceClient) SetBaseURL(url string) {
 	b.client.SetBaseURL(url)
-}
\ No newline at end of file
+}
+
+func (b *BinanceClient) SetTimeout(timeout time.Duration) {
+	b.client.SetTimeout(timeout)
+}
+
+// SetTransport replaces the transport of the HTTP client, keeping its timeout
+func (b *BinanceClient) SetTransport(transport http.RoundTripper) {
+	b.client.SetTransport(transport)
+}
+
+// SetHTTPClient replaces the HTTP client used for requests
+func (b *BinanceClient) SetHTTPClient(httpClient *http.Client) {
+	b.client.SetHTTPClient(httpClient)
+}
@@ -0,0 +1,154 @@
+package binancetest
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// apiKeyEntry is a registered API key and what verifies its signatures
+type apiKeyEntry struct {
+	keyType    utils.KeyType
+	secret     []byte
+	rsaKey     *rsa.PublicKey
+	ed25519Key ed25519.PublicKey
+}
+
+// AddHMACKey registers an HMAC API key and its secret
+func (s *Server) AddHMACKey(apiKey, secret string) {
+	s.addKey(apiKey, &apiKeyEntry{keyType: utils.KeyTypeHMAC, secret: []byte(secret)})
+}
+
+// AddRSAKey registers an RSA API key with the public half of its key pair
+func (s *Server) AddRSAKey(apiKey string, pub *rsa.PublicKey) {
+	s.addKey(apiKey, &apiKeyEntry{keyType: utils.KeyTypeRSA, rsaKey: pub})
+}
+
+// AddEd25519Key registers an Ed25519 API key with the public half of its key pair
+func (s *Server) AddEd25519Key(apiKey string, pub ed25519.PublicKey) {
+	s.addKey(apiKey, &apiKeyEntry{keyType: utils.KeyTypeEd25519, ed25519Key: pub})
+}
+
+// RemoveKey stops accepting an API key, as when a key is deleted or rotated out
+func (s *Server) RemoveKey(apiKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, apiKey)
+}
+
+func (s *Server) addKey(name string, key *apiKeyEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[name] = key
+}
+
+// verify checks the API key, timestamp, recvWindow and signature of a signed
+// request the way Binance does; the lock must be held
+func (s *Server) verify(r *http.Request) *apiError {
+	name := r.Header.Get("X-MBX-APIKEY")
+	if name == "" {
+		return newError(http.StatusUnauthorized, -2014, "API-key format invalid.")
+	}
+	key, ok := s.keys[name]
+	if !ok {
+		return newError(http.StatusUnauthorized, -2015, "Invalid API-key, IP, or permissions for action.")
+	}
+
+	// The signature covers the query string up to the signature parameter
+	// followed by the form body, if any
+	payload, signature, ok := splitSignature(r.URL.RawQuery)
+	if !ok {
+		return errMandatory("signature")
+	}
+	if r.Method != http.MethodGet && len(r.PostForm) > 0 {
+		payload += r.PostForm.Encode()
+	}
+
+	if apiErr := s.checkTimestamp(r.Form); apiErr != nil {
+		return apiErr
+	}
+
+	if !key.verify(payload, signature) {
+		return newError(http.StatusBadRequest, -1022, "Signature for this request is not valid.")
+	}
+
+	return nil
+}
+
+// splitSignature splits a raw query into the signed payload and the
+// unescaped signature, which must be the last parameter
+func splitSignature(rawQuery string) (string, string, bool) {
+	i := strings.LastIndex(rawQuery, "signature=")
+	if i < 0 || (i > 0 && rawQuery[i-1] != '&') {
+		return "", "", false
+	}
+
+	signature, err := url.QueryUnescape(rawQuery[i+len("signature="):])
+	if err != nil || signature == "" {
+		return "", "", false
+	}
+
+	return strings.TrimSuffix(rawQuery[:i], "&"), signature, true
+}
+
+func (s *Server) checkTimestamp(p url.Values) *apiError {
+	ts, err := strconv.ParseInt(p.Get("timestamp"), 10, 64)
+	if err != nil {
+		return errMandatory("timestamp")
+	}
+
+	recvWindow := int64(DefaultRecvWindow)
+	if v := p.Get("recvWindow"); v != "" {
+		recvWindow, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || recvWindow <= 0 {
+			return errIllegal("recvWindow")
+		}
+		if recvWindow > MaxRecvWindow {
+			return newError(http.StatusBadRequest, -1131, "recvWindow must be less than 60000")
+		}
+	}
+
+	now := s.nowMillis()
+	if ts >= now+1000 || now-ts > recvWindow {
+		return newError(http.StatusBadRequest, -1021, "Timestamp for this request is outside of the recvWindow.")
+	}
+
+	return nil
+}
+
+func (k *apiKeyEntry) verify(payload, signature string) bool {
+	switch k.keyType {
+	case utils.KeyTypeRSA:
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			return false
+		}
+		digest := sha256.Sum256([]byte(payload))
+		return rsa.VerifyPKCS1v15(k.rsaKey, crypto.SHA256, digest[:], sig) == nil
+
+	case utils.KeyTypeEd25519:
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil {
+			return false
+		}
+		return ed25519.Verify(k.ed25519Key, []byte(payload), sig)
+	}
+
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	h := hmac.New(sha256.New, k.secret)
+	h.Write([]byte(payload))
+	return hmac.Equal(h.Sum(nil), sig)
+}
@@ -0,0 +1,201 @@
+package binancetest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault makes matching requests fail, slow down, or both
+type Fault struct {
+	Method  string            // Empty matches every method
+	Path    string            // Empty matches every path, e.g. "/api/v3/order"
+	Status  int               // HTTP status to respond with; 0 only applies Delay
+	Code    int               // Binance error code, e.g. -1001
+	Msg     string            // Binance error message
+	Headers map[string]string // Extra response headers, e.g. Retry-After
+	Delay   time.Duration     // Added before responding
+	Times   int               // Requests to affect; 0 affects every request until ClearFaults
+	// Processed handles the request before responding with the error, as when
+	// an order reaches the matching engine but its response is lost
+	Processed bool
+}
+
+// InjectFault adds a fault. Faults are matched in the order they were added
+// and the first match applies.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// FailNext makes the next request to method and path fail with the given
+// status and Binance error
+func (s *Server) FailNext(method, path string, status, code int, msg string) {
+	s.InjectFault(Fault{Method: method, Path: path, Status: status, Code: code, Msg: msg, Times: 1})
+}
+
+// ClearFaults removes every injected fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// SetLatency delays every response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// takeFault returns the first fault matching a request and uses up one of its
+// times; the lock must be held
+func (s *Server) takeFault(method, path string) *Fault {
+	for i, f := range s.faults {
+		if (f.Method != "" && f.Method != method) || (f.Path != "" && f.Path != path) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+
+		return f
+	}
+	return nil
+}
+
+// rateLimits tracks IP request weight per minute, separately for /api and
+// /sapi endpoints as Binance does, the UID weight of wallet endpoints per
+// minute, and new orders per 10 seconds and per day
+type rateLimits struct {
+	weightLimit    int
+	uidWeightLimit int
+	orderLimit     int
+
+	minute     int64
+	apiWeight  int
+	sapiWeight int
+	uidWeight  int
+
+	tenSeconds int64
+	orders10s  int
+	day        int64
+	ordersDay  int
+}
+
+// SetWeightLimit makes requests fail with 429 and Retry-After once the
+// request weight used in the current minute would exceed limit. 0, the
+// default, disables the limit; the used weight is reported either way.
+func (s *Server) SetWeightLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits.weightLimit = limit
+}
+
+// SetUIDWeightLimit makes wallet endpoints with a UID weight, such as
+// withdrawals, their history and universal transfers, fail with 429 once the
+// UID weight used in the current minute would exceed limit. Binance allows
+// 180000 per minute. 0, the default, disables the limit.
+func (s *Server) SetUIDWeightLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits.uidWeightLimit = limit
+}
+
+// SetUsedWeight sets the request weight already used in the current minute,
+// as if other clients had been sharing the IP
+func (s *Server) SetUsedWeight(weight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resetWindows()
+	s.limits.apiWeight = weight
+	s.limits.sapiWeight = weight
+}
+
+// SetOrderLimit makes new orders fail with 429 once more than limit orders
+// have been placed in the current 10 seconds. 0, the default, disables the limit.
+func (s *Server) SetOrderLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits.orderLimit = limit
+}
+
+// resetWindows starts new rate limit windows when the clock has moved past
+// the current ones; the lock must be held
+func (s *Server) resetWindows() {
+	now := s.nowMillis()
+	l := &s.limits
+
+	if minute := now - now%60000; minute != l.minute {
+		l.minute, l.apiWeight, l.sapiWeight, l.uidWeight = minute, 0, 0, 0
+	}
+	if tenSeconds := now - now%10000; tenSeconds != l.tenSeconds {
+		l.tenSeconds, l.orders10s = tenSeconds, 0
+	}
+	if day := now - now%86400000; day != l.day {
+		l.day, l.ordersDay = day, 0
+	}
+}
+
+// useWeight counts a request against the rate limits and sets the usage
+// headers; the lock must be held
+func (s *Server) useWeight(w http.ResponseWriter, r *http.Request, weight, uidWeight int) *apiError {
+	s.resetWindows()
+	l := &s.limits
+	now := s.nowMillis()
+
+	used, header := &l.apiWeight, "X-MBX-USED-WEIGHT-1M"
+	if strings.HasPrefix(r.URL.Path, "/sapi/") {
+		used, header = &l.sapiWeight, "X-SAPI-USED-IP-WEIGHT-1M"
+	}
+
+	if l.weightLimit > 0 && *used+weight > l.weightLimit {
+		w.Header().Set(header, strconv.Itoa(*used))
+		w.Header().Set("Retry-After", strconv.FormatInt(retryAfter(now, l.minute+60000), 10))
+		return newError(http.StatusTooManyRequests, -1003,
+			fmt.Sprintf("Too much request weight used; current limit is %d request weight per 1 MINUTE.", l.weightLimit))
+	}
+
+	if uidWeight > 0 {
+		if l.uidWeightLimit > 0 && l.uidWeight+uidWeight > l.uidWeightLimit {
+			w.Header().Set("X-SAPI-USED-UID-WEIGHT-1M", strconv.Itoa(l.uidWeight))
+			w.Header().Set("Retry-After", strconv.FormatInt(retryAfter(now, l.minute+60000), 10))
+			return newError(http.StatusTooManyRequests, -1003,
+				fmt.Sprintf("Too much request weight used; current limit is %d request weight per 1 MINUTE.", l.uidWeightLimit))
+		}
+		l.uidWeight += uidWeight
+		w.Header().Set("X-SAPI-USED-UID-WEIGHT-1M", strconv.Itoa(l.uidWeight))
+	}
+
+	*used += weight
+	w.Header().Set(header, strconv.Itoa(*used))
+
+	if r.Method != http.MethodPost || r.URL.Path != "/api/v3/order" {
+		return nil
+	}
+
+	if l.orderLimit > 0 && l.orders10s >= l.orderLimit {
+		w.Header().Set("Retry-After", strconv.FormatInt(retryAfter(now, l.tenSeconds+10000), 10))
+		return newError(http.StatusTooManyRequests, -1015,
+			fmt.Sprintf("Too many new orders; current limit is %d orders per TEN_SECONDS.", l.orderLimit))
+	}
+
+	l.orders10s++
+	l.ordersDay++
+	w.Header().Set("X-MBX-ORDER-COUNT-10S", strconv.Itoa(l.orders10s))
+	w.Header().Set("X-MBX-ORDER-COUNT-1D", strconv.Itoa(l.ordersDay))
+
+	return nil
+}
+
+// retryAfter returns the whole seconds from now until the window ends at end
+func retryAfter(now, end int64) int64 {
+	return (end - now + 999) / 1000
+}
@@ -0,0 +1,323 @@
+// Package binancetest provides an in-process fake of the Binance spot and
+// wallet REST APIs for testing code that uses BinanceClient.
+//
+// The fake verifies HMAC, RSA and Ed25519 request signatures, timestamps and
+// recvWindow, keeps balances, orders, trades, deposits, withdrawals and
+// transfers in memory, and can inject errors, latency and rate limits:
+//
+//	srv := binancetest.NewServer()
+//	defer srv.Close()
+//
+//	srv.SetBalance("USDT", "1000")
+//	client := srv.NewClient()
+//	order, err := client.Trading.NewOrder(models.NewOrderRequest{...})
+package binancetest
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	binance "github.com/MartianPay/go-binance"
+)
+
+const (
+	// DefaultAPIKey and DefaultSecretKey are the HMAC key every server accepts
+	DefaultAPIKey    = "binancetest-api-key"
+	DefaultSecretKey = "binancetest-secret-key"
+
+	// DefaultRecvWindow is applied to signed requests that do not send recvWindow
+	DefaultRecvWindow = 5000
+	// MaxRecvWindow is the largest recvWindow accepted
+	MaxRecvWindow = 60000
+)
+
+// handler serves one endpoint; it is called with the server lock held
+type handler func(s *Server, p url.Values) (interface{}, *apiError)
+
+// route is an endpoint with its IP weight and, for wallet endpoints that
+// Binance limits per account, its UID weight
+type route struct {
+	signed    bool
+	weight    int
+	uidWeight int
+	handler   handler
+}
+
+// Server is a fake Binance API server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	now    func() time.Time
+	keys   map[string]*apiKeyEntry
+	routes map[string]route
+	state  *state
+
+	faults   []*Fault
+	latency  time.Duration
+	limits   rateLimits
+	requests map[string]int
+}
+
+// NewServer starts a fake server that accepts DefaultAPIKey and knows the
+// BTCUSDT, ETHUSDT, BNBUSDT and ETHBTC symbols and the BTC, ETH, BNB and USDT
+// coins. Balances start empty. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		now:      time.Now,
+		keys:     make(map[string]*apiKeyEntry),
+		state:    newState(),
+		requests: make(map[string]int),
+	}
+	s.state.book = s.newBook()
+	s.routes = s.buildRoutes()
+	s.AddHMACKey(DefaultAPIKey, DefaultSecretKey)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns a BinanceClient that talks to the server with DefaultAPIKey
+func (s *Server) NewClient() *binance.BinanceClient {
+	client := binance.NewClient(DefaultAPIKey, DefaultSecretKey)
+	client.SetBaseURL(s.URL)
+	return client
+}
+
+// SetClock replaces the clock used for timestamp checks, order and deposit
+// times and rate limit windows
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// RequestCount returns how many requests reached method and path, including
+// rejected ones
+func (s *Server) RequestCount(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method+" "+path]
+}
+
+func (s *Server) buildRoutes() map[string]route {
+	return map[string]route{
+		// Market data
+		"GET /api/v3/exchangeInfo": {weight: 20, handler: (*Server).exchangeInfo},
+		"GET /api/v3/klines":       {weight: 2, handler: (*Server).klines},
+		"GET /api/v3/uiKlines":     {weight: 2, handler: (*Server).klines},
+		"GET /api/v3/ticker/price": {weight: 2, handler: (*Server).tickerPrice},
+
+		// Spot trading
+		"POST /api/v3/order":        {signed: true, weight: 1, handler: (*Server).newOrder},
+		"POST /api/v3/order/test":   {signed: true, weight: 1, handler: (*Server).testOrder},
+		"GET /api/v3/order":         {signed: true, weight: 4, handler: (*Server).queryOrder},
+		"DELETE /api/v3/order":      {signed: true, weight: 1, handler: (*Server).cancelOrder},
+		"GET /api/v3/openOrders":    {signed: true, weight: 6, handler: (*Server).openOrders},
+		"DELETE /api/v3/openOrders": {signed: true, weight: 1, handler: (*Server).cancelOpenOrders},
+		"GET /api/v3/allOrders":     {signed: true, weight: 20, handler: (*Server).allOrders},
+		"GET /api/v3/account":       {signed: true, weight: 20, handler: (*Server).account},
+		"GET /api/v3/myTrades":      {signed: true, weight: 20, handler: (*Server).myTrades},
+
+		// Wallet
+		"GET /sapi/v1/capital/config/getall":             {signed: true, weight: 10, handler: (*Server).allCoins},
+		"GET /sapi/v1/account/info":                      {signed: true, weight: 1, handler: (*Server).accountInfo},
+		"GET /sapi/v1/account/status":                    {signed: true, weight: 1, handler: (*Server).accountStatus},
+		"GET /sapi/v1/account/apiTradingStatus":          {signed: true, weight: 1, handler: (*Server).apiTradingStatus},
+		"GET /sapi/v1/account/apiRestrictions":           {signed: true, weight: 1, handler: (*Server).apiRestrictions},
+		"POST /sapi/v1/account/enableFastWithdrawSwitch": {signed: true, weight: 1, handler: (*Server).enableFastWithdraw},
+		"POST /sapi/v1/asset/transfer":                   {signed: true, weight: 1, uidWeight: 900, handler: (*Server).transfer},
+		"GET /sapi/v1/asset/transfer":                    {signed: true, weight: 1, handler: (*Server).transferHistory},
+		"POST /sapi/v3/asset/getUserAsset":               {signed: true, weight: 5, handler: (*Server).userAssets},
+		"POST /sapi/v1/asset/get-funding-asset":          {signed: true, weight: 1, handler: (*Server).fundingAssets},
+		"GET /sapi/v1/asset/tradeFee":                    {signed: true, weight: 1, handler: (*Server).tradeFee},
+		"GET /sapi/v1/asset/assetDividend":               {signed: true, weight: 10, handler: (*Server).assetDividend},
+		"GET /sapi/v1/asset/dribblet":                    {signed: true, weight: 1, handler: (*Server).dustLog},
+		"GET /sapi/v1/capital/deposit/address":           {signed: true, weight: 10, handler: (*Server).depositAddress},
+		"GET /sapi/v1/capital/deposit/address/list":      {signed: true, weight: 10, handler: (*Server).depositAddressList},
+		"GET /sapi/v1/capital/deposit/hisrec":            {signed: true, weight: 1, handler: (*Server).depositHistory},
+		"POST /sapi/v1/capital/withdraw/apply":           {signed: true, weight: 1, uidWeight: 600, handler: (*Server).withdraw},
+		"GET /sapi/v1/capital/withdraw/history":          {signed: true, weight: 1, uidWeight: 18000, handler: (*Server).withdrawHistory},
+		"GET /sapi/v1/capital/withdraw/quota":            {signed: true, weight: 10, handler: (*Server).withdrawQuota},
+		"GET /sapi/v1/capital/withdraw/address/list":     {signed: true, weight: 10, handler: (*Server).withdrawAddressList},
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+
+	s.mu.Lock()
+	s.requests[key]++
+	delay := s.latency
+	fault := s.takeFault(r.Method, r.URL.Path)
+	if fault != nil {
+		delay += fault.Delay
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rt, ok := s.routes[key]
+	if !ok {
+		writeError(w, newError(http.StatusNotFound, -1000, "binancetest: endpoint "+key+" is not implemented"))
+		return
+	}
+
+	if apiErr := s.useWeight(w, r, rt.weight, rt.uidWeight); apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	if fault != nil && fault.Status != 0 && !fault.Processed {
+		for k, v := range fault.Headers {
+			w.Header().Set(k, v)
+		}
+		writeError(w, newError(fault.Status, fault.Code, fault.Msg))
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, newError(http.StatusBadRequest, -1100, "Illegal characters found in a parameter."))
+		return
+	}
+
+	if rt.signed {
+		if apiErr := s.verify(r); apiErr != nil {
+			writeError(w, apiErr)
+			return
+		}
+	}
+
+	result, apiErr := rt.handler(s, r.Form)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	if fault != nil && fault.Status != 0 {
+		for k, v := range fault.Headers {
+			w.Header().Set(k, v)
+		}
+		writeError(w, newError(fault.Status, fault.Code, fault.Msg))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// apiError is an error response in Binance's {"code":-1121,"msg":"Invalid symbol."} format
+type apiError struct {
+	status int
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+}
+
+func newError(status, code int, msg string) *apiError {
+	return &apiError{status: status, Code: code, Msg: msg}
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	writeJSON(w, e.status, e)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func errMandatory(name string) *apiError {
+	return newError(http.StatusBadRequest, -1102, "Mandatory parameter '"+name+"' was not sent, was empty/null, or malformed.")
+}
+
+func errIllegal(name string) *apiError {
+	return newError(http.StatusBadRequest, -1100, "Illegal characters found in parameter '"+name+"'.")
+}
+
+var (
+	errInvalidSymbol = newError(http.StatusBadRequest, -1121, "Invalid symbol.")
+	errUnknownOrder  = newError(http.StatusBadRequest, -2011, "Unknown order sent.")
+	errOrderNotExist = newError(http.StatusBadRequest, -2013, "Order does not exist.")
+)
+
+func required(p url.Values, name string) (string, *apiError) {
+	v := strings.TrimSpace(p.Get(name))
+	if v == "" {
+		return "", errMandatory(name)
+	}
+	return v, nil
+}
+
+func positiveParam(p url.Values, name string) (*big.Rat, *apiError) {
+	v, apiErr := required(p, name)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	r, ok := new(big.Rat).SetString(v)
+	if !ok || r.Sign() <= 0 {
+		return nil, errIllegal(name)
+	}
+	return r, nil
+}
+
+func intParam(p url.Values, name string) (int64, *apiError) {
+	v := p.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, errIllegal(name)
+	}
+	return n, nil
+}
+
+// timeRange parses the optional startTime and endTime parameters
+func timeRange(p url.Values) (start, end int64, apiErr *apiError) {
+	if start, apiErr = intParam(p, "startTime"); apiErr != nil {
+		return 0, 0, apiErr
+	}
+	if end, apiErr = intParam(p, "endTime"); apiErr != nil {
+		return 0, 0, apiErr
+	}
+	return start, end, nil
+}
+
+func inRange(t, start, end int64) bool {
+	return (start == 0 || t >= start) && (end == 0 || t <= end)
+}
+
+// page applies offset and limit, with limit defaulting to def
+func page[T any](items []T, offset, limit, def int64) []T {
+	if limit <= 0 {
+		limit = def
+	}
+	if offset >= int64(len(items)) {
+		return []T{}
+	}
+	items = items[offset:]
+	if int64(len(items)) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// nowMillis returns the server clock in milliseconds; the lock must be held
+func (s *Server) nowMillis() int64 {
+	return s.now().UnixMilli()
+}
@@ -0,0 +1,464 @@
+package binancetest_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	binance "github.com/MartianPay/go-binance"
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/client"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// apiError returns err as an *client.APIError, failing the test if it is not one
+func apiError(t *testing.T, err error) *client.APIError {
+	t.Helper()
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	return apiErr
+}
+
+// spotBalances returns the free spot balances reported by the account endpoint
+func spotBalances(t *testing.T, c *binance.BinanceClient) map[string]string {
+	t.Helper()
+	info, err := c.Trading.GetAccountInfo(0)
+	if err != nil {
+		t.Fatalf("GetAccountInfo: %v", err)
+	}
+	balances := make(map[string]string)
+	for _, b := range info.Balances {
+		balances[b.Asset] = b.Free
+	}
+	return balances
+}
+
+func TestSignatures(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		client   func(srv *binancetest.Server) *binance.BinanceClient
+		clock    time.Duration
+		wantCode int
+	}{
+		{
+			name:   "hmac",
+			client: func(srv *binancetest.Server) *binance.BinanceClient { return srv.NewClient() },
+		},
+		{
+			name: "rsa",
+			client: func(srv *binancetest.Server) *binance.BinanceClient {
+				srv.AddRSAKey("rsa-key", &rsaKey.PublicKey)
+				return binance.NewClientWithSigner(utils.NewRSASigner("rsa-key", rsaKey))
+			},
+		},
+		{
+			name: "ed25519",
+			client: func(srv *binancetest.Server) *binance.BinanceClient {
+				srv.AddEd25519Key("ed25519-key", edPub)
+				return binance.NewClientWithSigner(utils.NewEd25519Signer("ed25519-key", edKey))
+			},
+		},
+		{
+			name: "wrong secret",
+			client: func(srv *binancetest.Server) *binance.BinanceClient {
+				return binance.NewClient(binancetest.DefaultAPIKey, "wrong-secret")
+			},
+			wantCode: -1022,
+		},
+		{
+			name: "unknown key",
+			client: func(srv *binancetest.Server) *binance.BinanceClient {
+				return binance.NewClient("unknown-key", binancetest.DefaultSecretKey)
+			},
+			wantCode: -2015,
+		},
+		{
+			name: "rsa key signed with another key",
+			client: func(srv *binancetest.Server) *binance.BinanceClient {
+				srv.AddRSAKey("rsa-key", &rsaKey.PublicKey)
+				other, err := rsa.GenerateKey(rand.Reader, 2048)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return binance.NewClientWithSigner(utils.NewRSASigner("rsa-key", other))
+			},
+			wantCode: -1022,
+		},
+		{
+			name:     "timestamp outside recvWindow",
+			client:   func(srv *binancetest.Server) *binance.BinanceClient { return srv.NewClient() },
+			clock:    10 * time.Second,
+			wantCode: -1021,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := binancetest.NewServer()
+			defer srv.Close()
+			if tt.clock != 0 {
+				srv.SetClock(func() time.Time { return time.Now().Add(tt.clock) })
+			}
+
+			c := tt.client(srv)
+			c.SetBaseURL(srv.URL)
+
+			_, err := c.Trading.GetAccountInfo(0)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("expected success, got %v", err)
+				}
+				return
+			}
+			if code := apiError(t, err).Code; code != tt.wantCode {
+				t.Errorf("expected code %d, got %d", tt.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestBalances(t *testing.T) {
+	tests := []struct {
+		name     string
+		set      map[string]string
+		withdraw *models.WithdrawalRequest
+		want     map[string]string
+	}{
+		{
+			name: "set balances",
+			set:  map[string]string{"USDT": "1000", "BTC": "0.5"},
+			want: map[string]string{"USDT": "1000.00000000", "BTC": "0.50000000"},
+		},
+		{
+			name: "replaced balance",
+			set:  map[string]string{"ETH": "2.25"},
+			want: map[string]string{"ETH": "2.25000000"},
+		},
+		{
+			name:     "withdrawal debits amount and fee",
+			set:      map[string]string{"USDT": "100"},
+			withdraw: &models.WithdrawalRequest{Coin: "USDT", Network: "TRX", Address: "TXYZ", Amount: "50"},
+			want:     map[string]string{"USDT": "49.00000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := binancetest.NewServer()
+			defer srv.Close()
+			c := srv.NewClient()
+
+			for asset, free := range tt.set {
+				srv.SetBalance(asset, free)
+			}
+			if tt.withdraw != nil {
+				if _, err := c.Withdrawal.Withdraw(*tt.withdraw); err != nil {
+					t.Fatalf("Withdraw: %v", err)
+				}
+			}
+
+			balances := spotBalances(t, c)
+			for asset, want := range tt.want {
+				if balances[asset] != want {
+					t.Errorf("%s: expected %s, got %s", asset, want, balances[asset])
+				}
+			}
+		})
+	}
+}
+
+func TestAccountUpdateTime(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+	c := srv.NewClient()
+
+	start := time.Now().Truncate(time.Millisecond)
+	now := start
+	srv.SetClock(func() time.Time { return now })
+
+	updateTime := func() time.Time {
+		t.Helper()
+		info, err := c.Trading.GetAccountInfo(0)
+		if err != nil {
+			t.Fatalf("GetAccountInfo: %v", err)
+		}
+		return time.UnixMilli(info.UpdateTime)
+	}
+
+	srv.SetBalance("BTC", "1")
+	if got := updateTime(); !got.Equal(start) {
+		t.Fatalf("expected updateTime %s, got %s", start, got)
+	}
+
+	// An idle account keeps the time of its last balance change
+	now = start.Add(time.Second)
+	if got := updateTime(); !got.Equal(start) {
+		t.Errorf("expected an idle account to keep updateTime %s, got %s", start, got)
+	}
+
+	now = start.Add(2 * time.Second)
+	srv.SetBalance("BTC", "2")
+	if got := updateTime(); !got.Equal(now) {
+		t.Errorf("expected updateTime %s after a balance change, got %s", now, got)
+	}
+}
+
+func TestOrderMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances map[string]string
+		req      models.NewOrderRequest
+		// price, when set, moves BTCUSDT after the order is placed
+		price        string
+		wantCode     int
+		wantStatus   models.OrderStatus
+		wantBalances map[string]string
+	}{
+		{
+			name:         "market buy fills at the last price",
+			balances:     map[string]string{"USDT": "10000"},
+			req:          models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "0.1"},
+			wantStatus:   models.OrderStatusFilled,
+			wantBalances: map[string]string{"USDT": "5000.00000000", "BTC": "0.09990000"},
+		},
+		{
+			name:         "limit buy below the price rests with its quote reserved",
+			balances:     map[string]string{"USDT": "10000"},
+			req:          models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeLimit, TimeInForce: models.TimeInForceGTC, Quantity: "0.1", Price: "49000"},
+			wantStatus:   models.OrderStatusNew,
+			wantBalances: map[string]string{"USDT": "5100.00000000"},
+		},
+		{
+			name:         "limit buy fills when the price reaches it",
+			balances:     map[string]string{"USDT": "10000"},
+			req:          models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeLimit, TimeInForce: models.TimeInForceGTC, Quantity: "0.1", Price: "49000"},
+			price:        "48900",
+			wantStatus:   models.OrderStatusFilled,
+			wantBalances: map[string]string{"USDT": "5100.00000000", "BTC": "0.09990000"},
+		},
+		{
+			name:         "stop loss sell triggers below its stop price",
+			balances:     map[string]string{"BTC": "1"},
+			req:          models.NewOrderRequest{Side: models.SideSell, Type: models.OrderTypeStopLoss, Quantity: "1", StopPrice: "49000"},
+			price:        "48000",
+			wantStatus:   models.OrderStatusFilled,
+			wantBalances: map[string]string{"BTC": "0.00000000", "USDT": "47952.00000000"},
+		},
+		{
+			name:       "stop loss sell waits above its stop price",
+			balances:   map[string]string{"BTC": "1"},
+			req:        models.NewOrderRequest{Side: models.SideSell, Type: models.OrderTypeStopLoss, Quantity: "1", StopPrice: "49000"},
+			price:      "49500",
+			wantStatus: models.OrderStatusNew,
+		},
+		{
+			name:     "insufficient balance",
+			balances: map[string]string{"USDT": "100"},
+			req:      models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "0.1"},
+			wantCode: -2010,
+		},
+		{
+			name:     "unknown symbol",
+			balances: map[string]string{"USDT": "100"},
+			req:      models.NewOrderRequest{Symbol: "DOGEUSDT", Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "1"},
+			wantCode: -1121,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := binancetest.NewServer()
+			defer srv.Close()
+			c := srv.NewClient()
+
+			for asset, free := range tt.balances {
+				srv.SetBalance(asset, free)
+			}
+			if tt.req.Symbol == "" {
+				tt.req.Symbol = "BTCUSDT"
+			}
+
+			resp, err := c.Trading.NewOrder(tt.req)
+			if tt.wantCode != 0 {
+				if code := apiError(t, err).Code; code != tt.wantCode {
+					t.Errorf("expected code %d, got %d", tt.wantCode, code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewOrder: %v", err)
+			}
+
+			if tt.price != "" {
+				srv.SetPrice("BTCUSDT", tt.price)
+			}
+
+			order, err := c.Trading.QueryOrder(models.QueryOrderRequest{Symbol: tt.req.Symbol, OrderId: resp.OrderId})
+			if err != nil {
+				t.Fatalf("QueryOrder: %v", err)
+			}
+			if order.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, order.Status)
+			}
+
+			balances := spotBalances(t, c)
+			for asset, want := range tt.wantBalances {
+				if balances[asset] != want {
+					t.Errorf("%s: expected %s, got %s", asset, want, balances[asset])
+				}
+			}
+		})
+	}
+}
+
+func TestFaults(t *testing.T) {
+	history := func(c *binance.BinanceClient) error {
+		_, err := c.Withdrawal.GetWithdrawalHistory(models.WithdrawalHistoryRequest{})
+		return err
+	}
+	account := func(c *binance.BinanceClient) error {
+		_, err := c.Trading.GetAccountInfo(0)
+		return err
+	}
+
+	tests := []struct {
+		name  string
+		setup func(srv *binancetest.Server)
+		call  func(c *binance.BinanceClient) error
+		// calls is the number of times call is made; only the last result is checked
+		calls      int
+		wantStatus int
+		wantCode   int
+	}{
+		{
+			name: "fail next",
+			setup: func(srv *binancetest.Server) {
+				srv.FailNext(http.MethodGet, "/api/v3/account", http.StatusServiceUnavailable, -1001, "Internal error")
+			},
+			call:       account,
+			calls:      1,
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   -1001,
+		},
+		{
+			name: "fault used up",
+			setup: func(srv *binancetest.Server) {
+				srv.FailNext(http.MethodGet, "/api/v3/account", http.StatusServiceUnavailable, -1001, "Internal error")
+			},
+			call:  account,
+			calls: 2,
+		},
+		{
+			name: "fault headers",
+			setup: func(srv *binancetest.Server) {
+				srv.InjectFault(binancetest.Fault{Path: "/api/v3/account", Status: http.StatusTeapot, Code: -1003, Msg: "banned", Headers: map[string]string{"Retry-After": "120"}})
+			},
+			call:       account,
+			calls:      3,
+			wantStatus: http.StatusTeapot,
+			wantCode:   -1003,
+		},
+		{
+			name:       "ip weight limit",
+			setup:      func(srv *binancetest.Server) { srv.SetWeightLimit(30) },
+			call:       account,
+			calls:      2,
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   -1003,
+		},
+		{
+			name:  "withdrawal history is charged uid weight, not ip weight",
+			setup: func(srv *binancetest.Server) { srv.SetWeightLimit(12000) },
+			call:  history,
+			calls: 5,
+		},
+		{
+			name:       "uid weight limit",
+			setup:      func(srv *binancetest.Server) { srv.SetUIDWeightLimit(180000) },
+			call:       history,
+			calls:      11,
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   -1003,
+		},
+		{
+			name: "used weight shared with other clients",
+			setup: func(srv *binancetest.Server) {
+				srv.SetWeightLimit(6000)
+				srv.SetUsedWeight(5990)
+			},
+			call:       account,
+			calls:      1,
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   -1003,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := binancetest.NewServer()
+			defer srv.Close()
+			// A stopped clock keeps every call inside one rate limit window
+			now := time.Now()
+			srv.SetClock(func() time.Time { return now })
+			tt.setup(srv)
+
+			c := srv.NewClient()
+			var err error
+			for i := 0; i < tt.calls; i++ {
+				err = tt.call(c)
+			}
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("expected success, got %v", err)
+				}
+				return
+			}
+			apiErr := apiError(t, err)
+			if apiErr.StatusCode != tt.wantStatus || apiErr.Code != tt.wantCode {
+				t.Errorf("expected status %d code %d, got status %d code %d", tt.wantStatus, tt.wantCode, apiErr.StatusCode, apiErr.Code)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+
+	spec := utils.CredentialSpec{APIKey: binancetest.DefaultAPIKey, SecretKey: binancetest.DefaultSecretKey}
+	provider := utils.NewRotatingProvider(func() (*utils.Credentials, error) { return spec.Credentials() }, 0)
+	c := binance.NewClientWithProvider(provider)
+	c.SetBaseURL(srv.URL)
+
+	if _, err := c.Trading.GetAccountInfo(0); err != nil {
+		t.Fatalf("GetAccountInfo with the first key: %v", err)
+	}
+
+	srv.AddHMACKey("rotated-key", "rotated-secret")
+	spec = utils.CredentialSpec{APIKey: "rotated-key", SecretKey: "rotated-secret"}
+	if err := provider.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	srv.RemoveKey(binancetest.DefaultAPIKey)
+
+	if _, err := c.Trading.GetAccountInfo(0); err != nil {
+		t.Fatalf("GetAccountInfo after rotation: %v", err)
+	}
+}
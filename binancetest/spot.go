@@ -0,0 +1,413 @@
+package binancetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/MartianPay/go-binance/internal/matching"
+	"github.com/MartianPay/go-binance/models"
+)
+
+// newBook creates the order book of the server, settling against the spot
+// wallet; the lock must be held while the book is used
+func (s *Server) newBook() *matching.Book {
+	return &matching.Book{
+		Symbol: func(symbol string) (models.SymbolInfo, bool) {
+			info, ok := s.state.symbols[symbol]
+			return info, ok
+		},
+		Balance: func(asset string) (*big.Rat, *big.Rat) {
+			b := s.state.spot.get(asset)
+			return b.free, b.locked
+		},
+		Fee:      func(maker bool) *big.Rat { return s.state.commission },
+		Now:      s.nowMillis,
+		NewId:    s.state.newId,
+		IdPrefix: "binancetest",
+	}
+}
+
+// orderError converts a matching error into an error response
+func orderError(err error) *apiError {
+	var me *matching.Error
+	if errors.As(err, &me) {
+		return newError(http.StatusBadRequest, me.Code, me.Msg)
+	}
+	return newError(http.StatusInternalServerError, -1000, err.Error())
+}
+
+// ackResponse is the newOrderRespType=ACK response
+type ackResponse struct {
+	Symbol        string `json:"symbol"`
+	OrderId       int64  `json:"orderId"`
+	OrderListId   int64  `json:"orderListId"`
+	ClientOrderId string `json:"clientOrderId"`
+	TransactTime  int64  `json:"transactTime"`
+}
+
+// orderResponse returns the placement response of the given type, leaving
+// out the fields an ACK response does not have
+func orderResponse(o *matching.Order, respType models.OrderResponseType, fills []models.OrderFill) interface{} {
+	resp := o.Response(respType, fills)
+	if respType == models.OrderResponseTypeACK {
+		return ackResponse{
+			Symbol:        resp.Symbol,
+			OrderId:       resp.OrderId,
+			OrderListId:   resp.OrderListId,
+			ClientOrderId: resp.ClientOrderId,
+			TransactTime:  resp.TransactTime,
+		}
+	}
+	return resp
+}
+
+// Market data
+
+func (s *Server) exchangeInfo(p url.Values) (interface{}, *apiError) {
+	symbols, apiErr := s.symbolList(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	info := models.ExchangeInfo{
+		Timezone:   "UTC",
+		ServerTime: s.nowMillis(),
+		RateLimits: []models.RateLimit{
+			{RateLimitType: "REQUEST_WEIGHT", Interval: "MINUTE", IntervalNum: 1, Limit: 6000},
+			{RateLimitType: "ORDERS", Interval: "SECOND", IntervalNum: 10, Limit: 100},
+			{RateLimitType: "ORDERS", Interval: "DAY", IntervalNum: 1, Limit: 200000},
+		},
+		ExchangeFilters: []interface{}{},
+	}
+	for _, symbol := range symbols {
+		info.Symbols = append(info.Symbols, s.state.symbols[symbol])
+	}
+	return info, nil
+}
+
+func (s *Server) tickerPrice(p url.Values) (interface{}, *apiError) {
+	symbols, apiErr := s.symbolList(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	prices := make([]models.TickerPrice, 0, len(symbols))
+	for _, symbol := range symbols {
+		prices = append(prices, models.TickerPrice{Symbol: symbol, Price: format(s.state.prices[symbol])})
+	}
+
+	// A single symbol is returned as an object rather than an array
+	if p.Get("symbol") != "" {
+		return prices[0], nil
+	}
+	return prices, nil
+}
+
+// symbolList returns the symbols selected by the symbol or symbols
+// parameter, or every symbol in alphabetical order
+func (s *Server) symbolList(p url.Values) ([]string, *apiError) {
+	var symbols []string
+	switch {
+	case p.Get("symbol") != "":
+		symbols = []string{p.Get("symbol")}
+	case p.Get("symbols") != "":
+		if err := json.Unmarshal([]byte(p.Get("symbols")), &symbols); err != nil {
+			return nil, errIllegal("symbols")
+		}
+	default:
+		for symbol := range s.state.symbols {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+	}
+
+	for _, symbol := range symbols {
+		if _, ok := s.state.symbols[symbol]; !ok {
+			return nil, errInvalidSymbol
+		}
+	}
+	return symbols, nil
+}
+
+func (s *Server) klines(p url.Values) (interface{}, *apiError) {
+	symbol, apiErr := s.symbolParam(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	interval, apiErr := required(p, "interval")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	start, end, apiErr := timeRange(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	limit, apiErr := intParam(p, "limit")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var rows [][]interface{}
+	for _, k := range s.state.klines[symbol.Symbol+" "+interval] {
+		if inRange(k.OpenTime, start, end) {
+			rows = append(rows, []interface{}{
+				k.OpenTime, k.Open, k.High, k.Low, k.Close, k.Volume, k.CloseTime,
+				k.QuoteAssetVolume, k.NumberOfTrades, k.TakerBuyBaseAssetVolume, k.TakerBuyQuoteAssetVolume, k.Ignore,
+			})
+		}
+	}
+	return page(rows, 0, limit, 500), nil
+}
+
+func (s *Server) symbolParam(p url.Values) (models.SymbolInfo, *apiError) {
+	symbol, apiErr := required(p, "symbol")
+	if apiErr != nil {
+		return models.SymbolInfo{}, apiErr
+	}
+	info, ok := s.state.symbols[symbol]
+	if !ok {
+		return models.SymbolInfo{}, errInvalidSymbol
+	}
+	return info, nil
+}
+
+// Trading
+
+func (s *Server) newOrder(p url.Values) (interface{}, *apiError) {
+	req, apiErr := s.orderRequest(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	o, err := s.state.book.Parse(req)
+	if err != nil {
+		return nil, orderError(err)
+	}
+
+	fills, err := s.state.book.Place(o, s.state.prices[o.Symbol])
+	if err != nil {
+		return nil, orderError(err)
+	}
+
+	return orderResponse(o, matching.RespType(req), fills), nil
+}
+
+func (s *Server) testOrder(p url.Values) (interface{}, *apiError) {
+	req, apiErr := s.orderRequest(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if _, err := s.state.book.Parse(req); err != nil {
+		return nil, orderError(err)
+	}
+	return struct{}{}, nil
+}
+
+// orderRequest reads the parameters of a new order; they are validated by the book
+func (s *Server) orderRequest(p url.Values) (models.NewOrderRequest, *apiError) {
+	info, apiErr := s.symbolParam(p)
+	if apiErr != nil {
+		return models.NewOrderRequest{}, apiErr
+	}
+
+	return models.NewOrderRequest{
+		Symbol:           info.Symbol,
+		Side:             models.OrderSide(p.Get("side")),
+		Type:             models.OrderType(p.Get("type")),
+		TimeInForce:      models.TimeInForce(p.Get("timeInForce")),
+		Quantity:         p.Get("quantity"),
+		QuoteOrderQty:    p.Get("quoteOrderQty"),
+		Price:            p.Get("price"),
+		NewClientOrderId: p.Get("newClientOrderId"),
+		StopPrice:        p.Get("stopPrice"),
+		IcebergQty:       p.Get("icebergQty"),
+		NewOrderRespType: models.OrderResponseType(p.Get("newOrderRespType")),
+	}, nil
+}
+
+// matchOpenOrders triggers stop orders and fills resting orders of symbol
+// after its price changed; the lock must be held
+func (s *Server) matchOpenOrders(symbol string) {
+	s.state.book.OnPrice(symbol, s.state.prices[symbol])
+}
+
+// findOrder looks an order up by orderId or origClientOrderId
+func (s *Server) findOrder(p url.Values) (*matching.Order, *apiError) {
+	info, apiErr := s.symbolParam(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	orderId, apiErr := intParam(p, "orderId")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	o, err := s.state.book.Find(info.Symbol, orderId, p.Get("origClientOrderId"))
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return o, nil
+}
+
+func (s *Server) queryOrder(p url.Values) (interface{}, *apiError) {
+	o, apiErr := s.findOrder(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if o == nil {
+		return nil, errOrderNotExist
+	}
+	o.Sync()
+	return o.Order, nil
+}
+
+func (s *Server) cancelOrder(p url.Values) (interface{}, *apiError) {
+	o, apiErr := s.findOrder(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if o == nil || o.Status.IsTerminal() {
+		return nil, errUnknownOrder
+	}
+	return s.state.book.Cancel(o, p.Get("newClientOrderId")), nil
+}
+
+func (s *Server) cancelOpenOrders(p url.Values) (interface{}, *apiError) {
+	info, apiErr := s.symbolParam(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	canceled := []models.CancelOrderResponse{}
+	for _, o := range s.state.book.Orders {
+		if o.Symbol == info.Symbol && !o.Status.IsTerminal() {
+			canceled = append(canceled, s.state.book.Cancel(o, ""))
+		}
+	}
+	if len(canceled) == 0 {
+		return nil, errUnknownOrder
+	}
+	return canceled, nil
+}
+
+func (s *Server) openOrders(p url.Values) (interface{}, *apiError) {
+	symbol := p.Get("symbol")
+	if _, ok := s.state.symbols[symbol]; symbol != "" && !ok {
+		return nil, errInvalidSymbol
+	}
+
+	orders := []models.Order{}
+	for _, o := range s.state.book.Orders {
+		if (symbol == "" || o.Symbol == symbol) && !o.Status.IsTerminal() {
+			o.Sync()
+			orders = append(orders, o.Order)
+		}
+	}
+	return orders, nil
+}
+
+func (s *Server) allOrders(p url.Values) (interface{}, *apiError) {
+	info, apiErr := s.symbolParam(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	orderId, apiErr := intParam(p, "orderId")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	start, end, apiErr := timeRange(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	limit, apiErr := intParam(p, "limit")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	orders := []models.Order{}
+	for _, o := range s.state.book.Orders {
+		if o.Symbol == info.Symbol && o.OrderId >= orderId && inRange(o.Time, start, end) {
+			o.Sync()
+			orders = append(orders, o.Order)
+		}
+	}
+	return latest(orders, orderId == 0 && start == 0, limit), nil
+}
+
+func (s *Server) myTrades(p url.Values) (interface{}, *apiError) {
+	info, apiErr := s.symbolParam(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	orderId, apiErr := intParam(p, "orderId")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	fromId, apiErr := intParam(p, "fromId")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	start, end, apiErr := timeRange(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	limit, apiErr := intParam(p, "limit")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	trades := []models.Trade{}
+	for _, t := range s.state.book.Trades {
+		if t.Symbol == info.Symbol && (orderId == 0 || t.OrderId == orderId) && t.Id >= fromId && inRange(t.Time, start, end) {
+			trades = append(trades, t)
+		}
+	}
+	return latest(trades, fromId == 0 && start == 0, limit), nil
+}
+
+// latest applies limit to items in ascending order, keeping the most recent
+// items when fromEnd is set and the oldest otherwise. The history endpoints
+// return the most recent records unless an id or startTime is sent.
+func latest[T any](items []T, fromEnd bool, limit int64) []T {
+	if limit <= 0 || limit > 1000 {
+		limit = 500
+	}
+	if fromEnd && int64(len(items)) > limit {
+		return items[int64(len(items))-limit:]
+	}
+	return page(items, 0, limit, limit)
+}
+
+func (s *Server) account(p url.Values) (interface{}, *apiError) {
+	commission, _ := new(big.Rat).Mul(s.state.commission, big.NewRat(10000, 1)).Float64()
+
+	info := models.TradingAccountInfo{
+		MakerCommission: int64(commission),
+		TakerCommission: int64(commission),
+		CanTrade:        true,
+		CanWithdraw:     true,
+		CanDeposit:      true,
+		AccountType:     "SPOT",
+		Balances:        []models.Balance{},
+		Permissions:     []string{"SPOT"},
+	}
+	for _, asset := range s.state.spot.assets() {
+		b := s.state.spot[asset]
+		info.Balances = append(info.Balances, models.Balance{Asset: asset, Free: format(b.free), Locked: format(b.locked)})
+	}
+
+	// updateTime is the last balance change, so it stays put on an idle account.
+	// Changes are noticed when the account is read.
+	seen := fmt.Sprint(info.Balances)
+	if s.state.balancesUpdated == 0 || seen != s.state.balancesSeen {
+		s.state.balancesSeen = seen
+		s.state.balancesUpdated = s.nowMillis()
+	}
+	info.UpdateTime = s.state.balancesUpdated
+	return info, nil
+}
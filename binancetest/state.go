@@ -0,0 +1,408 @@
+package binancetest
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/MartianPay/go-binance/internal/matching"
+	"github.com/MartianPay/go-binance/models"
+)
+
+// balance is the free and locked amount of one asset in a wallet
+type balance struct {
+	free   *big.Rat
+	locked *big.Rat
+}
+
+// wallet holds the balances of one account, such as the spot or funding wallet
+type wallet map[string]*balance
+
+func (w wallet) get(asset string) *balance {
+	b, ok := w[asset]
+	if !ok {
+		b = &balance{free: new(big.Rat), locked: new(big.Rat)}
+		w[asset] = b
+	}
+	return b
+}
+
+// assets returns the assets of the wallet in alphabetical order
+func (w wallet) assets() []string {
+	assets := make([]string, 0, len(w))
+	for asset := range w {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+type state struct {
+	spot    wallet
+	funding wallet
+
+	symbols map[string]models.SymbolInfo
+	prices  map[string]*big.Rat
+	klines  map[string][]models.Kline
+	coins   map[string]models.CoinInfo
+
+	commission *big.Rat
+
+	book        *matching.Book
+	deposits    []models.DepositHistory
+	withdrawals []models.WithdrawalHistory
+	transfers   []models.TransferRecord
+	dividends   []models.AssetDividend
+
+	withdrawAddresses []models.WithdrawalAddress
+
+	// balancesSeen holds the spot balances last returned by the account
+	// endpoint and balancesUpdated the time they were first returned
+	balancesSeen    string
+	balancesUpdated int64
+
+	accountStatus string
+	tradingStatus models.APITradingStatusData
+	restrictions  models.APIRestrictions
+
+	nextId int64
+}
+
+func newState() *state {
+	st := &state{
+		spot:          make(wallet),
+		funding:       make(wallet),
+		symbols:       make(map[string]models.SymbolInfo),
+		prices:        make(map[string]*big.Rat),
+		klines:        make(map[string][]models.Kline),
+		coins:         make(map[string]models.CoinInfo),
+		commission:    big.NewRat(1, 1000),
+		accountStatus: "Normal",
+		restrictions: models.APIRestrictions{
+			EnableReading:              true,
+			EnableSpotAndMarginTrading: true,
+			EnableWithdrawals:          true,
+			EnableInternalTransfer:     true,
+			PermitsUniversalTransfer:   true,
+		},
+	}
+
+	st.addSymbol("BTCUSDT", "BTC", "USDT", "50000")
+	st.addSymbol("ETHUSDT", "ETH", "USDT", "3000")
+	st.addSymbol("BNBUSDT", "BNB", "USDT", "500")
+	st.addSymbol("ETHBTC", "ETH", "BTC", "0.06")
+
+	st.coins["BTC"] = newCoin("BTC", "Bitcoin", network("BTC", "BTC", "0.0002", "0.001"))
+	st.coins["ETH"] = newCoin("ETH", "Ethereum", network("ETH", "ETH", "0.002", "0.01"))
+	st.coins["BNB"] = newCoin("BNB", "BNB", network("BNB", "BSC", "0.0005", "0.01"))
+	st.coins["USDT"] = newCoin("USDT", "TetherUS", network("USDT", "ETH", "5", "10"), network("USDT", "TRX", "1", "10"))
+
+	return st
+}
+
+func (st *state) newId() int64 {
+	st.nextId++
+	return st.nextId
+}
+
+func (st *state) addSymbol(symbol, base, quote, price string) {
+	st.symbols[symbol] = models.SymbolInfo{
+		Symbol:                     symbol,
+		Status:                     "TRADING",
+		BaseAsset:                  base,
+		BaseAssetPrecision:         8,
+		QuoteAsset:                 quote,
+		QuotePrecision:             8,
+		QuoteAssetPrecision:        8,
+		BaseCommissionPrecision:    8,
+		QuoteCommissionPrecision:   8,
+		OrderTypes:                 []string{"LIMIT", "LIMIT_MAKER", "MARKET", "STOP_LOSS", "STOP_LOSS_LIMIT", "TAKE_PROFIT", "TAKE_PROFIT_LIMIT"},
+		QuoteOrderQtyMarketAllowed: true,
+		IsSpotTradingAllowed:       true,
+		Filters: []map[string]interface{}{
+			{"filterType": "PRICE_FILTER", "minPrice": "0.00000001", "maxPrice": "1000000.00000000", "tickSize": "0.00000001"},
+			{"filterType": "LOT_SIZE", "minQty": "0.00000001", "maxQty": "9000000.00000000", "stepSize": "0.00000001"},
+		},
+		Permissions: []string{"SPOT"},
+	}
+	st.prices[symbol] = decimal(price)
+}
+
+func newCoin(coin, name string, networks ...models.NetworkInfo) models.CoinInfo {
+	networks[0].IsDefault = true
+	return models.CoinInfo{
+		Coin:              coin,
+		Name:              name,
+		DepositAllEnable:  true,
+		WithdrawAllEnable: true,
+		Trading:           true,
+		NetworkList:       networks,
+	}
+}
+
+func network(coin, network, fee, min string) models.NetworkInfo {
+	return models.NetworkInfo{
+		Coin:                    coin,
+		Network:                 network,
+		Name:                    network,
+		DepositEnable:           true,
+		WithdrawEnable:          true,
+		MinConfirm:              1,
+		UnLockConfirm:           1,
+		WithdrawFee:             fee,
+		WithdrawMin:             min,
+		WithdrawMax:             "9999999",
+		WithdrawIntegerMultiple: "0.00000001",
+	}
+}
+
+// decimal parses a decimal set up by a test, panicking on invalid input
+func decimal(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic(fmt.Sprintf("binancetest: invalid decimal %q", s))
+	}
+	return r
+}
+
+// format formats r with 8 decimal places, as Binance does
+func format(r *big.Rat) string {
+	return r.FloatString(8)
+}
+
+// SetBalance sets the free spot balance of an asset
+func (s *Server) SetBalance(asset, free string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.spot.get(asset).free = decimal(free)
+}
+
+// SetFundingBalance sets the free funding wallet balance of an asset
+func (s *Server) SetFundingBalance(asset, free string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.funding.get(asset).free = decimal(free)
+}
+
+// Balance returns the spot balance of an asset
+func (s *Server) Balance(asset string) models.Balance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.state.spot.get(asset)
+	return models.Balance{Asset: asset, Free: format(b.free), Locked: format(b.locked)}
+}
+
+// FundingBalance returns the funding wallet balance of an asset
+func (s *Server) FundingBalance(asset string) models.Balance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.state.funding.get(asset)
+	return models.Balance{Asset: asset, Free: format(b.free), Locked: format(b.locked)}
+}
+
+// AddSymbol adds a trading symbol with its current price
+func (s *Server) AddSymbol(symbol, base, quote, price string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.addSymbol(symbol, base, quote, price)
+}
+
+// SetPrice sets the current price of a symbol. Open orders that the new
+// price reaches are triggered and filled.
+func (s *Server) SetPrice(symbol, price string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.prices[symbol] = decimal(price)
+	s.matchOpenOrders(symbol)
+}
+
+// SetKlines sets the klines returned for a symbol and interval, in ascending open time
+func (s *Server) SetKlines(symbol string, interval models.KlineInterval, klines []models.Kline) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.klines[symbol+" "+string(interval)] = klines
+}
+
+// AddCoin adds or replaces a coin and its network configuration
+func (s *Server) AddCoin(coin models.CoinInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.coins[coin.Coin] = coin
+}
+
+// SetCommissionRate sets the maker and taker commission rate, 0.001 by default
+func (s *Server) SetCommissionRate(rate string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.commission = decimal(rate)
+}
+
+// SetAccountStatus sets the account status reported by /sapi/v1/account/status
+func (s *Server) SetAccountStatus(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.accountStatus = status
+}
+
+// SetAPITradingStatus sets the quantitative rules status of the account
+func (s *Server) SetAPITradingStatus(status models.APITradingStatusData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.tradingStatus = status
+}
+
+// SetAPIRestrictions sets the permissions reported for the API key. Only the
+// report changes; requests are not checked against it.
+func (s *Server) SetAPIRestrictions(restrictions models.APIRestrictions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.restrictions = restrictions
+}
+
+// AddDeposit records a deposit, filling in the ID, transaction ID and insert
+// time when empty, and credits it to its wallet if its status is credited
+func (s *Server) AddDeposit(d models.DepositHistory) models.DepositHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.state.newId()
+	if d.Id == "" {
+		d.Id = strconv.FormatInt(id, 10)
+	}
+	if d.TxId == "" {
+		d.TxId = fmt.Sprintf("0xbinancetest%d", id)
+	}
+	if d.InsertTime == 0 {
+		d.InsertTime = s.nowMillis()
+	}
+	if d.Network == "" {
+		if network, ok := s.state.defaultNetwork(d.Coin); ok {
+			d.Network = network.Network
+		}
+	}
+	if d.Status.IsCredited() {
+		b := s.state.walletFor(d.WalletType).get(d.Coin)
+		b.free.Add(b.free, decimal(d.Amount))
+	}
+
+	s.state.deposits = append(s.state.deposits, d)
+	return d
+}
+
+// SetDepositStatus changes the status of a deposit, crediting it when it
+// becomes credited. It reports whether the deposit exists.
+func (s *Server) SetDepositStatus(id string, status models.DepositStatus) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.state.deposits {
+		d := &s.state.deposits[i]
+		if d.Id != id {
+			continue
+		}
+		if status.IsCredited() && !d.Status.IsCredited() {
+			b := s.state.walletFor(d.WalletType).get(d.Coin)
+			b.free.Add(b.free, decimal(d.Amount))
+		}
+		d.Status = status
+		return true
+	}
+	return false
+}
+
+// AddDividend records an asset dividend, filling in the IDs and the time when
+// empty, and credits it to the spot wallet
+func (s *Server) AddDividend(d models.AssetDividend) models.AssetDividend {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d.Id == 0 {
+		d.Id = s.state.newId()
+	}
+	if d.TranId == 0 {
+		d.TranId = s.state.newId()
+	}
+	if d.DivTime == 0 {
+		d.DivTime = s.nowMillis()
+	}
+	b := s.state.spot.get(d.Asset)
+	b.free.Add(b.free, decimal(d.Amount))
+
+	s.state.dividends = append(s.state.dividends, d)
+	return d
+}
+
+// Withdrawals returns every withdrawal applied for, oldest first
+func (s *Server) Withdrawals() []models.WithdrawalHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.WithdrawalHistory(nil), s.state.withdrawals...)
+}
+
+// AddWithdrawalAddress adds an address to the withdrawal address list. Only
+// the list changes; withdrawals are not checked against it.
+func (s *Server) AddWithdrawalAddress(a models.WithdrawalAddress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.withdrawAddresses = append(s.state.withdrawAddresses, a)
+}
+
+// SetWithdrawalStatus changes the status of a withdrawal. Cancelled, rejected
+// and failed withdrawals are refunded. It reports whether the withdrawal exists.
+func (s *Server) SetWithdrawalStatus(id string, status models.WithdrawalStatus) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.state.withdrawals {
+		w := &s.state.withdrawals[i]
+		if w.Id != id {
+			continue
+		}
+		if refunded(status) && !refunded(w.Status) {
+			b := s.state.walletFor(w.WalletType).get(w.Coin)
+			b.free.Add(b.free, decimal(w.Amount))
+			b.free.Add(b.free, decimal(w.TransactionFee))
+		}
+		w.Status = status
+		if status == models.WithdrawalStatusCompleted {
+			w.CompleteTime = s.now().UTC().Format(models.WithdrawalTimeLayout)
+		}
+		return true
+	}
+	return false
+}
+
+func refunded(status models.WithdrawalStatus) bool {
+	return status == models.WithdrawalStatusCancelled || status == models.WithdrawalStatusRejected || status == models.WithdrawalStatusFailure
+}
+
+func (st *state) walletFor(t models.WalletType) wallet {
+	if t == models.WalletTypeFunding {
+		return st.funding
+	}
+	return st.spot
+}
+
+// network returns the configuration of a coin's network, or its default
+// network when name is empty
+func (st *state) network(coin, name string) (models.NetworkInfo, bool) {
+	if name == "" {
+		return st.defaultNetwork(coin)
+	}
+	for _, n := range st.coins[coin].NetworkList {
+		if n.Network == name {
+			return n, true
+		}
+	}
+	return models.NetworkInfo{}, false
+}
+
+func (st *state) defaultNetwork(coin string) (models.NetworkInfo, bool) {
+	for _, n := range st.coins[coin].NetworkList {
+		if n.IsDefault {
+			return n, true
+		}
+	}
+	return models.NetworkInfo{}, false
+}
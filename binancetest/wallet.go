@@ -0,0 +1,425 @@
+package binancetest
+
+import (
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+var errInsufficientWalletBalance = newError(http.StatusBadRequest, -4026, "User has insufficient balance")
+
+func (s *Server) allCoins(p url.Values) (interface{}, *apiError) {
+	names := make([]string, 0, len(s.state.coins))
+	for name := range s.state.coins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	coins := make([]models.CoinInfo, 0, len(names))
+	for _, name := range names {
+		coin := s.state.coins[name]
+		b := s.state.spot.get(name)
+		coin.Free = format(b.free)
+		coin.Locked = format(b.locked)
+		coins = append(coins, coin)
+	}
+	return coins, nil
+}
+
+func (s *Server) accountInfo(p url.Values) (interface{}, *apiError) {
+	return models.AccountInfo{IsMarginEnable: true, IsFutureEnable: true}, nil
+}
+
+func (s *Server) accountStatus(p url.Values) (interface{}, *apiError) {
+	return models.AccountStatus{Data: s.state.accountStatus}, nil
+}
+
+func (s *Server) apiTradingStatus(p url.Values) (interface{}, *apiError) {
+	status := s.state.tradingStatus
+	if status.UpdateTime == 0 {
+		status.UpdateTime = s.nowMillis()
+	}
+	return models.APITradingStatus{Data: status}, nil
+}
+
+func (s *Server) apiRestrictions(p url.Values) (interface{}, *apiError) {
+	return s.state.restrictions, nil
+}
+
+func (s *Server) enableFastWithdraw(p url.Values) (interface{}, *apiError) {
+	return struct{}{}, nil
+}
+
+// transfer moves funds between the spot and funding wallets; other universal
+// transfer types are not supported
+func (s *Server) transfer(p url.Values) (interface{}, *apiError) {
+	t := models.UniversalTransferType(p.Get("type"))
+	var from, to wallet
+	switch t {
+	case models.TransferMainFunding:
+		from, to = s.state.spot, s.state.funding
+	case models.TransferFundingMain:
+		from, to = s.state.funding, s.state.spot
+	case "":
+		return nil, errMandatory("type")
+	default:
+		return nil, newError(http.StatusBadRequest, -1100, "binancetest: transfer type "+string(t)+" is not supported")
+	}
+
+	asset, apiErr := required(p, "asset")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	amount, apiErr := positiveParam(p, "amount")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	src := from.get(asset)
+	if src.free.Cmp(amount) < 0 {
+		return nil, errInsufficientWalletBalance
+	}
+	src.free.Sub(src.free, amount)
+	dst := to.get(asset)
+	dst.free.Add(dst.free, amount)
+
+	record := models.TransferRecord{
+		Asset:     asset,
+		Amount:    format(amount),
+		Type:      t,
+		Status:    "CONFIRMED",
+		TranId:    s.state.newId(),
+		Timestamp: s.nowMillis(),
+	}
+	s.state.transfers = append(s.state.transfers, record)
+
+	return models.AssetTransferResponse{TranId: record.TranId}, nil
+}
+
+func (s *Server) transferHistory(p url.Values) (interface{}, *apiError) {
+	t, apiErr := required(p, "type")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	start, end, apiErr := timeRange(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	current, apiErr := intParam(p, "current")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	size, apiErr := intParam(p, "size")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if current <= 0 {
+		current = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 10
+	}
+
+	// Newest transfers come first
+	var rows []models.TransferRecord
+	for i := len(s.state.transfers) - 1; i >= 0; i-- {
+		r := s.state.transfers[i]
+		if string(r.Type) == t && inRange(r.Timestamp, start, end) {
+			rows = append(rows, r)
+		}
+	}
+
+	history := models.TransferHistory{Total: len(rows)}
+	if len(rows) > 0 {
+		history.Rows = page(rows, (current-1)*size, size, size)
+	}
+	return history, nil
+}
+
+func (s *Server) userAssets(p url.Values) (interface{}, *apiError) {
+	return walletAssets(s.state.spot, p.Get("asset")), nil
+}
+
+func (s *Server) fundingAssets(p url.Values) (interface{}, *apiError) {
+	return walletAssets(s.state.funding, p.Get("asset")), nil
+}
+
+// walletAssets lists the non-zero balances of a wallet, or only asset if set
+func walletAssets(w wallet, asset string) []models.UserAsset {
+	assets := []models.UserAsset{}
+	for _, name := range w.assets() {
+		b := w[name]
+		if (asset != "" && name != asset) || (b.free.Sign() == 0 && b.locked.Sign() == 0) {
+			continue
+		}
+		assets = append(assets, models.UserAsset{
+			Asset:        name,
+			Free:         format(b.free),
+			Locked:       format(b.locked),
+			Freeze:       format(new(big.Rat)),
+			Withdrawing:  format(new(big.Rat)),
+			BtcValuation: format(new(big.Rat)),
+		})
+	}
+	return assets
+}
+
+func (s *Server) tradeFee(p url.Values) (interface{}, *apiError) {
+	symbols, apiErr := s.symbolList(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rate := s.state.commission.FloatString(8)
+	fees := make([]models.TradeFee, 0, len(symbols))
+	for _, symbol := range symbols {
+		fees = append(fees, models.TradeFee{Symbol: symbol, MakerCommission: rate, TakerCommission: rate})
+	}
+	return fees, nil
+}
+
+// coinNetwork returns the coin parameter and the requested or default network
+func (s *Server) coinNetwork(p url.Values) (string, models.NetworkInfo, *apiError) {
+	coin, apiErr := required(p, "coin")
+	if apiErr != nil {
+		return "", models.NetworkInfo{}, apiErr
+	}
+	if _, ok := s.state.coins[coin]; !ok {
+		return "", models.NetworkInfo{}, newError(http.StatusBadRequest, -4003, "This coin is not supported.")
+	}
+	network, ok := s.state.network(coin, p.Get("network"))
+	if !ok {
+		return "", models.NetworkInfo{}, newError(http.StatusBadRequest, -4118, "Network is not supported.")
+	}
+	return coin, network, nil
+}
+
+// depositAddress returns a fixed address per coin and network, so tests can
+// recognise it
+func depositAddress(coin, network string) string {
+	return "binancetest-" + strings.ToLower(coin) + "-" + strings.ToLower(network)
+}
+
+// depositTag is the memo of the account on networks where every account
+// shares one deposit address
+const depositTag = "100000001"
+
+func depositTagFor(n models.NetworkInfo) string {
+	if n.SameAddress {
+		return depositTag
+	}
+	return ""
+}
+
+func (s *Server) depositAddress(p url.Values) (interface{}, *apiError) {
+	coin, network, apiErr := s.coinNetwork(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return models.DepositAddress{Coin: coin, Address: depositAddress(coin, network.Network), Tag: depositTagFor(network)}, nil
+}
+
+func (s *Server) depositAddressList(p url.Values) (interface{}, *apiError) {
+	coin, network, apiErr := s.coinNetwork(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	networks := s.state.coins[coin].NetworkList
+	if p.Get("network") != "" {
+		networks = []models.NetworkInfo{network}
+	}
+
+	addresses := make([]models.DepositAddressListItem, 0, len(networks))
+	for _, n := range networks {
+		item := models.DepositAddressListItem{Coin: coin, Address: depositAddress(coin, n.Network), Tag: depositTagFor(n), Network: n.Network}
+		if n.IsDefault {
+			item.IsDefault = 1
+		}
+		addresses = append(addresses, item)
+	}
+	return addresses, nil
+}
+
+func (s *Server) depositHistory(p url.Values) (interface{}, *apiError) {
+	start, end, apiErr := timeRange(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	offset, apiErr := intParam(p, "offset")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	limit, apiErr := intParam(p, "limit")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	status, apiErr := intParam(p, "status")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	coin, txId := p.Get("coin"), p.Get("txId")
+
+	// Newest deposits come first
+	deposits := []models.DepositHistory{}
+	for i := len(s.state.deposits) - 1; i >= 0; i-- {
+		d := s.state.deposits[i]
+		if (coin != "" && d.Coin != coin) || (txId != "" && d.TxId != txId) ||
+			(p.Get("status") != "" && int64(d.Status) != status) || !inRange(d.InsertTime, start, end) {
+			continue
+		}
+		deposits = append(deposits, d)
+	}
+	return page(deposits, offset, limit, 1000), nil
+}
+
+func (s *Server) withdraw(p url.Values) (interface{}, *apiError) {
+	coin, network, apiErr := s.coinNetwork(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	address, apiErr := required(p, "address")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	amount, apiErr := positiveParam(p, "amount")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	walletType, apiErr := intParam(p, "walletType")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if !network.WithdrawEnable {
+		return nil, newError(http.StatusBadRequest, -4019, "The current currency is not open for withdrawal")
+	}
+	if amount.Cmp(decimal(network.WithdrawMin)) < 0 {
+		return nil, newError(http.StatusBadRequest, -4022, "The withdrawal amount is less than the minimum")
+	}
+
+	// The fee is added to the amount, unless transactionFeeFlag asks for it to
+	// be taken out of the amount
+	fee := decimal(network.WithdrawFee)
+	total := new(big.Rat).Add(amount, fee)
+	if p.Get("transactionFeeFlag") == "true" {
+		total.Set(amount)
+		amount = new(big.Rat).Sub(amount, fee)
+	}
+
+	b := s.state.walletFor(models.WalletType(walletType)).get(coin)
+	if b.free.Cmp(total) < 0 {
+		return nil, errInsufficientWalletBalance
+	}
+	b.free.Sub(b.free, total)
+
+	w := models.WithdrawalHistory{
+		Id:              "binancetest" + strconv.FormatInt(s.state.newId(), 10),
+		Amount:          format(amount),
+		TransactionFee:  format(fee),
+		Coin:            coin,
+		Status:          models.WithdrawalStatusProcessing,
+		Address:         address,
+		ApplyTime:       s.now().UTC().Format(models.WithdrawalTimeLayout),
+		Network:         network.Network,
+		TransferType:    models.TransferTypeExternal,
+		WithdrawOrderId: p.Get("withdrawOrderId"),
+		WalletType:      models.WalletType(walletType),
+	}
+	s.state.withdrawals = append(s.state.withdrawals, w)
+
+	return models.WithdrawalResponse{Id: w.Id}, nil
+}
+
+func (s *Server) withdrawHistory(p url.Values) (interface{}, *apiError) {
+	start, end, apiErr := timeRange(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	offset, apiErr := intParam(p, "offset")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	limit, apiErr := intParam(p, "limit")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	status, apiErr := intParam(p, "status")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	coin, withdrawOrderId := p.Get("coin"), p.Get("withdrawOrderId")
+
+	var ids map[string]bool
+	if p.Get("idList") != "" {
+		ids = make(map[string]bool)
+		for _, id := range strings.Split(p.Get("idList"), ",") {
+			ids[strings.TrimSpace(id)] = true
+		}
+	}
+
+	// Newest withdrawals come first
+	withdrawals := []models.WithdrawalHistory{}
+	for i := len(s.state.withdrawals) - 1; i >= 0; i-- {
+		w := s.state.withdrawals[i]
+		applied, _ := time.Parse(models.WithdrawalTimeLayout, w.ApplyTime)
+		if (coin != "" && w.Coin != coin) || (withdrawOrderId != "" && w.WithdrawOrderId != withdrawOrderId) ||
+			(ids != nil && !ids[w.Id]) || (p.Get("status") != "" && int64(w.Status) != status) || !inRange(applied.UnixMilli(), start, end) {
+			continue
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	return page(withdrawals, offset, limit, 1000), nil
+}
+
+func (s *Server) withdrawQuota(p url.Values) (interface{}, *apiError) {
+	return models.WithdrawalQuota{WdQuota: "8000000", UsedWdQuota: "0"}, nil
+}
+
+func (s *Server) withdrawAddressList(p url.Values) (interface{}, *apiError) {
+	return append([]models.WithdrawalAddress{}, s.state.withdrawAddresses...), nil
+}
+
+// dividendMaxRange is the longest time range the asset dividend endpoint accepts
+const dividendMaxRange = 180 * 24 * time.Hour
+
+func (s *Server) assetDividend(p url.Values) (interface{}, *apiError) {
+	start, end, apiErr := timeRange(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if start != 0 && end != 0 && end-start > dividendMaxRange.Milliseconds() {
+		return nil, newError(http.StatusBadRequest, -1127, "More than 180 days between startTime and endTime.")
+	}
+	limit, apiErr := intParam(p, "limit")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	asset := p.Get("asset")
+
+	// Newest dividends come first
+	rows := []models.AssetDividend{}
+	for i := len(s.state.dividends) - 1; i >= 0; i-- {
+		d := s.state.dividends[i]
+		if (asset != "" && d.Asset != asset) || !inRange(d.DivTime, start, end) {
+			continue
+		}
+		rows = append(rows, d)
+	}
+	total := len(rows)
+	if limit > 500 {
+		limit = 500
+	}
+	return models.AssetDividends{Rows: page(rows, 0, limit, 20), Total: total}, nil
+}
+
+// dustLog returns no conversions; dust conversion is not simulated
+func (s *Server) dustLog(p url.Values) (interface{}, *apiError) {
+	return models.DustLog{UserAssetDribblets: []models.DustConversion{}}, nil
+}
@@ -0,0 +1,173 @@
+package cassette_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	binance "github.com/MartianPay/go-binance"
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/cassette"
+	"github.com/MartianPay/go-binance/models"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order.json")
+	order := models.NewOrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "0.1"}
+
+	srv := binancetest.NewServer()
+	srv.SetBalance("USDT", "10000")
+
+	rec, err := cassette.New(path, cassette.Options{Mode: cassette.ModeRecord})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c := srv.NewClient()
+	c.SetTransport(rec)
+
+	placed, err := c.Trading.NewOrder(order)
+	if err != nil {
+		t.Fatalf("NewOrder: %v", err)
+	}
+	recorded, err := c.Trading.GetAccountInfo(0)
+	if err != nil {
+		t.Fatalf("GetAccountInfo: %v", err)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+	for _, secret := range []string{"signature", "timestamp", binancetest.DefaultAPIKey, binancetest.DefaultSecretKey} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected the cassette not to contain %q:\n%s", secret, data)
+		}
+	}
+
+	// the server is gone, and the replaying client has another key and signs
+	// with a later timestamp
+	time.Sleep(2 * time.Millisecond)
+	rec, err = cassette.New(path, cassette.Options{Mode: cassette.ModeReplay})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer rec.Stop()
+	replay := binance.NewClient("another-key", "another-secret")
+	replay.SetBaseURL("http://127.0.0.1:1")
+	replay.SetTransport(rec)
+
+	replayed, err := replay.Trading.NewOrder(order)
+	if err != nil {
+		t.Fatalf("replayed NewOrder: %v", err)
+	}
+	if replayed.OrderId != placed.OrderId || replayed.Status != placed.Status {
+		t.Errorf("expected order %d %s, got %d %s", placed.OrderId, placed.Status, replayed.OrderId, replayed.Status)
+	}
+	info, err := replay.Trading.GetAccountInfo(0)
+	if err != nil {
+		t.Fatalf("replayed GetAccountInfo: %v", err)
+	}
+	if len(info.Balances) != len(recorded.Balances) {
+		t.Errorf("expected %d balances, got %d", len(recorded.Balances), len(info.Balances))
+	}
+}
+
+// newCassette writes a cassette with one GET and one POST interaction and
+// returns a replaying recorder for it
+func newCassette(t *testing.T) *cassette.Recorder {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c := &cassette.Cassette{Interactions: []cassette.Interaction{
+		{
+			Request:  cassette.Request{Method: http.MethodGet, Path: "/api/v3/order", Query: "orderId=1&symbol=BTCUSDT"},
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: []byte(`{"orderId":1,"status":"FILLED"}`)},
+		},
+		{
+			Request:  cassette.Request{Method: http.MethodPost, Path: "/api/v3/order", Query: "quantity=0.1&side=BUY&symbol=BTCUSDT&type=MARKET"},
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: []byte(`{"orderId":1,"status":"FILLED"}`)},
+		},
+	}}
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	rec, err := cassette.New(path, cassette.Options{Mode: cassette.ModeReplay})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return rec
+}
+
+func roundTrip(rec *cassette.Recorder, method, rawURL string) (string, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestReplayMatchesParamsInAnyOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "recorded order", query: "orderId=1&symbol=BTCUSDT"},
+		{name: "reordered", query: "symbol=BTCUSDT&orderId=1"},
+		{name: "signed", query: "timestamp=1700000000000&symbol=BTCUSDT&orderId=1&signature=abcdef"},
+		{name: "different value", query: "orderId=2&symbol=BTCUSDT", wantErr: true},
+		{name: "extra parameter", query: "orderId=1&symbol=BTCUSDT&recvWindow=5000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newCassette(t)
+			body, err := roundTrip(rec, http.MethodGet, "https://api.binance.com/api/v3/order?"+tt.query)
+			if tt.wantErr {
+				if !errors.Is(err, cassette.ErrNoInteraction) {
+					t.Fatalf("expected ErrNoInteraction, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RoundTrip: %v", err)
+			}
+			if body != `{"orderId":1,"status":"FILLED"}` {
+				t.Errorf("unexpected body %s", body)
+			}
+		})
+	}
+}
+
+func TestReplayRepeatsOnlyGetRequests(t *testing.T) {
+	rec := newCassette(t)
+
+	for i := 0; i < 3; i++ {
+		if _, err := roundTrip(rec, http.MethodGet, "https://api.binance.com/api/v3/order?symbol=BTCUSDT&orderId=1"); err != nil {
+			t.Fatalf("GET %d: %v", i+1, err)
+		}
+	}
+
+	order := "https://api.binance.com/api/v3/order?symbol=BTCUSDT&side=BUY&type=MARKET&quantity=0.1"
+	if _, err := roundTrip(rec, http.MethodPost, order); err != nil {
+		t.Fatalf("first POST: %v", err)
+	}
+	if _, err := roundTrip(rec, http.MethodPost, order); !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Fatalf("expected the second POST to fail with ErrNoInteraction, got %v", err)
+	}
+}
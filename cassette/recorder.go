@@ -0,0 +1,243 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder replays, records, or both
+type Mode int
+
+const (
+	// ModeReplay serves every request from the cassette and never touches the
+	// network; requests without a recorded interaction fail with ErrNoInteraction
+	ModeReplay Mode = iota
+	// ModeRecord sends every request and records a new cassette, replacing
+	// the existing one on Stop
+	ModeRecord
+	// ModeReplayOrRecord replays matching interactions and sends and records
+	// the rest, appending them to the cassette on Stop
+	ModeReplayOrRecord
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	case ModeReplayOrRecord:
+		return "replay-or-record"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// ParseMode parses "replay", "record" or "replay-or-record", e.g. from an
+// environment variable that switches CI to replay and developers to record
+func ParseMode(s string) (Mode, error) {
+	for _, m := range []Mode{ModeReplay, ModeRecord, ModeReplayOrRecord} {
+		if m.String() == strings.ToLower(strings.TrimSpace(s)) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown cassette mode %q", s)
+}
+
+// Options configure a Recorder
+type Options struct {
+	Mode Mode
+	// Transport sends requests when recording; http.DefaultTransport when nil
+	Transport http.RoundTripper
+	// IgnoreParams are left out of matching and recording in addition to
+	// signature and timestamp, e.g. newClientOrderId when it is random
+	IgnoreParams []string
+	// BeforeSave, when set, is called on every new interaction before it is
+	// recorded, to scrub account data such as addresses from response bodies
+	BeforeSave func(*Interaction)
+}
+
+// Recorder is an http.RoundTripper that records and replays interactions.
+// Set it as the transport of a client with SetTransport, and call Stop when
+// done to write what was recorded.
+type Recorder struct {
+	path string
+	opts Options
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+	recorded bool
+}
+
+// New creates a recorder for the cassette at path. The cassette must exist in
+// ModeReplay; in ModeReplayOrRecord it is created if missing.
+func New(path string, opts Options) (*Recorder, error) {
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+
+	r := &Recorder{path: path, opts: opts, cassette: &Cassette{}}
+
+	if opts.Mode != ModeRecord {
+		c, err := Load(path)
+		switch {
+		case err == nil:
+			r.cassette = c
+		case opts.Mode == ModeReplayOrRecord && errors.Is(err, fs.ErrNotExist):
+		default:
+			return nil, err
+		}
+	}
+
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// RoundTrip replays or records a request according to the mode
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := r.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.opts.Mode != ModeRecord {
+		if i, ok := r.match(recorded); ok {
+			return newResponse(req, i.Response), nil
+		}
+		if r.opts.Mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s?%s", ErrNoInteraction, recorded.Method, recorded.Path, recorded.Query)
+		}
+	}
+
+	return r.record(req, recorded)
+}
+
+// Stop writes the cassette if anything new was recorded
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.recorded {
+		return nil
+	}
+	return r.cassette.Save(r.path)
+}
+
+// Interactions returns the interactions of the cassette, including those
+// recorded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// recordRequest builds the scrubbed, normalized form of a request. The body is
+// read and restored so the request can still be sent.
+func (r *Recorder) recordRequest(req *http.Request) (Request, error) {
+	query, err := normalizeQuery(req.URL.RawQuery, r.opts.IgnoreParams)
+	if err != nil {
+		return Request{}, err
+	}
+
+	recorded := Request{Method: req.Method, Path: req.URL.Path, Query: query}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return Request{}, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		recorded.Body = string(body)
+	}
+
+	return recorded, nil
+}
+
+// match finds the first unused interaction for a request. Once every match has
+// been used a GET request replays the last one again, so polling past the end
+// of a recording keeps seeing the final state; other methods change state and
+// are never replayed twice.
+func (r *Recorder) match(req Request) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, interaction := range r.cassette.Interactions {
+		if interaction.Request != req {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return interaction, true
+		}
+		last = i
+	}
+
+	if last >= 0 && req.Method == http.MethodGet {
+		return r.cassette.Interactions[last], true
+	}
+	return Interaction{}, false
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := r.opts.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    scrubHeaders(resp.Header),
+		},
+	}
+	interaction.Response.SetBody(body)
+	if r.opts.BeforeSave != nil {
+		r.opts.BeforeSave(&interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.used = append(r.used, true)
+	r.recorded = true
+	r.mu.Unlock()
+
+	// The caller gets the real response, not the scrubbed one
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func newResponse(req *http.Request, recorded Response) *http.Response {
+	body := recorded.GetBody()
+	header := make(http.Header)
+	for name, values := range recorded.Headers {
+		header[name] = append([]string(nil), values...)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
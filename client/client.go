@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"time"
 
 	"github.com/MartianPay/go-binance/utils"
@@ -16,26 +17,57 @@ const (
 	DefaultTimeout = 30 * time.Second
 )
 
+// APIError is returned when the API responds with a non-200 status code
+type APIError struct {
+	StatusCode int
+	Code       int    `json:"code"`
+	Message    string `json:"msg"`
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// newAPIError builds an APIError from a response, decoding Binance's
+// {"code":-1121,"msg":"Invalid symbol."} error body when present
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Body:       string(body),
+	}
+	_ = json.Unmarshal(body, apiErr)
+	return apiErr
+}
+
 type Client struct {
-	apiKey     string
-	secretKey  string
 	baseURL    string
 	httpClient *http.Client
 	signer     *utils.Signer
 }
 
 func NewClient(apiKey, secretKey string) *Client {
+	return NewClientWithSigner(utils.NewSigner(apiKey, secretKey))
+}
+
+// NewClientWithSigner creates a client that signs requests with signer, which
+// may hold an HMAC, RSA or Ed25519 key
+func NewClientWithSigner(signer *utils.Signer) *Client {
 	return &Client{
-		apiKey:    apiKey,
-		secretKey: secretKey,
-		baseURL:   BaseURL,
+		baseURL: BaseURL,
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
-		signer: utils.NewSigner(apiKey, secretKey),
+		signer: signer,
 	}
 }
 
+// NewClientWithProvider creates a client that asks provider for credentials on
+// every request, so keys can be rotated while the client is in use
+func NewClientWithProvider(provider utils.CredentialProvider) *Client {
+	return NewClientWithSigner(utils.NewProviderSigner(provider))
+}
+
 func (c *Client) SetBaseURL(url string) {
 	c.baseURL = url
 }
@@ -44,14 +76,31 @@ func (c *Client) SetTimeout(timeout time.Duration) {
 	c.httpClient.Timeout = timeout
 }
 
+// SetTransport replaces the transport of the HTTP client, keeping its timeout,
+// e.g. to record and replay requests with a cassette
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+// SetHTTPClient replaces the HTTP client used for requests
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
 func (c *Client) doRequest(method, endpoint string, params map[string]string, body interface{}, needSign bool) ([]byte, error) {
+	values := make(neturl.Values, len(params))
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	return c.doValuesRequest(method, endpoint, values, body, needSign)
+}
+
+func (c *Client) doValuesRequest(method, endpoint string, params neturl.Values, body interface{}, needSign bool) ([]byte, error) {
 	url := c.baseURL + endpoint
 
-	var queryString string
-	if needSign {
-		queryString = c.signer.Sign(params)
-	} else if len(params) > 0 {
-		queryString = c.signer.BuildQueryString(params)
+	queryString, headers, err := c.signer.SignRequest(params, needSign)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
 	}
 
 	if queryString != "" {
@@ -72,7 +121,6 @@ func (c *Client) doRequest(method, endpoint string, params map[string]string, bo
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	headers := c.signer.GetHeaders()
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
@@ -93,7 +141,7 @@ func (c *Client) doRequest(method, endpoint string, params map[string]string, bo
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp.StatusCode, respBody)
 	}
 
 	return respBody, nil
@@ -110,3 +158,12 @@ func (c *Client) Post(endpoint string, params map[string]string, body interface{
 func (c *Client) Delete(endpoint string, params map[string]string, needSign bool) ([]byte, error) {
 	return c.doRequest(http.MethodDelete, endpoint, params, nil, needSign)
 }
+
+func (c *Client) Put(endpoint string, params map[string]string, body interface{}, needSign bool) ([]byte, error) {
+	return c.doRequest(http.MethodPut, endpoint, params, body, needSign)
+}
+
+// PostValues is like Post but accepts repeated parameters, such as asset=BTC&asset=ETH
+func (c *Client) PostValues(endpoint string, params neturl.Values, needSign bool) ([]byte, error) {
+	return c.doValuesRequest(http.MethodPost, endpoint, params, nil, needSign)
+}
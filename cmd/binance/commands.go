@@ -0,0 +1,581 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// newFlagSet creates the flag set of a command; errors are returned rather than exiting
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// parseTime accepts RFC 3339 times, dates (YYYY-MM-DD, UTC) and millisecond timestamps
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339, YYYY-MM-DD or milliseconds", s)
+}
+
+func parseRange(start, end string) (time.Time, time.Time, error) {
+	s, err := parseTime(start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	en, err := parseTime(end)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return s, en, nil
+}
+
+func formatMillis(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+func runAccount(e *env, args []string) error {
+	fs := newFlagSet(e, "account")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	info, err := e.client.Trading.GetAccountInfo(0)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(keyValues(info,
+		"accountType", info.AccountType,
+		"canTrade", strconv.FormatBool(info.CanTrade),
+		"canWithdraw", strconv.FormatBool(info.CanWithdraw),
+		"canDeposit", strconv.FormatBool(info.CanDeposit),
+		"makerCommission", strconv.FormatInt(info.MakerCommission, 10),
+		"takerCommission", strconv.FormatInt(info.TakerCommission, 10),
+		"permissions", strings.Join(info.Permissions, ","),
+		"updateTime", formatMillis(info.UpdateTime),
+	))
+}
+
+func runBalances(e *env, args []string) error {
+	fs := newFlagSet(e, "balances")
+	all := fs.Bool("all", false, "include zero balances")
+	asset := fs.String("asset", "", "only show this asset")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	info, err := e.client.Trading.GetAccountInfo(0)
+	if err != nil {
+		return err
+	}
+
+	var balances []models.Balance
+	r := &result{headers: []string{"ASSET", "FREE", "LOCKED", "TOTAL"}}
+	for _, b := range info.Balances {
+		if *asset != "" && !strings.EqualFold(b.Asset, *asset) {
+			continue
+		}
+		var p utils.DecimalParser
+		total := new(big.Rat).Add(p.Parse(b.Free), p.Parse(b.Locked))
+		if p.Err != nil {
+			return fmt.Errorf("invalid %s balance: %w", b.Asset, p.Err)
+		}
+		if total.Sign() == 0 && !*all {
+			continue
+		}
+		balances = append(balances, b)
+		r.rows = append(r.rows, []string{b.Asset, b.Free, b.Locked, utils.FormatDecimal(total, 8)})
+	}
+	r.raw = balances
+
+	return e.out.print(r)
+}
+
+var orderHeaders = []string{"SYMBOL", "ORDER_ID", "CLIENT_ORDER_ID", "SIDE", "TYPE", "STATUS", "PRICE", "ORIG_QTY", "EXECUTED_QTY", "TIME"}
+
+func orderRow(o models.Order) []string {
+	return []string{o.Symbol, strconv.FormatInt(o.OrderId, 10), o.ClientOrderId, string(o.Side), string(o.Type), string(o.Status), o.Price, o.OrigQty, o.ExecutedQty, formatMillis(o.Time)}
+}
+
+func runOrders(e *env, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: binance orders place|test|cancel|get|list [flags]")
+	}
+
+	switch args[0] {
+	case "place":
+		return runPlaceOrder(e, args[1:], false)
+	case "test":
+		return runPlaceOrder(e, args[1:], true)
+	case "cancel":
+		return runCancelOrder(e, args[1:])
+	case "get":
+		return runGetOrder(e, args[1:])
+	case "list":
+		return runListOrders(e, args[1:])
+	}
+	return fmt.Errorf("unknown orders subcommand %q", args[0])
+}
+
+func runPlaceOrder(e *env, args []string, test bool) error {
+	fs := newFlagSet(e, "orders place")
+	symbol := fs.String("symbol", "", "symbol, e.g. BTCUSDT (required)")
+	side := fs.String("side", "", "BUY or SELL (required)")
+	orderType := fs.String("type", string(models.OrderTypeLimit), "order type, e.g. LIMIT, MARKET, LIMIT_MAKER")
+	quantity := fs.String("qty", "", "base asset quantity")
+	quoteQty := fs.String("quote-qty", "", "quote asset quantity for MARKET orders")
+	price := fs.String("price", "", "limit price")
+	stopPrice := fs.String("stop-price", "", "stop price")
+	tif := fs.String("tif", "", "time in force: GTC, IOC or FOK (LIMIT orders default to GTC)")
+	clientId := fs.String("client-id", "", "client order ID")
+	yes := fs.Bool("yes", false, "place the order without confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *symbol == "" || *side == "" {
+		return errors.New("-symbol and -side are required")
+	}
+
+	req := models.NewOrderRequest{
+		Symbol:           strings.ToUpper(*symbol),
+		Side:             models.OrderSide(strings.ToUpper(*side)),
+		Type:             models.OrderType(strings.ToUpper(*orderType)),
+		TimeInForce:      models.TimeInForce(strings.ToUpper(*tif)),
+		Quantity:         *quantity,
+		QuoteOrderQty:    *quoteQty,
+		Price:            *price,
+		StopPrice:        *stopPrice,
+		NewClientOrderId: *clientId,
+	}
+	if req.Type == models.OrderTypeLimit && req.TimeInForce == "" {
+		req.TimeInForce = models.TimeInForceGTC
+	}
+
+	if test {
+		if err := e.client.Trading.TestNewOrder(req); err != nil {
+			return err
+		}
+		return e.out.print(keyValues(map[string]string{"result": "ok"}, "result", "order accepted by the test endpoint"))
+	}
+
+	if !*yes && !e.confirm(fmt.Sprintf("Place %s %s order on %s for %s%s at %s?", req.Side, req.Type, req.Symbol, req.Quantity, req.QuoteOrderQty, req.Price)) {
+		return errors.New("order not placed")
+	}
+
+	resp, err := e.client.Trading.NewOrder(req)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(keyValues(resp,
+		"symbol", resp.Symbol,
+		"orderId", strconv.FormatInt(resp.OrderId, 10),
+		"clientOrderId", resp.ClientOrderId,
+		"status", string(resp.Status),
+		"price", resp.Price,
+		"origQty", resp.OrigQty,
+		"executedQty", resp.ExecutedQty,
+		"cummulativeQuoteQty", resp.CummulativeQuoteQty,
+	))
+}
+
+func runCancelOrder(e *env, args []string) error {
+	fs := newFlagSet(e, "orders cancel")
+	symbol := fs.String("symbol", "", "symbol (required)")
+	orderId := fs.Int64("id", 0, "order ID")
+	clientId := fs.String("client-id", "", "client order ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *symbol == "" || (*orderId == 0 && *clientId == "") {
+		return errors.New("-symbol and one of -id or -client-id are required")
+	}
+
+	resp, err := e.client.Trading.CancelOrder(models.CancelOrderRequest{
+		Symbol:            strings.ToUpper(*symbol),
+		OrderId:           *orderId,
+		OrigClientOrderId: *clientId,
+	})
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(keyValues(resp,
+		"symbol", resp.Symbol,
+		"orderId", strconv.FormatInt(resp.OrderId, 10),
+		"clientOrderId", resp.OrigClientOrderId,
+		"status", string(resp.Status),
+		"executedQty", resp.ExecutedQty,
+	))
+}
+
+func runGetOrder(e *env, args []string) error {
+	fs := newFlagSet(e, "orders get")
+	symbol := fs.String("symbol", "", "symbol (required)")
+	orderId := fs.Int64("id", 0, "order ID")
+	clientId := fs.String("client-id", "", "client order ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *symbol == "" || (*orderId == 0 && *clientId == "") {
+		return errors.New("-symbol and one of -id or -client-id are required")
+	}
+
+	order, err := e.client.Trading.QueryOrder(models.QueryOrderRequest{
+		Symbol:            strings.ToUpper(*symbol),
+		OrderId:           *orderId,
+		OrigClientOrderId: *clientId,
+	})
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(&result{raw: order, headers: orderHeaders, rows: [][]string{orderRow(*order)}})
+}
+
+func runListOrders(e *env, args []string) error {
+	fs := newFlagSet(e, "orders list")
+	symbol := fs.String("symbol", "", "symbol (required unless -open)")
+	open := fs.Bool("open", false, "list open orders only")
+	start := fs.String("start", "", "start time")
+	end := fs.String("end", "", "end time")
+	limit := fs.Int("limit", 0, "maximum number of orders when no start time is given")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var orders []models.Order
+	var err error
+	switch {
+	case *open:
+		orders, err = e.client.Trading.GetOpenOrders(models.OpenOrdersRequest{Symbol: strings.ToUpper(*symbol)})
+	case *symbol == "":
+		return errors.New("-symbol is required")
+	default:
+		startTime, endTime, perr := parseRange(*start, *end)
+		if perr != nil {
+			return perr
+		}
+		req := models.AllOrdersRequest{Symbol: strings.ToUpper(*symbol), StartTime: startTime, EndTime: endTime, Limit: *limit}
+		if startTime.IsZero() {
+			orders, err = e.client.Trading.GetAllOrders(req)
+		} else {
+			orders, err = e.client.Trading.IterateAllOrders(req).All()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	r := &result{raw: orders, headers: orderHeaders}
+	for _, o := range orders {
+		r.rows = append(r.rows, orderRow(o))
+	}
+	return e.out.print(r)
+}
+
+func runTrades(e *env, args []string) error {
+	fs := newFlagSet(e, "trades")
+	symbol := fs.String("symbol", "", "symbol (required)")
+	start := fs.String("start", "", "start time")
+	end := fs.String("end", "", "end time")
+	limit := fs.Int("limit", 0, "maximum number of trades when no start time is given")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *symbol == "" {
+		return errors.New("-symbol is required")
+	}
+
+	startTime, endTime, err := parseRange(*start, *end)
+	if err != nil {
+		return err
+	}
+
+	req := models.MyTradesRequest{Symbol: strings.ToUpper(*symbol), StartTime: startTime, EndTime: endTime, Limit: *limit}
+	var trades []models.Trade
+	if startTime.IsZero() {
+		trades, err = e.client.Trading.GetMyTrades(req)
+	} else {
+		trades, err = e.client.Trading.IterateMyTrades(req).All()
+	}
+	if err != nil {
+		return err
+	}
+
+	r := &result{raw: trades, headers: []string{"SYMBOL", "TRADE_ID", "ORDER_ID", "SIDE", "PRICE", "QTY", "QUOTE_QTY", "COMMISSION", "COMMISSION_ASSET", "MAKER", "TIME"}}
+	for _, t := range trades {
+		side := "SELL"
+		if t.IsBuyer {
+			side = "BUY"
+		}
+		r.rows = append(r.rows, []string{t.Symbol, strconv.FormatInt(t.Id, 10), strconv.FormatInt(t.OrderId, 10), side, t.Price, t.Qty, t.QuoteQty, t.Commission, t.CommissionAsset, strconv.FormatBool(t.IsMaker), formatMillis(t.Time)})
+	}
+	return e.out.print(r)
+}
+
+func runDeposit(e *env, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: binance deposit address|history [flags]")
+	}
+
+	switch args[0] {
+	case "address":
+		fs := newFlagSet(e, "deposit address")
+		coin := fs.String("coin", "", "coin (required)")
+		network := fs.String("network", "", "network (default network if empty)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *coin == "" {
+			return errors.New("-coin is required")
+		}
+
+		addr, err := e.client.Deposit.GetDepositAddress(models.DepositAddressRequest{Coin: strings.ToUpper(*coin), Network: *network})
+		if err != nil {
+			return err
+		}
+		return e.out.print(keyValues(addr, "coin", addr.Coin, "address", addr.Address, "tag", addr.Tag, "url", addr.URL))
+
+	case "history":
+		fs := newFlagSet(e, "deposit history")
+		coin := fs.String("coin", "", "coin")
+		start := fs.String("start", "", "start time (default 90 days ago)")
+		end := fs.String("end", "", "end time (default now)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		startTime, endTime, err := parseRange(*start, *end)
+		if err != nil {
+			return err
+		}
+
+		deposits, err := e.client.Deposit.IterateDepositHistory(models.DepositHistoryRequest{Coin: strings.ToUpper(*coin), StartTime: startTime, EndTime: endTime}).All()
+		if err != nil {
+			return err
+		}
+
+		r := &result{raw: deposits, headers: []string{"ID", "COIN", "AMOUNT", "NETWORK", "STATUS", "ADDRESS", "TAG", "TX_ID", "CONFIRMATIONS", "TIME"}}
+		for _, d := range deposits {
+			r.rows = append(r.rows, []string{d.Id, d.Coin, d.Amount, d.Network, d.Status.String(), d.Address, d.AddressTag, d.TxId, d.ConfirmTimes, formatMillis(d.InsertTime)})
+		}
+		return e.out.print(r)
+	}
+
+	return fmt.Errorf("unknown deposit subcommand %q", args[0])
+}
+
+func runWithdraw(e *env, args []string) error {
+	fs := newFlagSet(e, "withdraw")
+	coin := fs.String("coin", "", "coin (required)")
+	network := fs.String("network", "", "network (default network if empty)")
+	address := fs.String("address", "", "destination address (required)")
+	tag := fs.String("tag", "", "destination memo or tag")
+	amount := fs.String("amount", "", "amount (required)")
+	orderId := fs.String("id", "", "withdrawOrderId; when set the withdrawal is submitted at most once")
+	anyAddress := fs.Bool("any-address", false, "allow addresses missing from the account's withdrawal address list")
+	yes := fs.Bool("yes", false, "submit without confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *coin == "" || *address == "" || *amount == "" {
+		return errors.New("-coin, -address and -amount are required")
+	}
+
+	req := models.WithdrawalRequest{
+		Coin:            strings.ToUpper(*coin),
+		Network:         *network,
+		Address:         *address,
+		AddressTag:      *tag,
+		Amount:          *amount,
+		WithdrawOrderId: *orderId,
+	}
+
+	// Resolve the default network now so the confirmation names the network
+	// the funds will actually be sent on
+	coins := endpoints.NewCoinCache(e.client.Account, 0)
+	if req.Network == "" {
+		info, ok, err := coins.Coin(req.Coin)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("unknown coin %s", req.Coin)
+		}
+		for _, n := range info.NetworkList {
+			if n.IsDefault {
+				req.Network = n.Network
+				break
+			}
+		}
+		if req.Network == "" {
+			return fmt.Errorf("%s has no default network; set -network", req.Coin)
+		}
+	}
+
+	destination := req.Address
+	if req.AddressTag != "" {
+		destination += " (memo " + req.AddressTag + ")"
+	}
+	if !*yes && !e.confirm(fmt.Sprintf("Withdraw %s %s to %s on network %s?", req.Amount, req.Coin, destination, req.Network)) {
+		return errors.New("withdrawal not submitted")
+	}
+
+	guard := endpoints.NewWithdrawalGuard(e.client.Withdrawal, endpoints.WithdrawalPolicy{
+		RequireAllowlist: !*anyAddress,
+		Coins:            coins,
+	})
+	if !*anyAddress {
+		if err := guard.SyncAllowlist(); err != nil {
+			return err
+		}
+	}
+
+	if req.WithdrawOrderId != "" {
+		res, err := guard.WithdrawOnce(req, endpoints.WithdrawOnceOptions{})
+		if err != nil {
+			return err
+		}
+		return e.out.print(keyValues(res, "id", res.Id, "withdrawOrderId", res.WithdrawOrderId, "existing", strconv.FormatBool(res.Existing)))
+	}
+
+	resp, err := guard.Withdraw(req)
+	if err != nil {
+		return err
+	}
+	return e.out.print(keyValues(resp, "id", resp.Id))
+}
+
+func runWithdrawals(e *env, args []string) error {
+	fs := newFlagSet(e, "withdrawals")
+	coin := fs.String("coin", "", "coin")
+	start := fs.String("start", "", "start time (default 90 days ago)")
+	end := fs.String("end", "", "end time (default now)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	startTime, endTime, err := parseRange(*start, *end)
+	if err != nil {
+		return err
+	}
+
+	withdrawals, err := e.client.Withdrawal.IterateWithdrawalHistory(models.WithdrawalHistoryRequest{Coin: strings.ToUpper(*coin), StartTime: startTime, EndTime: endTime}).All()
+	if err != nil {
+		return err
+	}
+
+	r := &result{raw: withdrawals, headers: []string{"ID", "WITHDRAW_ORDER_ID", "COIN", "AMOUNT", "FEE", "NETWORK", "STATUS", "ADDRESS", "TX_ID", "APPLY_TIME"}}
+	for _, w := range withdrawals {
+		r.rows = append(r.rows, []string{w.Id, w.WithdrawOrderId, w.Coin, w.Amount, w.TransactionFee, w.Network, w.Status.String(), w.Address, w.TxId, w.ApplyTime})
+	}
+	return e.out.print(r)
+}
+
+func runQuota(e *env, args []string) error {
+	fs := newFlagSet(e, "quota")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	quota, err := e.client.Withdrawal.GetWithdrawalQuota()
+	if err != nil {
+		return err
+	}
+
+	var p utils.DecimalParser
+	remaining := new(big.Rat).Sub(p.Parse(quota.WdQuota), p.Parse(quota.UsedWdQuota))
+	if p.Err != nil {
+		return fmt.Errorf("invalid withdrawal quota: %w", p.Err)
+	}
+	return e.out.print(keyValues(quota, "wdQuota", quota.WdQuota, "usedWdQuota", quota.UsedWdQuota, "remaining", utils.FormatDecimal(remaining, 8)))
+}
+
+func runExchangeInfo(e *env, args []string) error {
+	fs := newFlagSet(e, "exchange-info")
+	symbol := fs.String("symbol", "", "only show this symbol")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	info, err := e.client.Market.GetExchangeInfo(models.ExchangeInfoRequest{Symbol: strings.ToUpper(*symbol)})
+	if err != nil {
+		return err
+	}
+
+	r := &result{raw: info, headers: []string{"SYMBOL", "STATUS", "BASE", "QUOTE", "ORDER_TYPES"}}
+	for _, s := range info.Symbols {
+		r.rows = append(r.rows, []string{s.Symbol, s.Status, s.BaseAsset, s.QuoteAsset, strings.Join(s.OrderTypes, ",")})
+	}
+	return e.out.print(r)
+}
+
+func runKlines(e *env, args []string) error {
+	fs := newFlagSet(e, "klines")
+	symbol := fs.String("symbol", "", "symbol (required)")
+	interval := fs.String("interval", string(models.Interval1h), "interval, e.g. 1m, 1h, 1d")
+	start := fs.String("start", "", "start time")
+	end := fs.String("end", "", "end time")
+	limit := fs.Int("limit", 0, "number of klines (default 500, max 1500)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *symbol == "" {
+		return errors.New("-symbol is required")
+	}
+
+	startTime, endTime, err := parseRange(*start, *end)
+	if err != nil {
+		return err
+	}
+
+	req := models.KlineRequest{Symbol: strings.ToUpper(*symbol), Interval: models.KlineInterval(*interval), Limit: *limit}
+	if !startTime.IsZero() {
+		req.StartTime = startTime.UnixMilli()
+	}
+	if !endTime.IsZero() {
+		req.EndTime = endTime.UnixMilli()
+	}
+
+	klines, err := e.client.Market.GetKlines(req)
+	if err != nil {
+		return err
+	}
+
+	r := &result{raw: klines, headers: []string{"OPEN_TIME", "OPEN", "HIGH", "LOW", "CLOSE", "VOLUME", "QUOTE_VOLUME", "TRADES"}}
+	for _, k := range klines {
+		r.rows = append(r.rows, []string{formatMillis(k.OpenTime), k.Open, k.High, k.Low, k.Close, k.Volume, k.QuoteAssetVolume, strconv.Itoa(k.NumberOfTrades)})
+	}
+	return e.out.print(r)
+}
@@ -0,0 +1,122 @@
+// Command binance is a scriptable command-line client for the Binance spot and
+// wallet APIs.
+//
+//	binance [-profile name] [-config file] [-o table|json|csv] <command> [flags]
+//
+// Credentials come from a named profile in the configuration file (default
+// ~/.binance/config.json, see package config), overridden by BINANCE_API_KEY,
+// BINANCE_SECRET_KEY and the other BINANCE_* environment variables.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	binance "github.com/MartianPay/go-binance"
+	"github.com/MartianPay/go-binance/config"
+)
+
+// env is what a command runs with
+type env struct {
+	client *binance.BinanceClient
+	out    *printer
+	stdin  *bufio.Reader
+	stderr io.Writer
+}
+
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"account":       {"show account permissions and commission rates", runAccount},
+	"balances":      {"list spot balances", runBalances},
+	"orders":        {"place, test, cancel, get or list orders", runOrders},
+	"trades":        {"list trades of a symbol", runTrades},
+	"deposit":       {"show a deposit address or the deposit history", runDeposit},
+	"withdraw":      {"submit a withdrawal after confirmation", runWithdraw},
+	"withdrawals":   {"list the withdrawal history", runWithdrawals},
+	"quota":         {"show the 24 hour withdrawal quota", runQuota},
+	"exchange-info": {"list symbols and their trading rules", runExchangeInfo},
+	"klines":        {"list klines of a symbol", runKlines},
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "binance:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("binance", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	profileName := fs.String("profile", "", "configuration profile (default $BINANCE_PROFILE or the file's default)")
+	configFile := fs.String("config", config.DefaultPath(), "configuration file")
+	format := fs.String("o", formatTable, "output format: table, json or csv")
+	fs.Usage = func() { usage(stderr, fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no command given")
+	}
+
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	out, err := newPrinter(stdout, *format)
+	if err != nil {
+		return err
+	}
+
+	client, err := config.NewClient(*configFile, *profileName)
+	if err != nil {
+		return err
+	}
+
+	return cmd.run(&env{client: client, out: out, stdin: bufio.NewReader(stdin), stderr: stderr}, fs.Args()[1:])
+}
+
+func usage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: binance [flags] <command> [command flags]")
+	fmt.Fprintln(w, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintln(w, "\nFlags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\nRun 'binance <command> -h' for command flags.")
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin
+func (e *env) confirm(question string) bool {
+	fmt.Fprintf(e.stderr, "%s [y/N] ", question)
+	answer, _ := e.stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// result is the output of a command: raw is encoded as JSON, headers and rows
+// are used for table and CSV output
+type result struct {
+	raw     interface{}
+	headers []string
+	rows    [][]string
+}
+
+// printer writes results in the selected format
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return &printer{w: w, format: format}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (want table, json or csv)", format)
+}
+
+func (p *printer) print(r *result) error {
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r.raw)
+
+	case formatCSV:
+		w := csv.NewWriter(p.w)
+		if err := w.Write(r.headers); err != nil {
+			return err
+		}
+		if err := w.WriteAll(r.rows); err != nil {
+			return err
+		}
+		return w.Error()
+
+	default:
+		w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(r.headers, "\t"))
+		for _, row := range r.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+}
+
+// keyValues builds a two column result from alternating keys and values
+func keyValues(raw interface{}, pairs ...string) *result {
+	r := &result{raw: raw, headers: []string{"FIELD", "VALUE"}}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		r.rows = append(r.rows, []string{pairs[i], pairs[i+1]})
+	}
+	return r
+}
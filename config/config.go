@@ -0,0 +1,345 @@
+// Package config loads named credential profiles (for example prod, testnet or
+// a sub-account) from a JSON file, applies environment overrides and builds
+// ready to use clients.
+//
+// A configuration file looks like:
+//
+//	{
+//	  "default": "prod",
+//	  "defaults": {"timeout": "10s"},
+//	  "profiles": {
+//	    "prod":    {"api_key": "...", "key_type": "ED25519", "private_key_file": "~/.binance/prod.pem"},
+//	    "testnet": {"api_key": "...", "secret_key": "...", "testnet": true},
+//	    "sub-a":   {"api_key": "...", "secret_key_file": "/run/secrets/sub-a"},
+//	    "vault":   {"credential_command": ["vault-binance", "prod"], "credential_refresh": "5m"}
+//	  }
+//	}
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	binance "github.com/MartianPay/go-binance"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// TestnetBaseURL is the base URL of the spot testnet
+const TestnetBaseURL = "https://testnet.binance.vision"
+
+// Environment variables read by the loader. Variables that set profile fields
+// override the values from the file.
+const (
+	EnvConfigFile     = "BINANCE_CONFIG"
+	EnvProfile        = "BINANCE_PROFILE"
+	EnvAPIKey         = "BINANCE_API_KEY"
+	EnvSecretKey      = "BINANCE_SECRET_KEY"
+	EnvKeyType        = "BINANCE_KEY_TYPE"
+	EnvPrivateKeyFile = "BINANCE_PRIVATE_KEY_FILE"
+	EnvBaseURL        = "BINANCE_BASE_URL"
+	EnvTimeout        = "BINANCE_TIMEOUT"
+)
+
+// ErrProfileNotFound is returned when the requested profile is not in the file
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Profile holds the credentials and environment of one account
+type Profile struct {
+	APIKey string `json:"api_key,omitempty"`
+	// KeyType is HMAC (default), RSA or ED25519
+	KeyType utils.KeyType `json:"key_type,omitempty"`
+	// SecretKey or SecretKeyFile holds the secret of an HMAC key
+	SecretKey     string `json:"secret_key,omitempty"`
+	SecretKeyFile string `json:"secret_key_file,omitempty"`
+	// PrivateKeyFile is the PEM private key of an RSA or Ed25519 key
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	// CredentialFile is a JSON utils.CredentialSpec file and CredentialCommand
+	// a command, such as a secrets manager CLI, that prints one. Either replaces
+	// the key fields above and is read again every CredentialRefresh (such as
+	// "5m"; never when empty), so rotated keys take effect without a restart.
+	CredentialFile    string   `json:"credential_file,omitempty"`
+	CredentialCommand []string `json:"credential_command,omitempty"`
+	CredentialRefresh string   `json:"credential_refresh,omitempty"`
+	BaseURL           string   `json:"base_url,omitempty"`
+	// Testnet selects TestnetBaseURL when BaseURL is empty
+	Testnet bool `json:"testnet,omitempty"`
+	// Timeout is an HTTP timeout such as "10s"
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// File is a configuration file
+type File struct {
+	// Default names the profile used when none is requested
+	Default string `json:"default,omitempty"`
+	// Defaults holds values applied to every profile that does not set them
+	Defaults Profile            `json:"defaults,omitempty"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// DefaultPath returns $BINANCE_CONFIG, or ~/.binance/config.json
+func DefaultPath() string {
+	if path := os.Getenv(EnvConfigFile); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".binance", "config.json")
+}
+
+// Load reads a configuration file. A missing file yields an empty configuration,
+// so profiles can come from the environment alone.
+func Load(path string) (*File, error) {
+	f := &File{Profiles: make(map[string]Profile)}
+	if path == "" {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(expandHome(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if f.Profiles == nil {
+		f.Profiles = make(map[string]Profile)
+	}
+	return f, nil
+}
+
+// Profile returns the named profile with defaults and environment overrides
+// applied. An empty name selects $BINANCE_PROFILE, then the file's default.
+// With no name and no default the profile comes from the environment alone.
+func (f *File) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+	}
+	if name == "" {
+		name = f.Default
+	}
+
+	var p Profile
+	if name != "" {
+		var ok bool
+		if p, ok = f.Profiles[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+		}
+	}
+
+	p.merge(f.Defaults)
+	if err := p.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if p.KeyType == "" {
+		p.KeyType = utils.KeyTypeHMAC
+	}
+	p.KeyType = utils.KeyType(strings.ToUpper(string(p.KeyType)))
+
+	return &p, nil
+}
+
+// merge fills fields that are not set from defaults. Key fields and a
+// credential file or command are never mixed between the two.
+func (p *Profile) merge(defaults Profile) {
+	switch {
+	case p.hasCredentialSource():
+	case p.APIKey == "" && defaults.hasCredentialSource():
+		p.CredentialFile, p.CredentialCommand = defaults.CredentialFile, defaults.CredentialCommand
+	default:
+		if p.APIKey == "" {
+			p.APIKey = defaults.APIKey
+		}
+		if p.KeyType == "" {
+			p.KeyType = defaults.KeyType
+		}
+		if p.SecretKey == "" && p.SecretKeyFile == "" {
+			p.SecretKey, p.SecretKeyFile = defaults.SecretKey, defaults.SecretKeyFile
+		}
+		if p.PrivateKeyFile == "" {
+			p.PrivateKeyFile = defaults.PrivateKeyFile
+		}
+	}
+	if p.CredentialRefresh == "" {
+		p.CredentialRefresh = defaults.CredentialRefresh
+	}
+	if p.BaseURL == "" && !p.Testnet {
+		p.BaseURL, p.Testnet = defaults.BaseURL, defaults.Testnet
+	}
+	if p.Timeout == "" {
+		p.Timeout = defaults.Timeout
+	}
+}
+
+// applyEnv overrides fields from the environment. Credentials are replaced as
+// a complete set when BINANCE_API_KEY is set, so an environment key is never
+// paired with the secret or private key of the profile.
+func (p *Profile) applyEnv() error {
+	if v := os.Getenv(EnvAPIKey); v != "" {
+		p.APIKey = v
+		p.KeyType = utils.KeyType(os.Getenv(EnvKeyType))
+		p.SecretKey, p.SecretKeyFile = os.Getenv(EnvSecretKey), ""
+		p.PrivateKeyFile = os.Getenv(EnvPrivateKeyFile)
+		p.CredentialFile, p.CredentialCommand = "", nil
+	} else {
+		for _, name := range []string{EnvSecretKey, EnvKeyType, EnvPrivateKeyFile} {
+			if os.Getenv(name) != "" {
+				return fmt.Errorf("%s is set without %s", name, EnvAPIKey)
+			}
+		}
+	}
+	if v := os.Getenv(EnvBaseURL); v != "" {
+		p.BaseURL = v
+	}
+	if v := os.Getenv(EnvTimeout); v != "" {
+		p.Timeout = v
+	}
+	return nil
+}
+
+// hasCredentialSource reports whether credentials come from a file or command
+func (p *Profile) hasCredentialSource() bool {
+	return p.CredentialFile != "" || len(p.CredentialCommand) > 0
+}
+
+// Signer builds the request signer of the profile, reading key files as needed
+func (p *Profile) Signer() (*utils.Signer, error) {
+	if p.hasCredentialSource() {
+		return p.providerSigner()
+	}
+	if p.APIKey == "" {
+		return nil, errors.New("api_key is not set")
+	}
+
+	switch p.KeyType {
+	case utils.KeyTypeHMAC, "":
+		secret := p.SecretKey
+		if secret == "" && p.SecretKeyFile != "" {
+			data, err := os.ReadFile(expandHome(p.SecretKeyFile))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secret key file: %w", err)
+			}
+			secret = strings.TrimSpace(string(data))
+		}
+		if secret == "" {
+			return nil, errors.New("secret_key or secret_key_file is required for HMAC keys")
+		}
+		return utils.NewSigner(p.APIKey, secret), nil
+
+	case utils.KeyTypeRSA, utils.KeyTypeEd25519:
+		if p.PrivateKeyFile == "" {
+			return nil, fmt.Errorf("private_key_file is required for %s keys", p.KeyType)
+		}
+		data, err := os.ReadFile(expandHome(p.PrivateKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+		return utils.NewSignerFromPEM(p.APIKey, p.KeyType, data)
+	}
+
+	return nil, fmt.Errorf("unknown key type %q", p.KeyType)
+}
+
+// providerSigner builds a signer that reads credentials from the profile's
+// credential file or command, reloading them every CredentialRefresh
+func (p *Profile) providerSigner() (*utils.Signer, error) {
+	if p.APIKey != "" {
+		return nil, errors.New("api_key cannot be combined with credential_file or credential_command")
+	}
+
+	var refresh time.Duration
+	if p.CredentialRefresh != "" {
+		var err error
+		if refresh, err = time.ParseDuration(p.CredentialRefresh); err != nil {
+			return nil, fmt.Errorf("invalid credential_refresh %q: %w", p.CredentialRefresh, err)
+		}
+	}
+
+	var provider *utils.RotatingProvider
+	switch {
+	case p.CredentialFile != "" && len(p.CredentialCommand) > 0:
+		return nil, errors.New("credential_file and credential_command cannot both be set")
+	case p.CredentialFile != "":
+		provider = utils.NewFileProvider(expandHome(p.CredentialFile), refresh)
+	default:
+		provider = utils.NewCommandProvider(p.CredentialCommand[0], p.CredentialCommand[1:], refresh)
+	}
+
+	// Load once so a missing file or failing command is reported here rather
+	// than on the first request
+	creds, err := provider.Credentials()
+	if err != nil {
+		return nil, err
+	}
+	creds.Release()
+
+	return utils.NewProviderSigner(provider), nil
+}
+
+// NewClient builds a client for the profile
+func (p *Profile) NewClient() (*binance.BinanceClient, error) {
+	signer, err := p.Signer()
+	if err != nil {
+		return nil, err
+	}
+
+	c := binance.NewClientWithSigner(signer)
+
+	switch {
+	case p.BaseURL != "":
+		c.SetBaseURL(p.BaseURL)
+	case p.Testnet:
+		c.SetBaseURL(TestnetBaseURL)
+	}
+
+	if p.Timeout != "" {
+		timeout, err := time.ParseDuration(p.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", p.Timeout, err)
+		}
+		c.SetTimeout(timeout)
+	}
+
+	return c, nil
+}
+
+// NewClient loads the configuration from path (DefaultPath when empty) and
+// builds a client for the named profile
+func NewClient(path, profile string) (*binance.BinanceClient, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := f.Profile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.NewClient()
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,379 @@
+package config_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MartianPay/go-binance/config"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// writeFile writes data to name in a temporary directory and returns its path
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// clearEnv unsets the variables the loader reads for the duration of the test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		config.EnvConfigFile, config.EnvProfile, config.EnvAPIKey, config.EnvSecretKey,
+		config.EnvKeyType, config.EnvPrivateKeyFile, config.EnvBaseURL, config.EnvTimeout,
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestProfileCredentialSource(t *testing.T) {
+	clearEnv(t)
+	creds := writeFile(t, "creds.json", `{"api_key": "rotated-key", "secret_key": "rotated-secret"}`)
+
+	tests := []struct {
+		name       string
+		defaults   config.Profile
+		profile    config.Profile
+		wantAPIKey string
+		wantErr    bool
+	}{
+		{
+			name:       "credential file",
+			profile:    config.Profile{CredentialFile: creds, CredentialRefresh: "5m"},
+			wantAPIKey: "rotated-key",
+		},
+		{
+			name:       "credential command",
+			profile:    config.Profile{CredentialCommand: []string{"cat", creds}},
+			wantAPIKey: "rotated-key",
+		},
+		{
+			name:       "credential file from the defaults",
+			defaults:   config.Profile{CredentialFile: creds},
+			wantAPIKey: "rotated-key",
+		},
+		{
+			name:       "key fields take precedence over a default credential file",
+			defaults:   config.Profile{CredentialFile: creds},
+			profile:    config.Profile{APIKey: "own-key", SecretKey: "own-secret"},
+			wantAPIKey: "own-key",
+		},
+		{
+			name:       "default key fields are not mixed with a credential file",
+			defaults:   config.Profile{APIKey: "default-key", SecretKey: "default-secret"},
+			profile:    config.Profile{CredentialFile: creds},
+			wantAPIKey: "rotated-key",
+		},
+		{
+			name:    "api key and credential file",
+			profile: config.Profile{APIKey: "own-key", CredentialFile: creds},
+			wantErr: true,
+		},
+		{
+			name:    "credential file and command",
+			profile: config.Profile{CredentialFile: creds, CredentialCommand: []string{"cat", creds}},
+			wantErr: true,
+		},
+		{
+			name:    "missing credential file",
+			profile: config.Profile{CredentialFile: filepath.Join(t.TempDir(), "missing.json")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid refresh",
+			profile: config.Profile{CredentialFile: creds, CredentialRefresh: "often"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &config.File{Defaults: tt.defaults, Profiles: map[string]config.Profile{"p": tt.profile}}
+			p, err := f.Profile("p")
+			if err != nil {
+				t.Fatalf("Profile: %v", err)
+			}
+
+			signer, err := p.Signer()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Signer: %v", err)
+			}
+
+			headers, err := signer.Headers()
+			if err != nil {
+				t.Fatalf("Headers: %v", err)
+			}
+			if got := headers["X-MBX-APIKEY"]; got != tt.wantAPIKey {
+				t.Errorf("expected API key %q, got %q", tt.wantAPIKey, got)
+			}
+		})
+	}
+}
+
+func TestProfileSelection(t *testing.T) {
+	f := &config.File{
+		Default: "prod",
+		Profiles: map[string]config.Profile{
+			"prod":    {APIKey: "prod-key", SecretKey: "prod-secret"},
+			"testnet": {APIKey: "testnet-key", SecretKey: "testnet-secret", Testnet: true},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		envProfile string
+		file       *config.File
+		wantAPIKey string
+		wantErr    error
+	}{
+		{name: "named", profile: "testnet", envProfile: "prod", wantAPIKey: "testnet-key"},
+		{name: "from the environment", envProfile: "testnet", wantAPIKey: "testnet-key"},
+		{name: "file default", wantAPIKey: "prod-key"},
+		{name: "unknown", profile: "staging", wantErr: config.ErrProfileNotFound},
+		{name: "unknown from the environment", envProfile: "staging", wantErr: config.ErrProfileNotFound},
+		{name: "no default", file: &config.File{}, wantAPIKey: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv(config.EnvProfile, tt.envProfile)
+
+			file := f
+			if tt.file != nil {
+				file = tt.file
+			}
+			p, err := file.Profile(tt.profile)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Profile: %v", err)
+			}
+			if p.APIKey != tt.wantAPIKey {
+				t.Errorf("expected API key %q, got %q", tt.wantAPIKey, p.APIKey)
+			}
+			if p.KeyType != utils.KeyTypeHMAC {
+				t.Errorf("expected the HMAC key type by default, got %s", p.KeyType)
+			}
+		})
+	}
+}
+
+func TestProfileMergesDefaults(t *testing.T) {
+	clearEnv(t)
+	f := &config.File{
+		Defaults: config.Profile{
+			APIKey:        "default-key",
+			SecretKeyFile: "/run/secrets/default",
+			BaseURL:       "https://api1.binance.com",
+			Timeout:       "10s",
+		},
+		Profiles: map[string]config.Profile{
+			"bare":    {},
+			"secret":  {APIKey: "own-key", SecretKey: "own-secret"},
+			"testnet": {Testnet: true, Timeout: "30s"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		want config.Profile
+	}{
+		{
+			name: "bare",
+			want: config.Profile{APIKey: "default-key", KeyType: utils.KeyTypeHMAC, SecretKeyFile: "/run/secrets/default", BaseURL: "https://api1.binance.com", Timeout: "10s"},
+		},
+		{
+			// an inline secret is not paired with the default secret file
+			name: "secret",
+			want: config.Profile{APIKey: "own-key", KeyType: utils.KeyTypeHMAC, SecretKey: "own-secret", BaseURL: "https://api1.binance.com", Timeout: "10s"},
+		},
+		{
+			// testnet replaces the default base URL rather than competing with it
+			name: "testnet",
+			want: config.Profile{APIKey: "default-key", KeyType: utils.KeyTypeHMAC, SecretKeyFile: "/run/secrets/default", Testnet: true, Timeout: "30s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := f.Profile(tt.name)
+			if err != nil {
+				t.Fatalf("Profile: %v", err)
+			}
+			if !reflect.DeepEqual(*p, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, *p)
+			}
+		})
+	}
+}
+
+func TestProfileEnvironmentOverrides(t *testing.T) {
+	f := &config.File{Profiles: map[string]config.Profile{
+		"prod": {APIKey: "file-key", KeyType: utils.KeyTypeEd25519, PrivateKeyFile: "/keys/prod.pem", Timeout: "10s"},
+	}}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    config.Profile
+		wantErr bool
+	}{
+		{
+			name: "no overrides",
+			want: config.Profile{APIKey: "file-key", KeyType: utils.KeyTypeEd25519, PrivateKeyFile: "/keys/prod.pem", Timeout: "10s"},
+		},
+		{
+			name: "credentials replaced as a set",
+			env:  map[string]string{config.EnvAPIKey: "env-key", config.EnvSecretKey: "env-secret"},
+			want: config.Profile{APIKey: "env-key", KeyType: utils.KeyTypeHMAC, SecretKey: "env-secret", Timeout: "10s"},
+		},
+		{
+			name: "environment key type is upper cased",
+			env:  map[string]string{config.EnvAPIKey: "env-key", config.EnvKeyType: "rsa", config.EnvPrivateKeyFile: "/keys/env.pem"},
+			want: config.Profile{APIKey: "env-key", KeyType: utils.KeyTypeRSA, PrivateKeyFile: "/keys/env.pem", Timeout: "10s"},
+		},
+		{
+			name: "base URL and timeout",
+			env:  map[string]string{config.EnvBaseURL: "https://api2.binance.com", config.EnvTimeout: "1m"},
+			want: config.Profile{APIKey: "file-key", KeyType: utils.KeyTypeEd25519, PrivateKeyFile: "/keys/prod.pem", BaseURL: "https://api2.binance.com", Timeout: "1m"},
+		},
+		{
+			name:    "secret without an API key",
+			env:     map[string]string{config.EnvSecretKey: "env-secret"},
+			wantErr: true,
+		},
+		{
+			name:    "private key file without an API key",
+			env:     map[string]string{config.EnvPrivateKeyFile: "/keys/env.pem"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			p, err := f.Profile("prod")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Profile: %v", err)
+			}
+			if !reflect.DeepEqual(*p, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, *p)
+			}
+		})
+	}
+}
+
+func TestProfileSigner(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemFile := writeFile(t, "key.pem", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	secretFile := writeFile(t, "secret", "file-secret\n")
+
+	tests := []struct {
+		name        string
+		profile     config.Profile
+		wantKeyType utils.KeyType
+		wantErr     bool
+	}{
+		{name: "inline secret", profile: config.Profile{APIKey: "k", SecretKey: "s"}, wantKeyType: utils.KeyTypeHMAC},
+		{name: "secret file", profile: config.Profile{APIKey: "k", SecretKeyFile: secretFile}, wantKeyType: utils.KeyTypeHMAC},
+		{name: "private key file", profile: config.Profile{APIKey: "k", KeyType: utils.KeyTypeEd25519, PrivateKeyFile: pemFile}, wantKeyType: utils.KeyTypeEd25519},
+		{name: "key type does not match the key", profile: config.Profile{APIKey: "k", KeyType: utils.KeyTypeRSA, PrivateKeyFile: pemFile}, wantErr: true},
+		{name: "missing secret file", profile: config.Profile{APIKey: "k", SecretKeyFile: filepath.Join(t.TempDir(), "missing")}, wantErr: true},
+		{name: "no secret", profile: config.Profile{APIKey: "k"}, wantErr: true},
+		{name: "no private key file", profile: config.Profile{APIKey: "k", KeyType: utils.KeyTypeEd25519}, wantErr: true},
+		{name: "no API key", profile: config.Profile{SecretKey: "s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			f := &config.File{Profiles: map[string]config.Profile{"p": tt.profile}}
+			p, err := f.Profile("p")
+			if err != nil {
+				t.Fatalf("Profile: %v", err)
+			}
+
+			signer, err := p.Signer()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Signer: %v", err)
+			}
+			if signer.KeyType != tt.wantKeyType {
+				t.Errorf("expected key type %s, got %s", tt.wantKeyType, signer.KeyType)
+			}
+			if _, err := signer.SignQuery("symbol=BTCUSDT"); err != nil {
+				t.Errorf("SignQuery: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.json", `{
+		"default": "prod",
+		"defaults": {"timeout": "10s"},
+		"profiles": {"prod": {"api_key": "k", "secret_key": "s"}}
+	}`)
+
+	f, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if f.Default != "prod" || f.Defaults.Timeout != "10s" || f.Profiles["prod"].APIKey != "k" {
+		t.Errorf("unexpected configuration %+v", f)
+	}
+
+	f, err = config.Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || f.Profiles == nil || len(f.Profiles) != 0 {
+		t.Errorf("expected an empty configuration for a missing file, got %+v, %v", f, err)
+	}
+
+	if _, err := config.Load(writeFile(t, "broken.json", "{")); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+}
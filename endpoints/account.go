@@ -50,8 +50,12 @@ func (s *AccountService) GetAccountInfo() (*models.AccountInfo, error) {
 }
 
 func (s *AccountService) UniversalTransfer(req models.AssetTransferRequest) (*models.AssetTransferResponse, error) {
+	if err := validateTransferSymbols(req.Type, req.FromSymbol, req.ToSymbol); err != nil {
+		return nil, err
+	}
+	
 	params := make(map[string]string)
-	params["type"] = req.Type
+	params["type"] = string(req.Type)
 	params["asset"] = req.Asset
 	params["amount"] = req.Amount
 	
@@ -121,4 +125,127 @@ func (s *AccountService) EnableFastWithdrawSwitch(recvWindow int64) error {
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// GetTransferHistory retrieves one page of universal transfer history for a transfer type
+// API endpoint: GET /sapi/v1/asset/transfer
+func (s *AccountService) GetTransferHistory(req models.TransferHistoryRequest) (*models.TransferHistory, error) {
+	if err := validateTransferSymbols(req.Type, req.FromSymbol, req.ToSymbol); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]string)
+	params["type"] = string(req.Type)
+
+	if !req.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(req.StartTime.UnixMilli(), 10)
+	}
+
+	if !req.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(req.EndTime.UnixMilli(), 10)
+	}
+
+	if req.Current > 0 {
+		params["current"] = strconv.Itoa(req.Current)
+	}
+
+	if req.Size > 0 {
+		params["size"] = strconv.Itoa(req.Size)
+	}
+
+	if req.FromSymbol != "" {
+		params["fromSymbol"] = req.FromSymbol
+	}
+
+	if req.ToSymbol != "" {
+		params["toSymbol"] = req.ToSymbol
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/asset/transfer", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer history: %w", err)
+	}
+
+	var history models.TransferHistory
+	if err := json.Unmarshal(resp, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &history, nil
+}
+
+// validateTransferSymbols rejects unknown transfer types and isolated margin
+// transfers that are missing their symbol
+func validateTransferSymbols(t models.UniversalTransferType, fromSymbol, toSymbol string) error {
+	if !t.IsValid() {
+		return fmt.Errorf("invalid universal transfer type %q", t)
+	}
+
+	if t.RequiresFromSymbol() && fromSymbol == "" {
+		return fmt.Errorf("fromSymbol is required for %s transfers", t)
+	}
+
+	if t.RequiresToSymbol() && toSymbol == "" {
+		return fmt.Errorf("toSymbol is required for %s transfers", t)
+	}
+
+	return nil
+}
+
+// GetAccountStatus retrieves the account status; a healthy account reports "Normal"
+// API endpoint: GET /sapi/v1/account/status
+func (s *AccountService) GetAccountStatus() (*models.AccountStatus, error) {
+	params := make(map[string]string)
+
+	resp, err := s.client.Get("/sapi/v1/account/status", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account status: %w", err)
+	}
+
+	var status models.AccountStatus
+	if err := json.Unmarshal(resp, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &status, nil
+}
+
+// GetAPITradingStatus retrieves whether API trading is locked by the quantitative rules
+// API endpoint: GET /sapi/v1/account/apiTradingStatus
+func (s *AccountService) GetAPITradingStatus() (*models.APITradingStatus, error) {
+	params := make(map[string]string)
+
+	resp, err := s.client.Get("/sapi/v1/account/apiTradingStatus", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API trading status: %w", err)
+	}
+
+	var status models.APITradingStatus
+	if err := json.Unmarshal(resp, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &status, nil
+}
+
+// GetAPIRestrictions retrieves the permissions and IP restriction of the API key
+// API endpoint: GET /sapi/v1/account/apiRestrictions
+func (s *AccountService) GetAPIRestrictions() (*models.APIRestrictions, error) {
+	params := make(map[string]string)
+
+	resp, err := s.client.Get("/sapi/v1/account/apiRestrictions", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API restrictions: %w", err)
+	}
+
+	var restrictions models.APIRestrictions
+	if err := json.Unmarshal(resp, &restrictions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &restrictions, nil
+}
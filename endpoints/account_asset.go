@@ -0,0 +1,221 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// GetFundingAsset retrieves balances of the funding wallet
+// API endpoint: POST /sapi/v1/asset/get-funding-asset
+func (s *AccountService) GetFundingAsset(req models.UserAssetRequest) ([]models.UserAsset, error) {
+	params := make(map[string]string)
+
+	if req.Asset != "" {
+		params["asset"] = req.Asset
+	}
+
+	if req.NeedBtcValuation {
+		params["needBtcValuation"] = "true"
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Post("/sapi/v1/asset/get-funding-asset", params, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding asset: %w", err)
+	}
+
+	var assets []models.UserAsset
+	if err := json.Unmarshal(resp, &assets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return assets, nil
+}
+
+// GetAssetDetail retrieves the deposit and withdrawal configuration of assets, keyed by asset
+// API endpoint: GET /sapi/v1/asset/assetDetail
+func (s *AccountService) GetAssetDetail(req models.AssetDetailRequest) (map[string]models.AssetDetail, error) {
+	params := make(map[string]string)
+
+	if req.Asset != "" {
+		params["asset"] = req.Asset
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/asset/assetDetail", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset detail: %w", err)
+	}
+
+	var details map[string]models.AssetDetail
+	if err := json.Unmarshal(resp, &details); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return details, nil
+}
+
+// GetTradeFee retrieves the maker and taker commission rates of symbols
+// API endpoint: GET /sapi/v1/asset/tradeFee
+func (s *AccountService) GetTradeFee(req models.TradeFeeRequest) ([]models.TradeFee, error) {
+	params := make(map[string]string)
+
+	if req.Symbol != "" {
+		params["symbol"] = req.Symbol
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/asset/tradeFee", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade fee: %w", err)
+	}
+
+	var fees []models.TradeFee
+	if err := json.Unmarshal(resp, &fees); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return fees, nil
+}
+
+// GetDustConvertible lists the assets that can be converted to BNB
+// API endpoint: POST /sapi/v1/asset/dust-btc
+func (s *AccountService) GetDustConvertible(req models.DustConvertibleRequest) (*models.DustConvertible, error) {
+	params := make(map[string]string)
+
+	if req.AccountType != "" {
+		params["accountType"] = string(req.AccountType)
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Post("/sapi/v1/asset/dust-btc", params, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dust convertible assets: %w", err)
+	}
+
+	var result models.DustConvertible
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DustTransfer converts small balances of the requested assets to BNB
+// API endpoint: POST /sapi/v1/asset/dust
+func (s *AccountService) DustTransfer(req models.DustTransferRequest) (*models.DustTransferResponse, error) {
+	if len(req.Assets) == 0 {
+		return nil, errors.New("at least one asset is required")
+	}
+
+	// Each asset is sent as a repeated asset parameter
+	params := url.Values{}
+	for _, asset := range req.Assets {
+		params.Add("asset", asset)
+	}
+
+	if req.AccountType != "" {
+		params.Set("accountType", string(req.AccountType))
+	}
+
+	if req.RecvWindow > 0 {
+		params.Set("recvWindow", strconv.FormatInt(req.RecvWindow, 10))
+	}
+
+	resp, err := s.client.PostValues("/sapi/v1/asset/dust", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer dust: %w", err)
+	}
+
+	var result models.DustTransferResponse
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetDustLog retrieves the dust conversion history
+// API endpoint: GET /sapi/v1/asset/dribblet
+func (s *AccountService) GetDustLog(req models.DustLogRequest) (*models.DustLog, error) {
+	params := make(map[string]string)
+
+	if !req.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(req.StartTime.UnixMilli(), 10)
+	}
+
+	if !req.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(req.EndTime.UnixMilli(), 10)
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/asset/dribblet", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dust log: %w", err)
+	}
+
+	var result models.DustLog
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetAssetDividend retrieves asset dividend records such as airdrops and staking rewards
+// API endpoint: GET /sapi/v1/asset/assetDividend
+func (s *AccountService) GetAssetDividend(req models.AssetDividendRequest) (*models.AssetDividends, error) {
+	params := make(map[string]string)
+
+	if req.Asset != "" {
+		params["asset"] = req.Asset
+	}
+
+	if !req.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(req.StartTime.UnixMilli(), 10)
+	}
+
+	if !req.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(req.EndTime.UnixMilli(), 10)
+	}
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/asset/assetDividend", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset dividend: %w", err)
+	}
+
+	var result models.AssetDividends
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,203 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// Limits of the account snapshot endpoint
+const (
+	snapshotMaxRange = 30 * 24 * time.Hour
+	snapshotMaxLimit = 30
+)
+
+// GetSpotSnapshots retrieves daily spot wallet snapshots
+// API endpoint: GET /sapi/v1/accountSnapshot
+func (s *AccountService) GetSpotSnapshots(req models.AccountSnapshotRequest) ([]models.SpotSnapshot, error) {
+	return getAccountSnapshots[models.SpotSnapshot](s, models.SnapshotTypeSpot, req)
+}
+
+// GetMarginSnapshots retrieves daily cross margin account snapshots
+// API endpoint: GET /sapi/v1/accountSnapshot
+func (s *AccountService) GetMarginSnapshots(req models.AccountSnapshotRequest) ([]models.MarginSnapshot, error) {
+	return getAccountSnapshots[models.MarginSnapshot](s, models.SnapshotTypeMargin, req)
+}
+
+// GetFuturesSnapshots retrieves daily USDⓈ-M futures account snapshots
+// API endpoint: GET /sapi/v1/accountSnapshot
+func (s *AccountService) GetFuturesSnapshots(req models.AccountSnapshotRequest) ([]models.FuturesSnapshot, error) {
+	return getAccountSnapshots[models.FuturesSnapshot](s, models.SnapshotTypeFutures, req)
+}
+
+func getAccountSnapshots[T any](s *AccountService, snapshotType models.SnapshotType, req models.AccountSnapshotRequest) ([]T, error) {
+	params := make(map[string]string)
+	params["type"] = string(snapshotType)
+
+	if !req.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(req.StartTime.UnixMilli(), 10)
+	}
+
+	if !req.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(req.EndTime.UnixMilli(), 10)
+	}
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/accountSnapshot", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s account snapshot: %w", snapshotType, err)
+	}
+
+	var result models.AccountSnapshotResponse[T]
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if result.Code != 200 {
+		return nil, fmt.Errorf("failed to get %s account snapshot: code %d: %s", snapshotType, result.Code, result.Msg)
+	}
+
+	return result.SnapshotVos, nil
+}
+
+// IterateSpotSnapshots iterates over the daily spot snapshots between
+// req.StartTime and req.EndTime in 30 day windows. Limit is ignored. A zero
+// StartTime starts 30 days before EndTime; a zero EndTime means now.
+func (s *AccountService) IterateSpotSnapshots(req models.AccountSnapshotRequest) *Iterator[models.SpotSnapshot] {
+	return iterateSnapshots(req, s.GetSpotSnapshots, func(v models.SpotSnapshot) int64 { return v.UpdateTime })
+}
+
+// IterateMarginSnapshots iterates over the daily cross margin snapshots between
+// req.StartTime and req.EndTime in 30 day windows. Limit is ignored. A zero
+// StartTime starts 30 days before EndTime; a zero EndTime means now.
+func (s *AccountService) IterateMarginSnapshots(req models.AccountSnapshotRequest) *Iterator[models.MarginSnapshot] {
+	return iterateSnapshots(req, s.GetMarginSnapshots, func(v models.MarginSnapshot) int64 { return v.UpdateTime })
+}
+
+// IterateFuturesSnapshots iterates over the daily futures snapshots between
+// req.StartTime and req.EndTime in 30 day windows. Limit is ignored. A zero
+// StartTime starts 30 days before EndTime; a zero EndTime means now.
+func (s *AccountService) IterateFuturesSnapshots(req models.AccountSnapshotRequest) *Iterator[models.FuturesSnapshot] {
+	return iterateSnapshots(req, s.GetFuturesSnapshots, func(v models.FuturesSnapshot) int64 { return v.UpdateTime })
+}
+
+// iterateSnapshots fetches one page of at most 30 daily snapshots per 30 day window
+func iterateSnapshots[T any](req models.AccountSnapshotRequest, get func(models.AccountSnapshotRequest) ([]T, error), updateTime func(T) int64) *Iterator[T] {
+	start, end := defaultRange(req.StartTime, req.EndTime, snapshotMaxRange)
+
+	fetch := func(start, end time.Time) ([]T, error) {
+		r := req
+		r.StartTime, r.EndTime, r.Limit = start, end, snapshotMaxLimit
+		return get(r)
+	}
+
+	return newIterator(start, end, snapshotMaxRange, fetch,
+		func(v T) string { return strconv.FormatInt(updateTime(v), 10) },
+		func(a, b T) bool { return updateTime(a) < updateTime(b) },
+	)
+}
+
+// NewSpotBalanceTable builds a table from spot balances, such as
+// TradingAccountInfo.Balances. Assets with a zero balance are left out.
+func NewSpotBalanceTable(balances []models.Balance, at time.Time) (*models.BalanceTable, error) {
+	table := &models.BalanceTable{Account: models.SnapshotTypeSpot, Time: at, Balances: make(map[string]models.AssetBalance)}
+	for _, b := range balances {
+		total, err := sumDecimals(b.Free, b.Locked)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s balance: %w", b.Asset, err)
+		}
+		if total.Sign() == 0 {
+			continue
+		}
+		table.Balances[b.Asset] = models.AssetBalance{
+			Asset:  b.Asset,
+			Free:   b.Free,
+			Locked: b.Locked,
+			Total:  utils.FormatDecimal(total, 8),
+		}
+	}
+	return table, nil
+}
+
+// SpotSnapshotBalanceTable converts a spot snapshot into a per-asset balance table
+func SpotSnapshotBalanceTable(s models.SpotSnapshot) (*models.BalanceTable, error) {
+	return NewSpotBalanceTable(s.Data.Balances, time.UnixMilli(s.UpdateTime))
+}
+
+// MarginSnapshotBalanceTable converts a margin snapshot into a per-asset
+// balance table of net holdings
+func MarginSnapshotBalanceTable(s models.MarginSnapshot) (*models.BalanceTable, error) {
+	table := &models.BalanceTable{Account: models.SnapshotTypeMargin, Time: time.UnixMilli(s.UpdateTime), Balances: make(map[string]models.AssetBalance)}
+	for _, a := range s.Data.UserAssets {
+		total, err := sumDecimals(a.Free, a.Locked)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s balance: %w", a.Asset, err)
+		}
+		debt, err := sumDecimals(a.Borrowed, a.Interest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s debt: %w", a.Asset, err)
+		}
+		total.Sub(total, debt)
+		table.Balances[a.Asset] = models.AssetBalance{
+			Asset:    a.Asset,
+			Free:     a.Free,
+			Locked:   a.Locked,
+			Borrowed: a.Borrowed,
+			Interest: a.Interest,
+			Total:    utils.FormatDecimal(total, 8),
+		}
+	}
+	return table, nil
+}
+
+// FuturesSnapshotBalanceTable converts a futures snapshot into a per-asset
+// balance table of wallet balances; unrealized profit of open positions is not included
+func FuturesSnapshotBalanceTable(s models.FuturesSnapshot) (*models.BalanceTable, error) {
+	table := &models.BalanceTable{Account: models.SnapshotTypeFutures, Time: time.UnixMilli(s.UpdateTime), Balances: make(map[string]models.AssetBalance)}
+	for _, a := range s.Data.Assets {
+		total, err := utils.ParseDecimal(a.WalletBalance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s wallet balance: %w", a.Asset, err)
+		}
+		table.Balances[a.Asset] = models.AssetBalance{
+			Asset: a.Asset,
+			Free:  a.WalletBalance,
+			Total: utils.FormatDecimal(total, 8),
+		}
+	}
+	return table, nil
+}
+
+// BalanceTableTotal returns the net holding of asset in a table, zero when
+// the asset is absent
+func BalanceTableTotal(t *models.BalanceTable, asset string) (*big.Rat, error) {
+	total, err := utils.ParseDecimal(t.Balances[asset].Total)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s total: %w", asset, err)
+	}
+	return total, nil
+}
+
+func sumDecimals(values ...string) (*big.Rat, error) {
+	sum := new(big.Rat)
+	for _, v := range values {
+		r, err := utils.ParseDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		sum.Add(sum, r)
+	}
+	return sum, nil
+}
@@ -0,0 +1,128 @@
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// ErrAPIKeyCheckFailed is returned by CheckAPIKey when the key does not meet the requirements
+var ErrAPIKeyCheckFailed = errors.New("API key check failed")
+
+// APIKeyRequirements declares what a deployment needs from its API key
+type APIKeyRequirements struct {
+	// Permissions that must be enabled on the key
+	Permissions []models.APIPermission
+	// RequireIPRestriction fails the check when the key is usable from any IP.
+	// Binance only allows withdrawals from IP restricted keys.
+	RequireIPRestriction bool
+	// ForbidPermissions lists permissions the key must not have, such as
+	// withdrawals on a key that only trades
+	ForbidPermissions []models.APIPermission
+}
+
+// APIKeyReport is the result of CheckAPIKey
+type APIKeyReport struct {
+	AccountStatus string
+	Restrictions  models.APIRestrictions
+	TradingStatus models.APITradingStatusData
+	// Missing lists required permissions the key does not have
+	Missing []models.APIPermission
+	// Excess lists forbidden permissions the key has
+	Excess []models.APIPermission
+	// Problems describes every failed check
+	Problems []string
+}
+
+// OK reports whether every check passed
+func (r *APIKeyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// String formats the report for logs
+func (r *APIKeyReport) String() string {
+	if r.OK() {
+		return "API key check passed"
+	}
+	return "API key check failed: " + strings.Join(r.Problems, "; ")
+}
+
+// CheckAPIKey compares the key's permissions, IP restriction, account status
+// and API trading lock against req. The report is returned even when the check
+// fails, together with an error wrapping ErrAPIKeyCheckFailed; other errors mean
+// the status could not be retrieved.
+func (s *AccountService) CheckAPIKey(req APIKeyRequirements) (*APIKeyReport, error) {
+	restrictions, err := s.GetAPIRestrictions()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.GetAccountStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &APIKeyReport{
+		AccountStatus: status.Data,
+		Restrictions:  *restrictions,
+	}
+
+	if status.Data != "Normal" {
+		report.Problems = append(report.Problems, fmt.Sprintf("account status is %q", status.Data))
+	}
+
+	for _, p := range req.Permissions {
+		if !restrictions.Has(p) {
+			report.Missing = append(report.Missing, p)
+			report.Problems = append(report.Problems, fmt.Sprintf("missing permission %s", p))
+		}
+	}
+
+	for _, p := range req.ForbidPermissions {
+		if restrictions.Has(p) {
+			report.Excess = append(report.Excess, p)
+			report.Problems = append(report.Problems, fmt.Sprintf("forbidden permission %s is enabled", p))
+		}
+	}
+
+	if (req.RequireIPRestriction || requires(req.Permissions, models.APIPermissionWithdrawals)) && !restrictions.IPRestrict {
+		report.Problems = append(report.Problems, "key is not IP restricted")
+	}
+
+	if expiry := restrictions.TradingAuthorityExpirationTime; expiry > 0 && requires(req.Permissions, models.APIPermissionSpotTrading) {
+		if time.UnixMilli(expiry).Before(time.Now()) {
+			report.Problems = append(report.Problems, fmt.Sprintf("trading authority expired at %s", time.UnixMilli(expiry).UTC().Format(time.RFC3339)))
+		}
+	}
+
+	// The quantitative rules lock only affects trading keys
+	if requires(req.Permissions, models.APIPermissionSpotTrading) {
+		trading, err := s.GetAPITradingStatus()
+		if err != nil {
+			return nil, err
+		}
+
+		report.TradingStatus = trading.Data
+		if trading.Data.IsLocked {
+			report.Problems = append(report.Problems, fmt.Sprintf("API trading is locked until %s", time.UnixMilli(trading.Data.PlannedRecoverTime).UTC().Format(time.RFC3339)))
+		}
+	}
+
+	if !report.OK() {
+		return report, fmt.Errorf("%w: %s", ErrAPIKeyCheckFailed, strings.Join(report.Problems, "; "))
+	}
+
+	return report, nil
+}
+
+func requires(permissions []models.APIPermission, p models.APIPermission) bool {
+	for _, q := range permissions {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
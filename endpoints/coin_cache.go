@@ -0,0 +1,65 @@
+package endpoints
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// DefaultCoinCacheTTL is how long a CoinCache reuses a GetAllCoins result
+const DefaultCoinCacheTTL = 5 * time.Minute
+
+// CoinCache caches the coin and network configuration returned by AccountService.GetAllCoins
+type CoinCache struct {
+	account *AccountService
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	coins     map[string]models.CoinInfo
+	fetchedAt time.Time
+}
+
+// NewCoinCache creates a cache that refetches the coin configuration after ttl.
+// A zero ttl uses DefaultCoinCacheTTL.
+func NewCoinCache(account *AccountService, ttl time.Duration) *CoinCache {
+	if ttl <= 0 {
+		ttl = DefaultCoinCacheTTL
+	}
+	return &CoinCache{
+		account: account,
+		ttl:     ttl,
+	}
+}
+
+// Invalidate drops the cached configuration so the next lookup refetches it
+func (c *CoinCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.coins = nil
+}
+
+// Coin returns the configuration of a coin, refreshing the cache if it has expired
+func (c *CoinCache) Coin(coin string) (*models.CoinInfo, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.coins == nil || time.Since(c.fetchedAt) > c.ttl {
+		coins, err := c.account.GetAllCoins()
+		if err != nil {
+			return nil, false, err
+		}
+
+		c.coins = make(map[string]models.CoinInfo, len(coins))
+		for _, info := range coins {
+			c.coins[info.Coin] = info
+		}
+		c.fetchedAt = time.Now()
+	}
+
+	info, ok := c.coins[coin]
+	if !ok {
+		return nil, false, nil
+	}
+	return &info, true, nil
+}
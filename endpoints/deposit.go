@@ -25,6 +25,10 @@ func (s *DepositService) GetDepositAddress(req models.DepositAddressRequest) (*m
 		params["network"] = req.Network
 	}
 	
+	if req.Amount != "" {
+		params["amount"] = req.Amount
+	}
+	
 	if req.RecvWindow > 0 {
 		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
 	}
@@ -42,6 +46,66 @@ func (s *DepositService) GetDepositAddress(req models.DepositAddressRequest) (*m
 	return &address, nil
 }
 
+// GetDepositAddressList retrieves every deposit address of a coin, including
+// additional addresses on networks that support more than one
+// API endpoint: GET /sapi/v1/capital/deposit/address/list
+func (s *DepositService) GetDepositAddressList(req models.DepositAddressListRequest) ([]models.DepositAddressListItem, error) {
+	params := make(map[string]string)
+	params["coin"] = req.Coin
+
+	if req.Network != "" {
+		params["network"] = req.Network
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/capital/deposit/address/list", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deposit address list: %w", err)
+	}
+
+	var addresses []models.DepositAddressListItem
+	if err := json.Unmarshal(resp, &addresses); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return addresses, nil
+}
+
+// GetSubAccountDepositAddress retrieves a sub-account's deposit address (master account only)
+// API endpoint: GET /sapi/v1/capital/deposit/subAddress
+func (s *DepositService) GetSubAccountDepositAddress(req models.SubAccountDepositAddressRequest) (*models.DepositAddress, error) {
+	params := make(map[string]string)
+	params["email"] = req.Email
+	params["coin"] = req.Coin
+
+	if req.Network != "" {
+		params["network"] = req.Network
+	}
+
+	if req.Amount != "" {
+		params["amount"] = req.Amount
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/capital/deposit/subAddress", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-account deposit address: %w", err)
+	}
+
+	var address models.DepositAddress
+	if err := json.Unmarshal(resp, &address); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &address, nil
+}
+
 func (s *DepositService) GetDepositHistory(req models.DepositHistoryRequest) ([]models.DepositHistory, error) {
 	params := make(map[string]string)
 	
@@ -49,8 +113,8 @@ func (s *DepositService) GetDepositHistory(req models.DepositHistoryRequest) ([]
 		params["coin"] = req.Coin
 	}
 	
-	if req.Status != 0 {
-		params["status"] = strconv.Itoa(req.Status)
+	if req.Status != nil {
+		params["status"] = strconv.Itoa(int(*req.Status))
 	}
 	
 	if !req.StartTime.IsZero() {
@@ -88,4 +152,39 @@ func (s *DepositService) GetDepositHistory(req models.DepositHistoryRequest) ([]
 	}
 	
 	return history, nil
-}
\ No newline at end of file
+}
+
+// ApplyDepositCredit applies for crediting a deposit that arrived without the
+// required memo or on a suspended token
+// API endpoint: POST /sapi/v1/capital/deposit/credit-apply
+func (s *DepositService) ApplyDepositCredit(req models.DepositCreditApplyRequest) (*models.DepositCreditApplyResponse, error) {
+	params := make(map[string]string)
+
+	if req.DepositId > 0 {
+		params["depositId"] = strconv.FormatInt(req.DepositId, 10)
+	}
+
+	if req.TxId != "" {
+		params["txId"] = req.TxId
+	}
+
+	if req.SubAccountId > 0 {
+		params["subAccountId"] = strconv.FormatInt(req.SubAccountId, 10)
+	}
+
+	if req.SubUserId > 0 {
+		params["subUserId"] = strconv.FormatInt(req.SubUserId, 10)
+	}
+
+	resp, err := s.client.Post("/sapi/v1/capital/deposit/credit-apply", params, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply deposit credit: %w", err)
+	}
+
+	var result models.DepositCreditApplyResponse
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,91 @@
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// ErrDepositNetworkUnavailable is returned when deposits are not possible on the selected network
+var ErrDepositNetworkUnavailable = errors.New("deposit network unavailable")
+
+// ResolvedDepositAddress is a deposit address together with the network rules
+// a customer needs to know before sending funds
+type ResolvedDepositAddress struct {
+	Coin    string
+	Network string
+	Address string
+	Tag     string
+	// TagRequired is true when the network shares one address between accounts
+	// and deposits must carry Tag as memo
+	TagRequired bool
+	URL         string
+	// MinConfirm is the number of confirmations before the deposit is credited
+	MinConfirm int
+	// UnlockConfirm is the number of confirmations before the deposit can be withdrawn
+	UnlockConfirm int
+	// SpecialTips carries network specific instructions published by Binance
+	SpecialTips string
+}
+
+// ResolveDepositNetwork returns the requested network of a coin, or the coin's
+// default network when network is empty. Networks with deposits disabled are refused.
+func ResolveDepositNetwork(coin *models.CoinInfo, network string) (*models.NetworkInfo, error) {
+	if !coin.DepositAllEnable {
+		return nil, fmt.Errorf("%w: deposits of %s are disabled", ErrDepositNetworkUnavailable, coin.Coin)
+	}
+
+	n := findNetwork(coin, network)
+	if n == nil {
+		if network == "" {
+			return nil, fmt.Errorf("%w: %s has no default network", ErrDepositNetworkUnavailable, coin.Coin)
+		}
+		return nil, fmt.Errorf("%w: %s is not supported for %s", ErrDepositNetworkUnavailable, network, coin.Coin)
+	}
+
+	if !n.DepositEnable {
+		return nil, fmt.Errorf("%w: deposits of %s on %s are disabled", ErrDepositNetworkUnavailable, coin.Coin, n.Network)
+	}
+
+	return n, nil
+}
+
+// ResolveDepositAddress selects the deposit network for req using the coin
+// configuration in coins, refusing networks with deposits disabled, and fetches
+// the address on that network
+func (s *DepositService) ResolveDepositAddress(coins *CoinCache, req models.DepositAddressRequest) (*ResolvedDepositAddress, error) {
+	coin, ok, err := coins.Coin(req.Coin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load coin configuration: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown coin %s", ErrDepositNetworkUnavailable, req.Coin)
+	}
+
+	network, err := ResolveDepositNetwork(coin, req.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Network = network.Network
+	address, err := s.GetDepositAddress(req)
+	if err != nil {
+		return nil, err
+	}
+	if network.SameAddress && address.Tag == "" {
+		return nil, fmt.Errorf("%w: %s on %s requires a memo but none was returned", ErrDepositNetworkUnavailable, coin.Coin, network.Network)
+	}
+
+	return &ResolvedDepositAddress{
+		Coin:          coin.Coin,
+		Network:       network.Network,
+		Address:       address.Address,
+		Tag:           address.Tag,
+		TagRequired:   network.SameAddress,
+		URL:           address.URL,
+		MinConfirm:    network.MinConfirm,
+		UnlockConfirm: network.UnLockConfirm,
+		SpecialTips:   network.SpecialTips,
+	}, nil
+}
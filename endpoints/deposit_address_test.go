@@ -0,0 +1,74 @@
+package endpoints_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+)
+
+func TestResolveDepositAddress(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+	c := srv.NewClient()
+	srv.AddCoin(models.CoinInfo{
+		Coin:             "XRP",
+		DepositAllEnable: true,
+		NetworkList: []models.NetworkInfo{
+			{Coin: "XRP", Network: "XRP", IsDefault: true, DepositEnable: true, SameAddress: true},
+			{Coin: "XRP", Network: "BSC", DepositEnable: false},
+		},
+	})
+	coins := endpoints.NewCoinCache(c.Account, 0)
+
+	tests := []struct {
+		name            string
+		req             models.DepositAddressRequest
+		wantNetwork     string
+		wantTagRequired bool
+		wantErr         error
+	}{
+		{
+			name:            "memo network",
+			req:             models.DepositAddressRequest{Coin: "XRP"},
+			wantNetwork:     "XRP",
+			wantTagRequired: true,
+		},
+		{
+			name:        "network with an address per account",
+			req:         models.DepositAddressRequest{Coin: "USDT", Network: "TRX"},
+			wantNetwork: "TRX",
+		},
+		{
+			name:    "network with deposits disabled",
+			req:     models.DepositAddressRequest{Coin: "XRP", Network: "BSC"},
+			wantErr: endpoints.ErrDepositNetworkUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := c.Deposit.ResolveDepositAddress(coins, tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveDepositAddress: %v", err)
+			}
+			if address.Network != tt.wantNetwork {
+				t.Errorf("expected network %s, got %s", tt.wantNetwork, address.Network)
+			}
+			if address.TagRequired != tt.wantTagRequired {
+				t.Errorf("expected TagRequired %v, got %v", tt.wantTagRequired, address.TagRequired)
+			}
+			if address.TagRequired && address.Tag == "" {
+				t.Error("expected a memo on a shared address network")
+			}
+		})
+	}
+}
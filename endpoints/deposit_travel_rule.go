@@ -0,0 +1,134 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// GetLocalEntityDepositHistory retrieves deposits with their travel rule status,
+// for accounts onboarded with a local entity that requires travel rule information
+// API endpoint: GET /sapi/v1/localentity/deposit/history
+func (s *DepositService) GetLocalEntityDepositHistory(req models.LocalEntityDepositHistoryRequest) ([]models.LocalEntityDeposit, error) {
+	params := make(map[string]string)
+
+	if req.TrId != "" {
+		params["trId"] = req.TrId
+	}
+
+	if req.TxId != "" {
+		params["txId"] = req.TxId
+	}
+
+	if req.TranId != "" {
+		params["tranId"] = req.TranId
+	}
+
+	if req.Network != "" {
+		params["network"] = req.Network
+	}
+
+	if req.Coin != "" {
+		params["coin"] = req.Coin
+	}
+
+	if req.TravelRuleStatus != nil {
+		params["travelRuleStatus"] = strconv.Itoa(int(*req.TravelRuleStatus))
+	}
+
+	if req.PendingQuestionnaire != nil {
+		params["pendingQuestionnaire"] = strconv.FormatBool(*req.PendingQuestionnaire)
+	}
+
+	if !req.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(req.StartTime.UnixMilli(), 10)
+	}
+
+	if !req.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(req.EndTime.UnixMilli(), 10)
+	}
+
+	if req.Offset > 0 {
+		params["offset"] = strconv.Itoa(req.Offset)
+	}
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/localentity/deposit/history", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local entity deposit history: %w", err)
+	}
+
+	var history []models.LocalEntityDeposit
+	if err := json.Unmarshal(resp, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return history, nil
+}
+
+// SubmitDepositQuestionnaire submits the travel rule questionnaire for a deposit
+// API endpoint: PUT /sapi/v1/localentity/deposit/provide-info
+func (s *DepositService) SubmitDepositQuestionnaire(req models.DepositQuestionnaireRequest) (*models.DepositQuestionnaireResponse, error) {
+	questionnaire, err := json.Marshal(req.Questionnaire)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal questionnaire: %w", err)
+	}
+
+	params := make(map[string]string)
+	params["tranId"] = strconv.FormatInt(req.TranId, 10)
+	params["questionnaire"] = string(questionnaire)
+
+	resp, err := s.client.Put("/sapi/v1/localentity/deposit/provide-info", params, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit deposit questionnaire: %w", err)
+	}
+
+	var result models.DepositQuestionnaireResponse
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetVASPList retrieves the VASPs onboarded for travel rule exchanges
+// API endpoint: GET /sapi/v1/localentity/vasp
+func (s *DepositService) GetVASPList() ([]models.VASP, error) {
+	params := make(map[string]string)
+
+	resp, err := s.client.Get("/sapi/v1/localentity/vasp", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VASP list: %w", err)
+	}
+
+	var vasps []models.VASP
+	if err := json.Unmarshal(resp, &vasps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return vasps, nil
+}
+
+// GetOnboardedEntity retrieves the local entity the account is onboarded with,
+// which determines the questionnaire format to use
+// API endpoint: GET /sapi/v1/localentity/questionnaire-requirements
+func (s *DepositService) GetOnboardedEntity() (*models.QuestionnaireRequirements, error) {
+	params := make(map[string]string)
+
+	resp, err := s.client.Get("/sapi/v1/localentity/questionnaire-requirements", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get onboarded entity: %w", err)
+	}
+
+	var requirements models.QuestionnaireRequirements
+	if err := json.Unmarshal(resp, &requirements); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &requirements, nil
+}
@@ -0,0 +1,431 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// depositHistoryMaxRange is the longest time range GetDepositHistory accepts
+const depositHistoryMaxRange = 90 * 24 * time.Hour
+
+// depositHistoryPageSize is the largest page GetDepositHistory returns
+const depositHistoryPageSize = 1000
+
+// DepositEventType identifies the stage a deposit has reached
+type DepositEventType string
+
+const (
+	// DepositEventPending is emitted when a deposit is first seen and whenever its confirmations increase
+	DepositEventPending DepositEventType = "PENDING"
+	// DepositEventCredited is emitted when the amount is credited to the account
+	DepositEventCredited DepositEventType = "CREDITED"
+	// DepositEventUnlockable is emitted when the deposit can be withdrawn
+	DepositEventUnlockable DepositEventType = "UNLOCKABLE"
+	// DepositEventRejected is emitted when the deposit is rejected or flagged as a wrong deposit
+	DepositEventRejected DepositEventType = "REJECTED"
+)
+
+// DepositEvent reports a change in a deposit's progress
+type DepositEvent struct {
+	Type    DepositEventType
+	Deposit models.DepositHistory
+	// Confirmations is the number of block confirmations so far
+	Confirmations int
+	// RequiredConfirmations is the number of confirmations needed to credit the deposit
+	RequiredConfirmations int
+}
+
+// ParseConfirmTimes parses DepositHistory.ConfirmTimes ("3/12") into the
+// current and required confirmation counts
+func ParseConfirmTimes(s string) (int, int) {
+	current, required, _ := strings.Cut(s, "/")
+	c, _ := strconv.Atoi(strings.TrimSpace(current))
+	r, _ := strconv.Atoi(strings.TrimSpace(required))
+	return c, r
+}
+
+// DepositProgress is the last state the watcher emitted for a deposit
+type DepositProgress struct {
+	Stage         DepositEventType `json:"stage"`
+	Confirmations int              `json:"confirmations"`
+	InsertTime    int64            `json:"insertTime"`
+	// Coin and TxId look the deposit up once it falls behind the polled window
+	Coin string `json:"coin,omitempty"`
+	TxId string `json:"txId,omitempty"`
+}
+
+// done reports whether no further events will be emitted for the deposit
+func (p DepositProgress) done() bool {
+	return p.Stage == DepositEventUnlockable || p.Stage == DepositEventRejected
+}
+
+// DepositCheckpoint is the persisted state of a DepositWatcher
+type DepositCheckpoint struct {
+	// Cursor is the insert time (ms) from which the next poll searches, before lookback
+	Cursor int64 `json:"cursor"`
+	// Deposits holds the progress of every deposit seen since Cursor, and of
+	// older deposits still in progress, keyed by deposit key
+	Deposits map[string]DepositProgress `json:"deposits"`
+}
+
+// DepositCheckpointStore persists the watcher's checkpoint across restarts
+type DepositCheckpointStore interface {
+	// Load returns the saved checkpoint, or nil if there is none
+	Load() (*DepositCheckpoint, error)
+	Save(checkpoint *DepositCheckpoint) error
+}
+
+// MemoryCheckpointStore keeps the checkpoint in memory
+type MemoryCheckpointStore struct {
+	mu         sync.Mutex
+	checkpoint *DepositCheckpoint
+}
+
+func (m *MemoryCheckpointStore) Load() (*DepositCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copyCheckpoint(m.checkpoint), nil
+}
+
+func (m *MemoryCheckpointStore) Save(checkpoint *DepositCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoint = copyCheckpoint(checkpoint)
+	return nil
+}
+
+func copyCheckpoint(c *DepositCheckpoint) *DepositCheckpoint {
+	if c == nil {
+		return nil
+	}
+	cp := &DepositCheckpoint{Cursor: c.Cursor, Deposits: make(map[string]DepositProgress, len(c.Deposits))}
+	for k, v := range c.Deposits {
+		cp.Deposits[k] = v
+	}
+	return cp
+}
+
+// FileCheckpointStore keeps the checkpoint in a JSON file
+type FileCheckpointStore struct {
+	Path string
+}
+
+func (f *FileCheckpointStore) Load() (*DepositCheckpoint, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var c DepositCheckpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	}
+	return &c, nil
+}
+
+// Save writes the checkpoint to a temporary file and renames it into place so
+// a crash never leaves a partial checkpoint behind
+func (f *FileCheckpointStore) Save(checkpoint *DepositCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	tmp := f.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, f.Path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DepositWatcherOptions configures a DepositWatcher
+type DepositWatcherOptions struct {
+	// Coin restricts the watcher to one coin; empty watches every coin
+	Coin string
+	// Interval is the time between polls in Run (default 30s)
+	Interval time.Duration
+	// Lookback is subtracted from the cursor on every poll to catch deposits
+	// whose insert time lands slightly in the past (default 10m)
+	Lookback time.Duration
+	// MaxCursorAge caps how far the cursor trails now (default 24h). Deposits
+	// still in progress before the cursor are looked up by transaction ID on
+	// every poll instead of keeping the whole range in the window
+	MaxCursorAge time.Duration
+	// StartTime is where a watcher without a checkpoint starts (default now minus Lookback)
+	StartTime time.Time
+	// Store persists progress; defaults to an in-memory store
+	Store DepositCheckpointStore
+}
+
+// DepositWatcher polls the deposit history and emits an event each time a
+// deposit moves to a new stage. Progress is saved to the checkpoint store
+// after every handled event, so a restarted watcher neither re-emits nor misses
+// deposits.
+type DepositWatcher struct {
+	service *DepositService
+	opts    DepositWatcherOptions
+	handler func(DepositEvent) error
+
+	mu         sync.Mutex
+	checkpoint *DepositCheckpoint
+}
+
+// NewDepositWatcher creates a watcher that passes events to handler. If handler
+// returns an error the event is retried on the next poll.
+func NewDepositWatcher(service *DepositService, opts DepositWatcherOptions, handler func(DepositEvent) error) *DepositWatcher {
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	if opts.Lookback <= 0 {
+		opts.Lookback = 10 * time.Minute
+	}
+	if opts.MaxCursorAge <= 0 {
+		opts.MaxCursorAge = 24 * time.Hour
+	}
+	if opts.Store == nil {
+		opts.Store = &MemoryCheckpointStore{}
+	}
+
+	return &DepositWatcher{
+		service: service,
+		opts:    opts,
+		handler: handler,
+	}
+}
+
+// Run polls until stop is closed. Poll errors are passed to onError, if set, and polling continues.
+func (w *DepositWatcher) Run(stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(); err != nil && onError != nil {
+			onError(err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the deposit history since the checkpoint once and emits events for new progress
+func (w *DepositWatcher) Poll() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.checkpoint == nil {
+		cp, err := w.opts.Store.Load()
+		if err != nil {
+			return err
+		}
+		if cp == nil {
+			start := w.opts.StartTime
+			if start.IsZero() {
+				start = time.Now().Add(-w.opts.Lookback)
+			}
+			cp = &DepositCheckpoint{Cursor: start.UnixMilli()}
+		}
+		if cp.Deposits == nil {
+			cp.Deposits = make(map[string]DepositProgress)
+		}
+		w.checkpoint = cp
+	}
+
+	now := time.Now()
+	start := time.UnixMilli(w.checkpoint.Cursor).Add(-w.opts.Lookback)
+
+	deposits, err := w.fetch(start, now)
+	if err != nil {
+		return err
+	}
+
+	stuck, err := w.fetchStuck(start)
+	if err != nil {
+		return err
+	}
+	deposits = append(stuck, deposits...)
+
+	for _, d := range deposits {
+		key := depositKey(d)
+		prev, seen := w.checkpoint.Deposits[key]
+
+		for _, event := range depositEvents(d, prev, seen) {
+			if err := w.handler(event); err != nil {
+				return fmt.Errorf("deposit event handler failed: %w", err)
+			}
+
+			w.checkpoint.Deposits[key] = DepositProgress{
+				Stage:         event.Type,
+				Confirmations: event.Confirmations,
+				InsertTime:    d.InsertTime,
+				Coin:          d.Coin,
+				TxId:          d.TxId,
+			}
+			if err := w.opts.Store.Save(w.checkpoint); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.advance(now)
+	return w.opts.Store.Save(w.checkpoint)
+}
+
+// advance moves the cursor to the oldest deposit still in progress, or to now
+// if every deposit is done, but never further back than MaxCursorAge. Deposits
+// before the new window are forgotten once done, or if they cannot be looked
+// up by transaction ID.
+func (w *DepositWatcher) advance(now time.Time) {
+	cursor := now.UnixMilli()
+	for _, p := range w.checkpoint.Deposits {
+		if !p.done() && p.InsertTime < cursor {
+			cursor = p.InsertTime
+		}
+	}
+	if oldest := now.Add(-w.opts.MaxCursorAge).UnixMilli(); cursor < oldest {
+		cursor = oldest
+	}
+
+	windowStart := time.UnixMilli(cursor).Add(-w.opts.Lookback).UnixMilli()
+	for key, p := range w.checkpoint.Deposits {
+		if p.InsertTime < windowStart && (p.done() || p.TxId == "") {
+			delete(w.checkpoint.Deposits, key)
+		}
+	}
+
+	w.checkpoint.Cursor = cursor
+}
+
+// fetch returns every deposit inserted between start and end in insert time order,
+// splitting the range into windows the API accepts and paging within each
+func (w *DepositWatcher) fetch(start, end time.Time) ([]models.DepositHistory, error) {
+	var all []models.DepositHistory
+
+	for windowStart := start; windowStart.Before(end); windowStart = windowStart.Add(depositHistoryMaxRange) {
+		windowEnd := windowStart.Add(depositHistoryMaxRange)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+
+		for offset := 0; ; offset += depositHistoryPageSize {
+			page, err := w.service.GetDepositHistory(models.DepositHistoryRequest{
+				Coin:      w.opts.Coin,
+				StartTime: windowStart,
+				EndTime:   windowEnd,
+				Offset:    offset,
+				Limit:     depositHistoryPageSize,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			all = append(all, page...)
+			if len(page) < depositHistoryPageSize {
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].InsertTime < all[j].InsertTime })
+	return all, nil
+}
+
+// fetchStuck looks up, by transaction ID, every deposit still in progress that
+// was inserted before start and so is missing from the polled window
+func (w *DepositWatcher) fetchStuck(start time.Time) ([]models.DepositHistory, error) {
+	var stuck []models.DepositHistory
+
+	for key, p := range w.checkpoint.Deposits {
+		if p.done() || p.TxId == "" || p.InsertTime >= start.UnixMilli() {
+			continue
+		}
+
+		inserted := time.UnixMilli(p.InsertTime)
+		deposits, err := w.service.GetDepositHistory(models.DepositHistoryRequest{
+			Coin:      p.Coin,
+			TxId:      p.TxId,
+			StartTime: inserted,
+			EndTime:   inserted.Add(time.Minute),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range deposits {
+			if depositKey(d) == key {
+				stuck = append(stuck, d)
+			}
+		}
+	}
+
+	sort.SliceStable(stuck, func(i, j int) bool { return stuck[i].InsertTime < stuck[j].InsertTime })
+	return stuck, nil
+}
+
+// depositKey identifies a deposit across polls
+func depositKey(d models.DepositHistory) string {
+	if d.Id != "" {
+		return d.Id
+	}
+	return d.Coin + ":" + d.TxId + ":" + d.Address + ":" + d.AddressTag + ":" + d.Amount
+}
+
+// depositEvents returns the events to emit for a deposit given the progress
+// already emitted for it
+func depositEvents(d models.DepositHistory, prev DepositProgress, seen bool) []DepositEvent {
+	if seen && prev.done() {
+		return nil
+	}
+
+	confirmations, required := ParseConfirmTimes(d.ConfirmTimes)
+	newEvent := func(t DepositEventType) DepositEvent {
+		return DepositEvent{
+			Type:                  t,
+			Deposit:               d,
+			Confirmations:         confirmations,
+			RequiredConfirmations: required,
+		}
+	}
+
+	if d.Status == models.DepositStatusRejected || d.Status == models.DepositStatusWrongDeposit {
+		return []DepositEvent{newEvent(DepositEventRejected)}
+	}
+
+	unlockable := d.Status.IsSuccess() ||
+		(d.Status.IsCredited() && d.UnlockConfirm > 0 && confirmations >= d.UnlockConfirm)
+
+	var events []DepositEvent
+	switch {
+	case !d.Status.IsCredited():
+		if !seen || confirmations > prev.Confirmations {
+			events = append(events, newEvent(DepositEventPending))
+		}
+	case !seen || prev.Stage == DepositEventPending:
+		events = append(events, newEvent(DepositEventCredited))
+		if unlockable {
+			events = append(events, newEvent(DepositEventUnlockable))
+		}
+	case unlockable:
+		events = append(events, newEvent(DepositEventUnlockable))
+	}
+
+	return events
+}
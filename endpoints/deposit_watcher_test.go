@@ -0,0 +1,154 @@
+package endpoints_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+)
+
+// eventLog records watcher events as "id:TYPE"
+type eventLog struct {
+	events []string
+}
+
+func (l *eventLog) handle(e endpoints.DepositEvent) error {
+	l.events = append(l.events, e.Deposit.Id+":"+string(e.Type))
+	return nil
+}
+
+// take returns the events recorded since the last call
+func (l *eventLog) take() []string {
+	events := l.events
+	l.events = nil
+	return events
+}
+
+func pollEvents(t *testing.T, w *endpoints.DepositWatcher, log *eventLog, want ...string) {
+	t.Helper()
+	if err := w.Poll(); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if got := log.take(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+}
+
+func TestDepositWatcherDeduplicates(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+	c := srv.NewClient()
+
+	log := &eventLog{}
+	w := endpoints.NewDepositWatcher(c.Deposit, endpoints.DepositWatcherOptions{Coin: "XRP"}, log.handle)
+
+	d := srv.AddDeposit(models.DepositHistory{Coin: "XRP", Amount: "10", ConfirmTimes: "1/12", Status: models.DepositStatusPending})
+	pollEvents(t, w, log, d.Id+":PENDING")
+	pollEvents(t, w, log)
+
+	srv.SetDepositStatus(d.Id, models.DepositStatusSuccess)
+	pollEvents(t, w, log, d.Id+":CREDITED", d.Id+":UNLOCKABLE")
+	pollEvents(t, w, log)
+}
+
+func TestFileCheckpointStoreRoundTrip(t *testing.T) {
+	store := &endpoints.FileCheckpointStore{Path: filepath.Join(t.TempDir(), "deposits.json")}
+
+	cp, err := store.Load()
+	if err != nil || cp != nil {
+		t.Fatalf("expected no checkpoint before the first save, got %v, %v", cp, err)
+	}
+
+	want := &endpoints.DepositCheckpoint{
+		Cursor: 1700000000000,
+		Deposits: map[string]endpoints.DepositProgress{
+			"1": {Stage: endpoints.DepositEventPending, Confirmations: 3, InsertTime: 1699999990000, Coin: "XRP", TxId: "0xabc"},
+			"2": {Stage: endpoints.DepositEventUnlockable, InsertTime: 1699999995000},
+		},
+	}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDepositWatcherRestart(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+	c := srv.NewClient()
+	store := &endpoints.FileCheckpointStore{Path: filepath.Join(t.TempDir(), "deposits.json")}
+	opts := endpoints.DepositWatcherOptions{Coin: "XRP", Store: store}
+
+	now := time.Now()
+	pending := srv.AddDeposit(models.DepositHistory{Coin: "XRP", Amount: "10", ConfirmTimes: "1/12", Status: models.DepositStatusPending, InsertTime: now.Add(-2 * time.Minute).UnixMilli()})
+	credited := srv.AddDeposit(models.DepositHistory{Coin: "XRP", Amount: "5", ConfirmTimes: "12/12", Status: models.DepositStatusSuccess, InsertTime: now.Add(-time.Minute).UnixMilli()})
+
+	log := &eventLog{}
+	first := endpoints.NewDepositWatcher(c.Deposit, opts, log.handle)
+	pollEvents(t, first, log, pending.Id+":PENDING", credited.Id+":CREDITED", credited.Id+":UNLOCKABLE")
+
+	// a restarted watcher picks up the saved progress and only reports what changed
+	second := endpoints.NewDepositWatcher(c.Deposit, opts, log.handle)
+	pollEvents(t, second, log)
+
+	srv.SetDepositStatus(pending.Id, models.DepositStatusCreditedCannotWithdraw)
+	third := endpoints.NewDepositWatcher(c.Deposit, opts, log.handle)
+	pollEvents(t, third, log, pending.Id+":CREDITED")
+}
+
+func TestDepositWatcherStuckDeposit(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+	c := srv.NewClient()
+	store := &endpoints.MemoryCheckpointStore{}
+
+	now := time.Now()
+	stuck := srv.AddDeposit(models.DepositHistory{
+		Coin:         "XRP",
+		Amount:       "10",
+		ConfirmTimes: "1/12",
+		Status:       models.DepositStatusPending,
+		InsertTime:   now.Add(-72 * time.Hour).UnixMilli(),
+	})
+
+	log := &eventLog{}
+	w := endpoints.NewDepositWatcher(c.Deposit, endpoints.DepositWatcherOptions{
+		Coin:         "XRP",
+		MaxCursorAge: time.Hour,
+		StartTime:    now.Add(-96 * time.Hour),
+		Store:        store,
+	}, log.handle)
+	pollEvents(t, w, log, stuck.Id+":PENDING")
+
+	cp, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if oldest := now.Add(-time.Hour).UnixMilli(); cp.Cursor < oldest {
+		t.Fatalf("expected the cursor to stay within MaxCursorAge, got %d before %d", cp.Cursor, oldest)
+	}
+
+	// the deposit is now behind the polled window and is looked up by transaction ID
+	pollEvents(t, w, log)
+	srv.SetDepositStatus(stuck.Id, models.DepositStatusSuccess)
+	pollEvents(t, w, log, stuck.Id+":CREDITED", stuck.Id+":UNLOCKABLE")
+
+	cp, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, ok := cp.Deposits[stuck.Id]; ok {
+		t.Fatalf("expected the finished deposit to be forgotten, got %+v", cp.Deposits)
+	}
+}
@@ -128,4 +128,38 @@ func (s *MarketDataService) GetUIKlines(req models.KlineRequest) ([]models.Kline
 	}
 	
 	return klines, nil
-}
\ No newline at end of file
+}
+
+// GetTickerPrices retrieves the latest price of one, several or all symbols
+// API endpoint: GET /api/v3/ticker/price
+func (s *MarketDataService) GetTickerPrices(req models.TickerPriceRequest) ([]models.TickerPrice, error) {
+	params := make(map[string]string)
+
+	if req.Symbol != "" {
+		params["symbol"] = req.Symbol
+	} else if len(req.Symbols) > 0 {
+		// Format: ["BTCUSDT","BNBUSDT"]
+		params["symbols"] = `["` + strings.Join(req.Symbols, `","`) + `"]`
+	}
+
+	resp, err := s.client.Get("/api/v3/ticker/price", params, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticker prices: %w", err)
+	}
+
+	// A single symbol is returned as an object rather than an array
+	if req.Symbol != "" {
+		var price models.TickerPrice
+		if err := json.Unmarshal(resp, &price); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ticker price: %w", err)
+		}
+		return []models.TickerPrice{price}, nil
+	}
+
+	var prices []models.TickerPrice
+	if err := json.Unmarshal(resp, &prices); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ticker prices: %w", err)
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,495 @@
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// ErrOrderNotTracked is returned when the tracker has never seen the requested order
+var ErrOrderNotTracked = errors.New("order not tracked")
+
+// ErrOrderWaitTimeout is returned when an order does not reach a terminal status in time
+var ErrOrderWaitTimeout = errors.New("timed out waiting for terminal order status")
+
+// InvalidTransitionError is returned when an update would move an order into
+// a status that cannot be reached from its current one
+type InvalidTransitionError struct {
+	OrderId       int64
+	ClientOrderId string
+	From          models.OrderStatus
+	To            models.OrderStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("invalid order status transition for order %d (%s): %s -> %s", e.OrderId, e.ClientOrderId, e.From, e.To)
+}
+
+// validTransitions lists the statuses reachable from each non-terminal status
+var validTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderStatusNew: {
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusCanceled,
+		models.OrderStatusPendingCancel,
+		models.OrderStatusRejected,
+		models.OrderStatusExpired,
+		models.OrderStatusExpiredInMatch,
+	},
+	models.OrderStatusPartiallyFilled: {
+		models.OrderStatusFilled,
+		models.OrderStatusCanceled,
+		models.OrderStatusPendingCancel,
+		models.OrderStatusExpired,
+		models.OrderStatusExpiredInMatch,
+	},
+	models.OrderStatusPendingCancel: {
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusCanceled,
+		models.OrderStatusExpired,
+	},
+}
+
+// canTransition reports whether an order may move from one status to another
+func canTransition(from, to models.OrderStatus) bool {
+	if from == "" || from == to {
+		return true
+	}
+	for _, s := range validTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TrackedOrder is a snapshot of the reconciled state of an order
+type TrackedOrder struct {
+	Symbol              string
+	OrderId             int64
+	ClientOrderId       string
+	Side                models.OrderSide
+	Type                models.OrderType
+	Status              models.OrderStatus
+	OrigQty             string
+	ExecutedQty         string
+	CummulativeQuoteQty string
+	AvgPrice            string
+	Fills               []models.OrderFill
+	UpdateTime          int64
+}
+
+// orderUpdate is the common form REST responses and stream events are reduced to
+type orderUpdate struct {
+	symbol        string
+	orderId       int64
+	clientOrderId string
+	side          models.OrderSide
+	orderType     models.OrderType
+	status        models.OrderStatus
+	origQty       string
+	executedQty   string
+	cumQuoteQty   string
+	fills         []models.OrderFill
+	updateTime    int64
+}
+
+type trackedEntry struct {
+	order   TrackedOrder
+	tradeId map[int64]bool
+	done    chan struct{}
+}
+
+func (e *trackedEntry) snapshot() *TrackedOrder {
+	o := e.order
+	o.Fills = append([]models.OrderFill(nil), e.order.Fills...)
+	return &o
+}
+
+// OrderTracker reconciles order responses, query results and execution reports
+// into a per-order state machine
+type OrderTracker struct {
+	trading      *TradingService
+	pollInterval time.Duration
+
+	mu              sync.Mutex
+	byOrderId       map[int64]*trackedEntry
+	byClientOrderId map[string]*trackedEntry
+}
+
+// NewOrderTracker creates an order tracker. trading may be nil, in which case
+// the tracker only consumes updates it is given and never polls.
+func NewOrderTracker(trading *TradingService) *OrderTracker {
+	return &OrderTracker{
+		trading:         trading,
+		pollInterval:    2 * time.Second,
+		byOrderId:       make(map[int64]*trackedEntry),
+		byClientOrderId: make(map[string]*trackedEntry),
+	}
+}
+
+// SetPollInterval sets how often WaitForTerminal queries the order while waiting.
+// A zero interval disables polling.
+func (t *OrderTracker) SetPollInterval(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pollInterval = interval
+}
+
+// ApplyOrderResponse records the result of TradingService.NewOrder
+func (t *OrderTracker) ApplyOrderResponse(resp *models.OrderResponse) (*TrackedOrder, error) {
+	return t.apply(orderUpdate{
+		symbol:        resp.Symbol,
+		orderId:       resp.OrderId,
+		clientOrderId: resp.ClientOrderId,
+		side:          resp.Side,
+		orderType:     resp.Type,
+		status:        resp.Status,
+		origQty:       resp.OrigQty,
+		executedQty:   resp.ExecutedQty,
+		cumQuoteQty:   resp.CummulativeQuoteQty,
+		fills:         resp.Fills,
+		updateTime:    resp.TransactTime,
+	})
+}
+
+// ApplyCancelResponse records the result of TradingService.CancelOrder
+func (t *OrderTracker) ApplyCancelResponse(resp *models.CancelOrderResponse) (*TrackedOrder, error) {
+	return t.apply(orderUpdate{
+		symbol:        resp.Symbol,
+		orderId:       resp.OrderId,
+		clientOrderId: resp.OrigClientOrderId,
+		side:          resp.Side,
+		orderType:     resp.Type,
+		status:        resp.Status,
+		origQty:       resp.OrigQty,
+		executedQty:   resp.ExecutedQty,
+		cumQuoteQty:   resp.CummulativeQuoteQty,
+		updateTime:    resp.TransactTime,
+	})
+}
+
+// ApplyOrder records the result of TradingService.QueryOrder or an order listing
+func (t *OrderTracker) ApplyOrder(order *models.Order) (*TrackedOrder, error) {
+	return t.apply(orderUpdate{
+		symbol:        order.Symbol,
+		orderId:       order.OrderId,
+		clientOrderId: order.ClientOrderId,
+		side:          order.Side,
+		orderType:     order.Type,
+		status:        order.Status,
+		origQty:       order.OrigQty,
+		executedQty:   order.ExecutedQty,
+		cumQuoteQty:   order.CummulativeQuoteQty,
+		updateTime:    order.UpdateTime,
+	})
+}
+
+// ApplyExecutionReport records an executionReport event from the user data stream
+func (t *OrderTracker) ApplyExecutionReport(report *models.ExecutionReport) (*TrackedOrder, error) {
+	u := orderUpdate{
+		symbol:        report.Symbol,
+		orderId:       report.OrderId,
+		clientOrderId: report.ClientOrderId,
+		side:          report.Side,
+		orderType:     report.Type,
+		status:        report.Status,
+		origQty:       report.Quantity,
+		executedQty:   report.CumulativeFilledQty,
+		cumQuoteQty:   report.CumulativeQuoteQty,
+		updateTime:    report.TransactionTime,
+	}
+
+	// For cancellations "c" carries the cancel request's id and "C" the order's own id
+	if report.ExecutionType == models.ExecutionTypeCanceled && report.OrigClientOrderId != "" {
+		u.clientOrderId = report.OrigClientOrderId
+	}
+
+	if report.ExecutionType == models.ExecutionTypeTrade {
+		u.fills = []models.OrderFill{{
+			Price:           report.LastExecutedPrice,
+			Qty:             report.LastExecutedQty,
+			Commission:      report.Commission,
+			CommissionAsset: report.CommissionAsset,
+			TradeId:         report.TradeId,
+		}}
+	}
+
+	return t.apply(u)
+}
+
+// Get returns the current state of an order by order ID
+func (t *OrderTracker) Get(orderId int64) (*TrackedOrder, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.byOrderId[orderId]
+	if !ok {
+		return nil, false
+	}
+	return e.snapshot(), true
+}
+
+// GetByClientOrderId returns the current state of an order by client order ID
+func (t *OrderTracker) GetByClientOrderId(clientOrderId string) (*TrackedOrder, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.byClientOrderId[clientOrderId]
+	if !ok {
+		return nil, false
+	}
+	return e.snapshot(), true
+}
+
+// Remove stops tracking an order
+func (t *OrderTracker) Remove(orderId int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.byOrderId[orderId]
+	if !ok {
+		return
+	}
+	delete(t.byOrderId, orderId)
+	if e.order.ClientOrderId != "" {
+		delete(t.byClientOrderId, e.order.ClientOrderId)
+	}
+}
+
+// Refresh queries the order with TradingService.QueryOrder and applies the result
+func (t *OrderTracker) Refresh(orderId int64) (*TrackedOrder, error) {
+	t.mu.Lock()
+	e, ok := t.byOrderId[orderId]
+	var req models.QueryOrderRequest
+	if ok {
+		req = models.QueryOrderRequest{Symbol: e.order.Symbol, OrderId: orderId}
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return nil, ErrOrderNotTracked
+	}
+	return t.refresh(req)
+}
+
+func (t *OrderTracker) refresh(req models.QueryOrderRequest) (*TrackedOrder, error) {
+	if t.trading == nil {
+		return nil, errors.New("order tracker has no trading service to poll with")
+	}
+
+	order, err := t.trading.QueryOrder(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.ApplyOrder(order)
+}
+
+// WaitForTerminal blocks until the order reaches a terminal status or the timeout
+// elapses. While waiting the order is polled at the configured poll interval.
+func (t *OrderTracker) WaitForTerminal(orderId int64, timeout time.Duration) (*TrackedOrder, error) {
+	t.mu.Lock()
+	e, ok := t.byOrderId[orderId]
+	t.mu.Unlock()
+
+	if !ok {
+		return nil, ErrOrderNotTracked
+	}
+	return t.wait(e, timeout)
+}
+
+// WaitForTerminalByClientOrderId is like WaitForTerminal but looks the order up by client order ID
+func (t *OrderTracker) WaitForTerminalByClientOrderId(clientOrderId string, timeout time.Duration) (*TrackedOrder, error) {
+	t.mu.Lock()
+	e, ok := t.byClientOrderId[clientOrderId]
+	t.mu.Unlock()
+
+	if !ok {
+		return nil, ErrOrderNotTracked
+	}
+	return t.wait(e, timeout)
+}
+
+func (t *OrderTracker) wait(e *trackedEntry, timeout time.Duration) (*TrackedOrder, error) {
+	t.mu.Lock()
+	interval := t.pollInterval
+	t.mu.Unlock()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var poll <-chan time.Time
+	if interval > 0 && t.trading != nil {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	var pollErr error
+	for {
+		select {
+		case <-e.done:
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return e.snapshot(), nil
+		case <-poll:
+			t.mu.Lock()
+			req := models.QueryOrderRequest{Symbol: e.order.Symbol, OrderId: e.order.OrderId}
+			if req.OrderId == 0 {
+				req.OrigClientOrderId = e.order.ClientOrderId
+			}
+			t.mu.Unlock()
+
+			if _, err := t.refresh(req); err != nil {
+				pollErr = err
+			}
+		case <-deadline.C:
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if pollErr != nil {
+				return e.snapshot(), fmt.Errorf("%w (last poll error: %v)", ErrOrderWaitTimeout, pollErr)
+			}
+			return e.snapshot(), ErrOrderWaitTimeout
+		}
+	}
+}
+
+// apply merges an update into the tracked state of its order
+func (t *OrderTracker) apply(u orderUpdate) (*TrackedOrder, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e := t.byOrderId[u.orderId]
+	if e == nil && u.clientOrderId != "" {
+		e = t.byClientOrderId[u.clientOrderId]
+	}
+
+	if e == nil {
+		if u.orderId == 0 && u.clientOrderId == "" {
+			return nil, errors.New("order update has neither order ID nor client order ID")
+		}
+		e = &trackedEntry{
+			tradeId: make(map[int64]bool),
+			done:    make(chan struct{}),
+		}
+		e.order.Status = models.OrderStatusNew
+	}
+
+	cur := &e.order
+
+	// ACK responses carry no status; anything else with a lower fill or an older
+	// timestamp than what we already have is a stale, out of order update
+	status := u.status
+	if status == "" {
+		status = cur.Status
+	}
+	stale := u.updateTime > 0 && u.updateTime < cur.UpdateTime
+	if u.executedQty != "" && cur.ExecutedQty != "" {
+		var p utils.DecimalParser
+		if p.Parse(u.executedQty).Cmp(p.Parse(cur.ExecutedQty)) < 0 {
+			stale = true
+		}
+		if p.Err != nil {
+			return nil, fmt.Errorf("invalid executed quantity: %w", p.Err)
+		}
+	}
+
+	if !stale && !canTransition(cur.Status, status) {
+		return nil, &InvalidTransitionError{
+			OrderId:       u.orderId,
+			ClientOrderId: u.clientOrderId,
+			From:          cur.Status,
+			To:            status,
+		}
+	}
+
+	if u.orderId != 0 && cur.OrderId == 0 {
+		cur.OrderId = u.orderId
+		t.byOrderId[u.orderId] = e
+	}
+	if u.clientOrderId != "" && cur.ClientOrderId == "" {
+		cur.ClientOrderId = u.clientOrderId
+		t.byClientOrderId[u.clientOrderId] = e
+	}
+	if cur.Symbol == "" {
+		cur.Symbol = u.symbol
+	}
+	if cur.Side == "" {
+		cur.Side = u.side
+	}
+	if cur.Type == "" {
+		cur.Type = u.orderType
+	}
+	if cur.OrigQty == "" {
+		cur.OrigQty = u.origQty
+	}
+
+	for _, f := range u.fills {
+		if f.TradeId != 0 && e.tradeId[f.TradeId] {
+			continue
+		}
+		e.tradeId[f.TradeId] = true
+		cur.Fills = append(cur.Fills, f)
+	}
+
+	if !stale {
+		cur.Status = status
+		if u.executedQty != "" {
+			cur.ExecutedQty = u.executedQty
+		}
+		if u.cumQuoteQty != "" {
+			cur.CummulativeQuoteQty = u.cumQuoteQty
+		}
+		if u.updateTime > cur.UpdateTime {
+			cur.UpdateTime = u.updateTime
+		}
+	}
+
+	avgPrice, err := averagePrice(cur)
+	if err != nil {
+		return nil, err
+	}
+	cur.AvgPrice = avgPrice
+
+	if cur.Status.IsTerminal() {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+	}
+
+	return e.snapshot(), nil
+}
+
+// averagePrice derives the average fill price from the cumulative quote and base
+// quantities, falling back to the individual fills when those are not known
+func averagePrice(o *TrackedOrder) (string, error) {
+	var p utils.DecimalParser
+	executed := p.Parse(o.ExecutedQty)
+	quote := p.Parse(o.CummulativeQuoteQty)
+
+	if executed.Sign() <= 0 || quote.Sign() <= 0 {
+		executed.SetInt64(0)
+		quote.SetInt64(0)
+		for _, f := range o.Fills {
+			qty := p.Parse(f.Qty)
+			executed.Add(executed, qty)
+			quote.Add(quote, qty.Mul(qty, p.Parse(f.Price)))
+		}
+	}
+
+	if p.Err != nil {
+		return "", fmt.Errorf("invalid fill quantity: %w", p.Err)
+	}
+	if executed.Sign() <= 0 {
+		return "", nil
+	}
+
+	return utils.FormatDecimal(quote.Quo(quote, executed), 8), nil
+}
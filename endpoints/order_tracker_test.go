@@ -0,0 +1,284 @@
+package endpoints_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+)
+
+func TestOrderTrackerTransitions(t *testing.T) {
+	tests := []struct {
+		from    models.OrderStatus
+		to      models.OrderStatus
+		wantErr bool
+	}{
+		{from: models.OrderStatusNew, to: models.OrderStatusNew},
+		{from: models.OrderStatusNew, to: models.OrderStatusPartiallyFilled},
+		{from: models.OrderStatusNew, to: models.OrderStatusFilled},
+		{from: models.OrderStatusNew, to: models.OrderStatusRejected},
+		{from: models.OrderStatusPartiallyFilled, to: models.OrderStatusFilled},
+		{from: models.OrderStatusPartiallyFilled, to: models.OrderStatusCanceled},
+		{from: models.OrderStatusPartiallyFilled, to: models.OrderStatusNew, wantErr: true},
+		{from: models.OrderStatusPartiallyFilled, to: models.OrderStatusRejected, wantErr: true},
+		{from: models.OrderStatusPendingCancel, to: models.OrderStatusCanceled},
+		{from: models.OrderStatusPendingCancel, to: models.OrderStatusExpiredInMatch, wantErr: true},
+		{from: models.OrderStatusFilled, to: models.OrderStatusFilled},
+		{from: models.OrderStatusFilled, to: models.OrderStatusCanceled, wantErr: true},
+		{from: models.OrderStatusCanceled, to: models.OrderStatusPartiallyFilled, wantErr: true},
+		{from: models.OrderStatusExpired, to: models.OrderStatusNew, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			tracker := endpoints.NewOrderTracker(nil)
+			if _, err := tracker.ApplyOrder(&models.Order{Symbol: "BTCUSDT", OrderId: 1, Status: tt.from, ExecutedQty: "0", UpdateTime: 1}); err != nil {
+				t.Fatalf("ApplyOrder(%s): %v", tt.from, err)
+			}
+
+			_, err := tracker.ApplyOrder(&models.Order{Symbol: "BTCUSDT", OrderId: 1, Status: tt.to, ExecutedQty: "0", UpdateTime: 2})
+			var transitionErr *endpoints.InvalidTransitionError
+			if tt.wantErr != errors.As(err, &transitionErr) {
+				t.Fatalf("expected an invalid transition %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("ApplyOrder(%s): %v", tt.to, err)
+			}
+
+			want := tt.to
+			if tt.wantErr {
+				want = tt.from
+			}
+			if o, _ := tracker.Get(1); o.Status != want {
+				t.Errorf("expected status %s, got %s", want, o.Status)
+			}
+		})
+	}
+}
+
+func TestOrderTrackerStaleUpdates(t *testing.T) {
+	tests := []struct {
+		name   string
+		update models.Order
+	}{
+		{
+			name:   "older update time",
+			update: models.Order{Status: models.OrderStatusNew, ExecutedQty: "0", UpdateTime: 100},
+		},
+		{
+			name:   "lower executed quantity",
+			update: models.Order{Status: models.OrderStatusPartiallyFilled, ExecutedQty: "0.2", UpdateTime: 300},
+		},
+		{
+			name:   "stale terminal status",
+			update: models.Order{Status: models.OrderStatusCanceled, ExecutedQty: "0.2", UpdateTime: 150},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := endpoints.NewOrderTracker(nil)
+			current := models.Order{Symbol: "BTCUSDT", OrderId: 1, Status: models.OrderStatusPartiallyFilled, ExecutedQty: "0.5", CummulativeQuoteQty: "25000", UpdateTime: 200}
+			if _, err := tracker.ApplyOrder(&current); err != nil {
+				t.Fatalf("ApplyOrder: %v", err)
+			}
+
+			update := tt.update
+			update.Symbol, update.OrderId = "BTCUSDT", 1
+			o, err := tracker.ApplyOrder(&update)
+			if err != nil {
+				t.Fatalf("expected the stale update to be ignored, got %v", err)
+			}
+			if o.Status != current.Status || o.ExecutedQty != current.ExecutedQty || o.UpdateTime != current.UpdateTime {
+				t.Errorf("expected the state to stay %s %s at %d, got %s %s at %d",
+					current.Status, current.ExecutedQty, current.UpdateTime, o.Status, o.ExecutedQty, o.UpdateTime)
+			}
+		})
+	}
+}
+
+func TestOrderTrackerDedupesFillsByTradeId(t *testing.T) {
+	tracker := endpoints.NewOrderTracker(nil)
+	_, err := tracker.ApplyOrderResponse(&models.OrderResponse{
+		Symbol:              "BTCUSDT",
+		OrderId:             1,
+		ClientOrderId:       "fills",
+		Status:              models.OrderStatusFilled,
+		OrigQty:             "1",
+		ExecutedQty:         "1",
+		CummulativeQuoteQty: "50500",
+		TransactTime:        100,
+		Fills: []models.OrderFill{
+			{Price: "50000", Qty: "0.5", TradeId: 10},
+			{Price: "51000", Qty: "0.5", TradeId: 11},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyOrderResponse: %v", err)
+	}
+
+	// the same fill arriving from the user data stream is not counted twice
+	o, err := tracker.ApplyExecutionReport(&models.ExecutionReport{
+		Symbol:              "BTCUSDT",
+		OrderId:             1,
+		ClientOrderId:       "fills",
+		ExecutionType:       models.ExecutionTypeTrade,
+		Status:              models.OrderStatusFilled,
+		LastExecutedQty:     "0.5",
+		LastExecutedPrice:   "51000",
+		CumulativeFilledQty: "1",
+		CumulativeQuoteQty:  "50500",
+		TransactionTime:     100,
+		TradeId:             11,
+	})
+	if err != nil {
+		t.Fatalf("ApplyExecutionReport: %v", err)
+	}
+	if len(o.Fills) != 2 {
+		t.Errorf("expected 2 fills, got %+v", o.Fills)
+	}
+	if o.AvgPrice != "50500" {
+		t.Errorf("expected average price 50500, got %s", o.AvgPrice)
+	}
+}
+
+func TestOrderTrackerExecutionReportOrder(t *testing.T) {
+	newReport := func(x models.ExecutionType, status models.OrderStatus, tradeId int64, last, cum, cumQuote string, time int64) *models.ExecutionReport {
+		return &models.ExecutionReport{
+			Symbol:              "BTCUSDT",
+			OrderId:             1,
+			ClientOrderId:       "stream",
+			Side:                models.SideBuy,
+			Type:                models.OrderTypeLimit,
+			Quantity:            "1",
+			ExecutionType:       x,
+			Status:              status,
+			TradeId:             tradeId,
+			LastExecutedQty:     last,
+			LastExecutedPrice:   "50000",
+			CumulativeFilledQty: cum,
+			CumulativeQuoteQty:  cumQuote,
+			TransactionTime:     time,
+		}
+	}
+	placed := newReport(models.ExecutionTypeNew, models.OrderStatusNew, 0, "0", "0", "0", 100)
+	first := newReport(models.ExecutionTypeTrade, models.OrderStatusPartiallyFilled, 10, "0.4", "0.4", "20000", 200)
+	second := newReport(models.ExecutionTypeTrade, models.OrderStatusFilled, 11, "0.6", "1", "50000", 300)
+
+	tests := []struct {
+		name    string
+		reports []*models.ExecutionReport
+	}{
+		{name: "in order", reports: []*models.ExecutionReport{placed, first, second}},
+		{name: "fill before placement", reports: []*models.ExecutionReport{first, placed, second}},
+		{name: "last fill first", reports: []*models.ExecutionReport{second, first, placed}},
+		{name: "duplicates", reports: []*models.ExecutionReport{placed, first, first, second, second, placed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := endpoints.NewOrderTracker(nil)
+			for _, r := range tt.reports {
+				if _, err := tracker.ApplyExecutionReport(r); err != nil {
+					t.Fatalf("ApplyExecutionReport(%s, trade %d): %v", r.Status, r.TradeId, err)
+				}
+			}
+
+			o, ok := tracker.GetByClientOrderId("stream")
+			if !ok {
+				t.Fatal("expected the order to be tracked")
+			}
+			if o.Status != models.OrderStatusFilled || o.ExecutedQty != "1" || o.UpdateTime != 300 {
+				t.Errorf("expected FILLED 1 at 300, got %s %s at %d", o.Status, o.ExecutedQty, o.UpdateTime)
+			}
+			if len(o.Fills) != 2 {
+				t.Errorf("expected 2 fills, got %+v", o.Fills)
+			}
+			if o.AvgPrice != "50000" {
+				t.Errorf("expected average price 50000, got %s", o.AvgPrice)
+			}
+		})
+	}
+}
+
+func TestOrderTrackerWaitForTerminal(t *testing.T) {
+	t.Run("untracked", func(t *testing.T) {
+		tracker := endpoints.NewOrderTracker(nil)
+		if _, err := tracker.WaitForTerminal(1, time.Millisecond); !errors.Is(err, endpoints.ErrOrderNotTracked) {
+			t.Fatalf("expected ErrOrderNotTracked, got %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		tracker := endpoints.NewOrderTracker(nil)
+		if _, err := tracker.ApplyOrder(&models.Order{Symbol: "BTCUSDT", OrderId: 1, Status: models.OrderStatusNew}); err != nil {
+			t.Fatalf("ApplyOrder: %v", err)
+		}
+		o, err := tracker.WaitForTerminal(1, 10*time.Millisecond)
+		if !errors.Is(err, endpoints.ErrOrderWaitTimeout) {
+			t.Fatalf("expected ErrOrderWaitTimeout, got %v", err)
+		}
+		if o.Status != models.OrderStatusNew {
+			t.Errorf("expected the last known status NEW, got %s", o.Status)
+		}
+	})
+
+	t.Run("update from another goroutine", func(t *testing.T) {
+		tracker := endpoints.NewOrderTracker(nil)
+		if _, err := tracker.ApplyOrder(&models.Order{Symbol: "BTCUSDT", OrderId: 1, ClientOrderId: "wait", Status: models.OrderStatusNew}); err != nil {
+			t.Fatalf("ApplyOrder: %v", err)
+		}
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			tracker.ApplyCancelResponse(&models.CancelOrderResponse{Symbol: "BTCUSDT", OrderId: 1, OrigClientOrderId: "wait", Status: models.OrderStatusCanceled})
+		}()
+
+		o, err := tracker.WaitForTerminalByClientOrderId("wait", 5*time.Second)
+		if err != nil {
+			t.Fatalf("WaitForTerminalByClientOrderId: %v", err)
+		}
+		if o.Status != models.OrderStatusCanceled {
+			t.Errorf("expected CANCELED, got %s", o.Status)
+		}
+	})
+
+	t.Run("polling", func(t *testing.T) {
+		srv := binancetest.NewServer()
+		defer srv.Close()
+		c := srv.NewClient()
+		srv.SetBalance("USDT", "10000")
+
+		tracker := endpoints.NewOrderTracker(c.Trading)
+		tracker.SetPollInterval(5 * time.Millisecond)
+
+		resp, err := c.Trading.NewOrder(models.NewOrderRequest{
+			Symbol:      "BTCUSDT",
+			Side:        models.SideBuy,
+			Type:        models.OrderTypeLimit,
+			TimeInForce: models.TimeInForceGTC,
+			Quantity:    "0.1",
+			Price:       "49000",
+		})
+		if err != nil {
+			t.Fatalf("NewOrder: %v", err)
+		}
+		if _, err := tracker.ApplyOrderResponse(resp); err != nil {
+			t.Fatalf("ApplyOrderResponse: %v", err)
+		}
+
+		// the order fills on the exchange without the tracker being told
+		srv.SetPrice("BTCUSDT", "48000")
+
+		o, err := tracker.WaitForTerminal(resp.OrderId, 5*time.Second)
+		if err != nil {
+			t.Fatalf("WaitForTerminal: %v", err)
+		}
+		if o.Status != models.OrderStatusFilled || o.ExecutedQty != "0.10000000" {
+			t.Errorf("expected FILLED 0.10000000, got %s %s", o.Status, o.ExecutedQty)
+		}
+	})
+}
@@ -0,0 +1,334 @@
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// Limits on the time range and page size of history endpoints
+const (
+	capitalHistoryMaxRange  = 90 * 24 * time.Hour
+	capitalHistoryPageSize  = 1000
+	tradeHistoryMaxRange    = 24 * time.Hour
+	tradeHistoryPageSize    = 1000
+	transferHistoryPageSize = 100
+)
+
+// Iterator yields the records of a paginated history endpoint one at a time,
+// oldest first and without duplicates:
+//
+//	it := client.Deposit.IterateDepositHistory(req)
+//	for it.Next() {
+//		d := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	windowStart time.Time
+	end         time.Time
+	windowSize  time.Duration
+	fetch       func(start, end time.Time) ([]T, error)
+	key         func(T) string
+	less        func(a, b T) bool
+
+	buf  []T
+	cur  T
+	err  error
+	seen map[string]bool
+}
+
+func newIterator[T any](start, end time.Time, windowSize time.Duration, fetch func(start, end time.Time) ([]T, error), key func(T) string, less func(a, b T) bool) *Iterator[T] {
+	return &Iterator[T]{
+		windowStart: start,
+		end:         end,
+		windowSize:  windowSize,
+		fetch:       fetch,
+		key:         key,
+		less:        less,
+		seen:        make(map[string]bool),
+	}
+}
+
+func failedIterator[T any](err error) *Iterator[T] {
+	return &Iterator[T]{err: err}
+}
+
+// Next advances to the next record, fetching the next window when needed. It
+// returns false when all records have been read or an error occurred.
+func (it *Iterator[T]) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil || it.fetch == nil || it.windowStart.After(it.end) {
+			return false
+		}
+
+		// Windows are inclusive at both ends, so each one stops a millisecond
+		// short of where the next begins
+		windowEnd := it.windowStart.Add(it.windowSize - time.Millisecond)
+		if windowEnd.After(it.end) {
+			windowEnd = it.end
+		}
+
+		records, err := it.fetch(it.windowStart, windowEnd)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.windowStart = windowEnd.Add(time.Millisecond)
+
+		sort.SliceStable(records, func(i, j int) bool { return it.less(records[i], records[j]) })
+
+		// Keys are remembered for the whole iteration, so a record repeated
+		// across pages or in any later window is only yielded once
+		for _, r := range records {
+			k := it.key(r)
+			if it.seen[k] {
+				continue
+			}
+			it.seen[k] = true
+			it.buf = append(it.buf, r)
+		}
+	}
+
+	it.cur = it.buf[0]
+	it.buf = it.buf[1:]
+	return true
+}
+
+// Value returns the current record
+func (it *Iterator[T]) Value() T {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All reads the remaining records into a slice
+func (it *Iterator[T]) All() ([]T, error) {
+	var all []T
+	for it.Next() {
+		all = append(all, it.Value())
+	}
+	return all, it.Err()
+}
+
+// pageByOffset fetches every page of an offset/limit paginated endpoint
+func pageByOffset[T any](pageSize int, fetch func(offset, limit int) ([]T, error)) ([]T, error) {
+	var all []T
+	for offset := 0; ; offset += pageSize {
+		page, err := fetch(offset, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, page...)
+		if len(page) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// pageById fetches every record between start and end from an endpoint that
+// returns the oldest records from startTime or from an id. The first page is
+// fetched by time and the following ones from the id after the highest one
+// seen, so records sharing a millisecond are never skipped. Paging stops at the
+// first record past end.
+func pageById[T any](start, end time.Time, pageSize int, byTime func(start, end time.Time, limit int) ([]T, error), fromId func(id int64, limit int) ([]T, error), idOf func(T) int64, timeOf func(T) int64) ([]T, error) {
+	var all []T
+	page, err := byTime(start, end, pageSize)
+	for next := int64(0); ; {
+		if err != nil {
+			return nil, err
+		}
+
+		prev, past := next, false
+		for _, r := range page {
+			if id := idOf(r); id >= next {
+				next = id + 1
+			}
+			t := timeOf(r)
+			if t > end.UnixMilli() {
+				past = true
+			} else if t >= start.UnixMilli() {
+				all = append(all, r)
+			}
+		}
+		if len(page) < pageSize || past {
+			return all, nil
+		}
+		if next <= prev {
+			return nil, fmt.Errorf("history did not advance past id %d", prev)
+		}
+
+		page, err = fromId(next, pageSize)
+	}
+}
+
+// defaultRange fills in a missing history range: end defaults to now and
+// start to maxRange before end
+func defaultRange(start, end time.Time, maxRange time.Duration) (time.Time, time.Time) {
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if start.IsZero() {
+		start = end.Add(-maxRange)
+	}
+	return start, end
+}
+
+// IterateDepositHistory iterates over every deposit between req.StartTime and
+// req.EndTime, splitting the range into 90 day windows and paging within each.
+// Offset and Limit are ignored. A zero StartTime starts 90 days before EndTime;
+// a zero EndTime means now.
+func (s *DepositService) IterateDepositHistory(req models.DepositHistoryRequest) *Iterator[models.DepositHistory] {
+	start, end := defaultRange(req.StartTime, req.EndTime, capitalHistoryMaxRange)
+
+	fetch := func(start, end time.Time) ([]models.DepositHistory, error) {
+		return pageByOffset(capitalHistoryPageSize, func(offset, limit int) ([]models.DepositHistory, error) {
+			r := req
+			r.StartTime, r.EndTime, r.Offset, r.Limit = start, end, offset, limit
+			return s.GetDepositHistory(r)
+		})
+	}
+
+	return newIterator(start, end, capitalHistoryMaxRange, fetch,
+		depositKey,
+		func(a, b models.DepositHistory) bool { return a.InsertTime < b.InsertTime },
+	)
+}
+
+// IterateWithdrawalHistory iterates over every withdrawal between req.StartTime
+// and req.EndTime, splitting the range into 90 day windows and paging within each.
+// Offset and Limit are ignored. A zero StartTime starts 90 days before EndTime;
+// a zero EndTime means now.
+func (s *WithdrawalService) IterateWithdrawalHistory(req models.WithdrawalHistoryRequest) *Iterator[models.WithdrawalHistory] {
+	start, end := defaultRange(req.StartTime, req.EndTime, capitalHistoryMaxRange)
+
+	fetch := func(start, end time.Time) ([]models.WithdrawalHistory, error) {
+		return pageByOffset(capitalHistoryPageSize, func(offset, limit int) ([]models.WithdrawalHistory, error) {
+			r := req
+			r.StartTime, r.EndTime, r.Offset, r.Limit = start, end, offset, limit
+			return s.GetWithdrawalHistory(r)
+		})
+	}
+
+	return newIterator(start, end, capitalHistoryMaxRange, fetch,
+		func(w models.WithdrawalHistory) string { return w.Id },
+		// applyTime is in WithdrawalTimeLayout, UTC, so it sorts lexically
+		func(a, b models.WithdrawalHistory) bool { return a.ApplyTime < b.ApplyTime },
+	)
+}
+
+// IterateAllOrders iterates over every order of req.Symbol created between
+// req.StartTime and req.EndTime, in 24 hour windows. OrderId and Limit are
+// ignored. StartTime is required; a zero EndTime means now.
+func (s *TradingService) IterateAllOrders(req models.AllOrdersRequest) *Iterator[models.Order] {
+	if req.StartTime.IsZero() {
+		return failedIterator[models.Order](errors.New("startTime is required to iterate orders"))
+	}
+	start, end := defaultRange(req.StartTime, req.EndTime, tradeHistoryMaxRange)
+
+	fetch := func(start, end time.Time) ([]models.Order, error) {
+		return pageById(start, end, tradeHistoryPageSize,
+			func(start, end time.Time, limit int) ([]models.Order, error) {
+				r := req
+				r.OrderId, r.StartTime, r.EndTime, r.Limit = 0, start, end, limit
+				return s.GetAllOrders(r)
+			},
+			func(id int64, limit int) ([]models.Order, error) {
+				r := req
+				r.OrderId, r.StartTime, r.EndTime, r.Limit = id, time.Time{}, time.Time{}, limit
+				return s.GetAllOrders(r)
+			},
+			func(o models.Order) int64 { return o.OrderId },
+			func(o models.Order) int64 { return o.Time },
+		)
+	}
+
+	return newIterator(start, end, tradeHistoryMaxRange, fetch,
+		func(o models.Order) string { return strconv.FormatInt(o.OrderId, 10) },
+		func(a, b models.Order) bool {
+			if a.Time != b.Time {
+				return a.Time < b.Time
+			}
+			return a.OrderId < b.OrderId
+		},
+	)
+}
+
+// IterateMyTrades iterates over every trade of req.Symbol between req.StartTime
+// and req.EndTime, in 24 hour windows. OrderId, FromId and Limit are ignored.
+// StartTime is required; a zero EndTime means now.
+func (s *TradingService) IterateMyTrades(req models.MyTradesRequest) *Iterator[models.Trade] {
+	if req.StartTime.IsZero() {
+		return failedIterator[models.Trade](errors.New("startTime is required to iterate trades"))
+	}
+	start, end := defaultRange(req.StartTime, req.EndTime, tradeHistoryMaxRange)
+
+	fetch := func(start, end time.Time) ([]models.Trade, error) {
+		return pageById(start, end, tradeHistoryPageSize,
+			func(start, end time.Time, limit int) ([]models.Trade, error) {
+				r := req
+				r.OrderId, r.FromId, r.StartTime, r.EndTime, r.Limit = 0, 0, start, end, limit
+				return s.GetMyTrades(r)
+			},
+			// fromId cannot be combined with a time range
+			func(id int64, limit int) ([]models.Trade, error) {
+				r := req
+				r.OrderId, r.FromId, r.StartTime, r.EndTime, r.Limit = 0, id, time.Time{}, time.Time{}, limit
+				return s.GetMyTrades(r)
+			},
+			func(t models.Trade) int64 { return t.Id },
+			func(t models.Trade) int64 { return t.Time },
+		)
+	}
+
+	return newIterator(start, end, tradeHistoryMaxRange, fetch,
+		func(t models.Trade) string { return strconv.FormatInt(t.Id, 10) },
+		func(a, b models.Trade) bool {
+			if a.Time != b.Time {
+				return a.Time < b.Time
+			}
+			return a.Id < b.Id
+		},
+	)
+}
+
+// IterateTransferHistory iterates over every universal transfer of req.Type
+// between req.StartTime and req.EndTime, paging by page number. Current and Size
+// are ignored. A zero StartTime starts 6 months before EndTime, the furthest
+// back the endpoint reaches; a zero EndTime means now.
+func (s *AccountService) IterateTransferHistory(req models.TransferHistoryRequest) *Iterator[models.TransferRecord] {
+	start, end := defaultRange(req.StartTime, req.EndTime, 180*24*time.Hour)
+
+	fetch := func(start, end time.Time) ([]models.TransferRecord, error) {
+		var all []models.TransferRecord
+		for page := 1; ; page++ {
+			r := req
+			r.StartTime, r.EndTime, r.Current, r.Size = start, end, page, transferHistoryPageSize
+
+			history, err := s.GetTransferHistory(r)
+			if err != nil {
+				return nil, err
+			}
+
+			all = append(all, history.Rows...)
+			if len(history.Rows) < transferHistoryPageSize || len(all) >= history.Total {
+				return all, nil
+			}
+		}
+	}
+
+	// The whole range is fetched as a single window
+	return newIterator(start, end, end.Sub(start)+time.Millisecond, fetch,
+		func(t models.TransferRecord) string { return strconv.FormatInt(t.TranId, 10) },
+		func(a, b models.TransferRecord) bool { return a.Timestamp < b.Timestamp },
+	)
+}
@@ -0,0 +1,109 @@
+package endpoints_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/models"
+)
+
+func TestIterateTradeHistory(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+	c := srv.NewClient()
+	srv.SetBalance("USDT", "100000")
+
+	// More orders than fit on a page, all filled by one price move so that
+	// every trade shares a millisecond
+	const orders = 1100
+	start := time.Now().Add(-time.Minute)
+	for i := 0; i < orders; i++ {
+		_, err := c.Trading.NewOrder(models.NewOrderRequest{
+			Symbol:      "BTCUSDT",
+			Side:        models.SideBuy,
+			Type:        models.OrderTypeLimit,
+			TimeInForce: models.TimeInForceGTC,
+			Quantity:    "0.001",
+			Price:       "49000",
+		})
+		if err != nil {
+			t.Fatalf("NewOrder %d: %v", i, err)
+		}
+	}
+	at := time.Now()
+	srv.SetClock(func() time.Time { return at })
+	srv.SetPrice("BTCUSDT", "48000")
+	srv.SetClock(time.Now)
+
+	trades, err := c.Trading.IterateMyTrades(models.MyTradesRequest{Symbol: "BTCUSDT", StartTime: start}).All()
+	if err != nil {
+		t.Fatalf("IterateMyTrades: %v", err)
+	}
+	if len(trades) != orders {
+		t.Fatalf("expected %d trades, got %d", orders, len(trades))
+	}
+	if trades[0].Time != trades[len(trades)-1].Time {
+		t.Fatal("expected every trade in the same millisecond")
+	}
+	seen := make(map[int64]bool)
+	for _, tr := range trades {
+		if seen[tr.Id] {
+			t.Fatalf("trade %d yielded twice", tr.Id)
+		}
+		seen[tr.Id] = true
+	}
+
+	all, err := c.Trading.IterateAllOrders(models.AllOrdersRequest{Symbol: "BTCUSDT", StartTime: start}).All()
+	if err != nil {
+		t.Fatalf("IterateAllOrders: %v", err)
+	}
+	if len(all) != orders {
+		t.Fatalf("expected %d orders, got %d", orders, len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].OrderId <= all[i-1].OrderId {
+			t.Fatalf("orders out of order at %d: %d after %d", i, all[i].OrderId, all[i-1].OrderId)
+		}
+	}
+}
+
+func TestIterateTradeHistoryStopsAtEndTime(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+	c := srv.NewClient()
+	srv.SetBalance("USDT", "100000")
+
+	start := time.Now().Add(-time.Minute)
+	for i := 0; i < 1001; i++ {
+		if _, err := c.Trading.NewOrder(models.NewOrderRequest{
+			Symbol:   "BTCUSDT",
+			Side:     models.SideBuy,
+			Type:     models.OrderTypeMarket,
+			Quantity: "0.001",
+		}); err != nil {
+			t.Fatalf("NewOrder %d: %v", i, err)
+		}
+	}
+	end := time.Now()
+
+	// A trade after the end of the range is not returned, even though paging
+	// by id reaches it
+	time.Sleep(5 * time.Millisecond)
+	if _, err := c.Trading.NewOrder(models.NewOrderRequest{
+		Symbol:   "BTCUSDT",
+		Side:     models.SideBuy,
+		Type:     models.OrderTypeMarket,
+		Quantity: "0.001",
+	}); err != nil {
+		t.Fatalf("NewOrder: %v", err)
+	}
+
+	trades, err := c.Trading.IterateMyTrades(models.MyTradesRequest{Symbol: "BTCUSDT", StartTime: start, EndTime: end}).All()
+	if err != nil {
+		t.Fatalf("IterateMyTrades: %v", err)
+	}
+	if len(trades) != 1001 {
+		t.Errorf("expected 1001 trades, got %d", len(trades))
+	}
+}
@@ -0,0 +1,233 @@
+package endpoints
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MartianPay/go-binance/client"
+	"github.com/MartianPay/go-binance/models"
+)
+
+// Binance error codes relevant to resolving an order's outcome
+const (
+	errCodeUnknown          = -1000 // UNKNOWN: an unknown error occurred while processing the request
+	errCodeUnexpectedResp   = -1006 // UNEXPECTED_RESP: execution status unknown
+	errCodeTimeout          = -1007 // TIMEOUT: send status unknown, execution status unknown
+	errCodeNewOrderRejected = -2010 // NEW_ORDER_REJECTED, including "Duplicate order sent."
+	errCodeNoSuchOrder      = -2013 // NO_SUCH_ORDER: order does not exist
+	maxClientOrderIdLength  = 36
+	// minClientOrderIdRandom is the least number of random hex characters a
+	// generated client order ID keeps after its prefix (48 bits)
+	minClientOrderIdRandom = 12
+)
+
+// clientOrderIdPattern is Binance's format for client order IDs
+var clientOrderIdPattern = regexp.MustCompile(`^[a-zA-Z0-9-_]{1,36}$`)
+
+// ErrOrderOutcomeUnknown is returned when an order placement failed ambiguously
+// and its outcome could not be determined by querying the order
+var ErrOrderOutcomeUnknown = errors.New("order outcome unknown")
+
+// SafeOrderOptions configures NewOrderSafe
+type SafeOrderOptions struct {
+	// MaxAttempts is the maximum number of placement attempts (default 3)
+	MaxAttempts int
+	// QueryAttempts is the number of times the order is queried after an
+	// ambiguous failure before giving up (default 5)
+	QueryAttempts int
+	// QueryDelay is the delay before each query, giving an in-flight order time
+	// to reach the matching engine (default 1s)
+	QueryDelay time.Duration
+	// ClientOrderIdPrefix is prepended to generated client order IDs. It may
+	// use letters, digits, - and _, and be at most 24 characters long.
+	ClientOrderIdPrefix string
+}
+
+// SafeOrderResult is the definitive outcome of NewOrderSafe. Exactly one of
+// Response and Order is set.
+type SafeOrderResult struct {
+	ClientOrderId string
+	// Response is the placement response when a placement attempt succeeded
+	Response *models.OrderResponse
+	// Order is the queried order when the outcome was resolved after an ambiguous failure
+	Order *models.Order
+	// Attempts is the number of placement attempts made
+	Attempts int
+}
+
+// GenerateClientOrderId returns prefix followed by random hex characters,
+// filling Binance's 36 character limit. The prefix must match Binance's
+// ^[a-zA-Z0-9-_]{1,36}$ format and leave room for at least 12 random characters.
+func GenerateClientOrderId(prefix string) (string, error) {
+	if prefix != "" && !clientOrderIdPattern.MatchString(prefix) {
+		return "", fmt.Errorf("invalid client order ID prefix %q: only letters, digits, - and _ are allowed", prefix)
+	}
+	n := maxClientOrderIdLength - len(prefix)
+	if n < minClientOrderIdRandom {
+		return "", fmt.Errorf("client order ID prefix %q is longer than %d characters", prefix, maxClientOrderIdLength-minClientOrderIdRandom)
+	}
+
+	b := make([]byte, (n+1)/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate client order ID: %w", err)
+	}
+	return prefix + hex.EncodeToString(b)[:n], nil
+}
+
+// IsUnknownOutcome reports whether err leaves the result of a request unknown:
+// transport failures, undecodable responses, 5xx responses and Binance's
+// "execution status unknown" codes. Errors raised before a request is sent,
+// such as validation errors, are definitive.
+func IsUnknownOutcome(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode >= http.StatusInternalServerError {
+			return true
+		}
+
+		switch apiErr.Code {
+		case errCodeUnknown, errCodeUnexpectedResp, errCodeTimeout:
+			return true
+		}
+
+		return false
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &urlErr) ||
+		errors.As(err, &netErr) ||
+		errors.As(err, &syntaxErr) ||
+		errors.As(err, &typeErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isNoSuchOrder reports whether err is Binance's "order does not exist" error
+func isNoSuchOrder(err error) bool {
+	var apiErr *client.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == errCodeNoSuchOrder
+}
+
+// isDuplicateOrder reports whether err rejects an order because an open order
+// already uses its client order ID
+func isDuplicateOrder(err error) bool {
+	var apiErr *client.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == errCodeNewOrderRejected && strings.Contains(apiErr.Message, "Duplicate order")
+}
+
+// NewOrderSafe places an order with a unique client order ID and resolves
+// ambiguous failures. If NewClientOrderId is empty one is generated. When a
+// placement attempt fails with a network error or 5xx the order is queried by
+// its client order ID; placement is retried only if the order does not exist
+// at that moment. A retry rejected as a duplicate resolves to the original order.
+//
+// Binance only rejects a duplicate client order ID while the original order is
+// open. If the original request reaches the matching engine after the query
+// and the order fills or is cancelled before the retry arrives, the retry
+// places a second order. Set MaxAttempts to 1 where that risk is unacceptable;
+// ambiguous failures are then resolved by querying but never retried.
+func (s *TradingService) NewOrderSafe(req models.NewOrderRequest, opts SafeOrderOptions) (*SafeOrderResult, error) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.QueryAttempts <= 0 {
+		opts.QueryAttempts = 5
+	}
+	if opts.QueryDelay <= 0 {
+		opts.QueryDelay = time.Second
+	}
+
+	if req.NewClientOrderId == "" {
+		id, err := GenerateClientOrderId(opts.ClientOrderIdPrefix)
+		if err != nil {
+			return nil, err
+		}
+		req.NewClientOrderId = id
+	} else if !clientOrderIdPattern.MatchString(req.NewClientOrderId) {
+		return nil, fmt.Errorf("invalid client order ID %q", req.NewClientOrderId)
+	}
+
+	result := &SafeOrderResult{ClientOrderId: req.NewClientOrderId}
+
+	var lastErr error
+	for result.Attempts < opts.MaxAttempts {
+		result.Attempts++
+
+		resp, err := s.NewOrder(req)
+		if err == nil {
+			result.Response = resp
+			return result, nil
+		}
+
+		// The original request arrived late and its order is still open
+		if result.Attempts > 1 && isDuplicateOrder(err) {
+			order, qerr := s.resolveOrder(req.Symbol, req.NewClientOrderId, opts)
+			if qerr != nil || order == nil {
+				return result, fmt.Errorf("%w: retry rejected as duplicate, then %v", ErrOrderOutcomeUnknown, qerr)
+			}
+			result.Order = order
+			return result, nil
+		}
+
+		if !IsUnknownOutcome(err) {
+			return result, err
+		}
+		lastErr = err
+
+		order, err := s.resolveOrder(req.Symbol, req.NewClientOrderId, opts)
+		if err != nil {
+			return result, fmt.Errorf("%w: placement failed with %v, then %v", ErrOrderOutcomeUnknown, lastErr, err)
+		}
+
+		if order != nil {
+			result.Order = order
+			return result, nil
+		}
+	}
+
+	return result, fmt.Errorf("order %s not placed after %d attempts: %w", req.NewClientOrderId, result.Attempts, lastErr)
+}
+
+// resolveOrder queries an order by client order ID. It returns (nil, nil) only
+// when Binance confirms the order does not exist.
+func (s *TradingService) resolveOrder(symbol, clientOrderId string, opts SafeOrderOptions) (*models.Order, error) {
+	var lastErr error
+	for i := 0; i < opts.QueryAttempts; i++ {
+		time.Sleep(opts.QueryDelay)
+
+		order, err := s.QueryOrder(models.QueryOrderRequest{
+			Symbol:            symbol,
+			OrigClientOrderId: clientOrderId,
+		})
+		if err == nil {
+			return order, nil
+		}
+
+		if isNoSuchOrder(err) {
+			return nil, nil
+		}
+
+		if !IsUnknownOutcome(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("failed to resolve order %s after %d queries: %w", clientOrderId, opts.QueryAttempts, lastErr)
+}
@@ -0,0 +1,133 @@
+package endpoints_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+)
+
+func TestGenerateClientOrderId(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		wantErr bool
+	}{
+		{name: "no prefix"},
+		{name: "prefix", prefix: "grid-bot_1"},
+		{name: "longest prefix", prefix: strings.Repeat("a", 24)},
+		{name: "prefix too long", prefix: strings.Repeat("a", 25), wantErr: true},
+		{name: "invalid characters", prefix: "grid.bot", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := endpoints.GenerateClientOrderId(tt.prefix)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(id) != 36 || !strings.HasPrefix(id, tt.prefix) {
+				t.Errorf("expected a 36 character ID starting with %q, got %q", tt.prefix, id)
+			}
+		})
+	}
+}
+
+func TestNewOrderSafe(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault *binancetest.Fault
+		// wantResponse is set when a placement attempt succeeds and wantOrder
+		// when the outcome is resolved by querying the order
+		wantResponse bool
+		wantOrder    bool
+		wantErr      bool
+		wantAttempts int
+	}{
+		{
+			name:         "placed",
+			wantResponse: true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "lost before reaching the engine is placed again",
+			fault:        &binancetest.Fault{Status: http.StatusServiceUnavailable, Code: -1001, Msg: "Internal error; unable to process your request. Please try again."},
+			wantResponse: true,
+			wantAttempts: 2,
+		},
+		{
+			name:         "response lost after placement resolves to the order",
+			fault:        &binancetest.Fault{Status: http.StatusGatewayTimeout, Code: -1007, Msg: "Timeout waiting for response from backend server.", Processed: true},
+			wantOrder:    true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "definitive rejection is not retried",
+			fault:        &binancetest.Fault{Status: http.StatusBadRequest, Code: -2010, Msg: "Account has insufficient balance for requested action."},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := binancetest.NewServer()
+			defer srv.Close()
+			c := srv.NewClient()
+			srv.SetBalance("USDT", "10000")
+			if tt.fault != nil {
+				f := *tt.fault
+				f.Method, f.Path, f.Times = http.MethodPost, "/api/v3/order", 1
+				srv.InjectFault(f)
+			}
+
+			result, err := c.Trading.NewOrderSafe(
+				models.NewOrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "0.1"},
+				endpoints.SafeOrderOptions{ClientOrderIdPrefix: "test-", QueryDelay: time.Millisecond},
+			)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if endpoints.IsUnknownOutcome(err) {
+					t.Errorf("expected a definitive error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("NewOrderSafe: %v", err)
+			}
+
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, result.Attempts)
+			}
+			if (result.Response != nil) != tt.wantResponse || (result.Order != nil) != tt.wantOrder {
+				t.Errorf("expected response %v and order %v, got %+v", tt.wantResponse, tt.wantOrder, result)
+			}
+			if !strings.HasPrefix(result.ClientOrderId, "test-") {
+				t.Errorf("expected a generated client order ID with the prefix, got %q", result.ClientOrderId)
+			}
+
+			// However the outcome was learned, at most one order was placed
+			orders, err := c.Trading.GetAllOrders(models.AllOrdersRequest{Symbol: "BTCUSDT"})
+			if err != nil {
+				t.Fatalf("GetAllOrders: %v", err)
+			}
+			want := 1
+			if tt.wantErr {
+				want = 0
+			}
+			if len(orders) != want {
+				t.Errorf("expected %d orders, got %d", want, len(orders))
+			}
+		})
+	}
+}
@@ -174,11 +174,11 @@ func (s *TradingService) GetAllOrders(req models.AllOrdersRequest) ([]models.Ord
 	}
 	
 	if !req.StartTime.IsZero() {
-		params["startTime"] = strconv.FormatInt(req.StartTime.Unix()*1000, 10)
+		params["startTime"] = strconv.FormatInt(req.StartTime.UnixMilli(), 10)
 	}
 	
 	if !req.EndTime.IsZero() {
-		params["endTime"] = strconv.FormatInt(req.EndTime.Unix()*1000, 10)
+		params["endTime"] = strconv.FormatInt(req.EndTime.UnixMilli(), 10)
 	}
 	
 	if req.Limit > 0 {
@@ -235,11 +235,11 @@ func (s *TradingService) GetMyTrades(req models.MyTradesRequest) ([]models.Trade
 	}
 
 	if !req.StartTime.IsZero() {
-		params["startTime"] = strconv.FormatInt(req.StartTime.Unix()*1000, 10)
+		params["startTime"] = strconv.FormatInt(req.StartTime.UnixMilli(), 10)
 	}
 
 	if !req.EndTime.IsZero() {
-		params["endTime"] = strconv.FormatInt(req.EndTime.Unix()*1000, 10)
+		params["endTime"] = strconv.FormatInt(req.EndTime.UnixMilli(), 10)
 	}
 
 	if req.FromId > 0 {
@@ -10,14 +10,27 @@ import (
 )
 
 type WithdrawalService struct {
-	client *client.Client
+	client    *client.Client
+	validator *WithdrawalValidator
 }
 
 func NewWithdrawalService(c *client.Client) *WithdrawalService {
 	return &WithdrawalService{client: c}
 }
 
+// SetValidator makes Withdraw validate every request against the coin's network
+// configuration before submitting it. Pass nil to disable validation.
+func (s *WithdrawalService) SetValidator(v *WithdrawalValidator) {
+	s.validator = v
+}
+
 func (s *WithdrawalService) Withdraw(req models.WithdrawalRequest) (*models.WithdrawalResponse, error) {
+	if s.validator != nil {
+		if err := s.validator.Validate(req); err != nil {
+			return nil, err
+		}
+	}
+	
 	params := make(map[string]string)
 	params["coin"] = req.Coin
 	params["address"] = req.Address
@@ -44,7 +57,7 @@ func (s *WithdrawalService) Withdraw(req models.WithdrawalRequest) (*models.With
 	}
 	
 	if req.WalletType != 0 {
-		params["walletType"] = strconv.Itoa(req.WalletType)
+		params["walletType"] = strconv.Itoa(int(req.WalletType))
 	}
 	
 	if req.RecvWindow > 0 {
@@ -75,8 +88,8 @@ func (s *WithdrawalService) GetWithdrawalHistory(req models.WithdrawalHistoryReq
 		params["withdrawOrderId"] = req.WithdrawOrderId
 	}
 	
-	if req.Status != 0 {
-		params["status"] = strconv.Itoa(req.Status)
+	if req.Status != nil {
+		params["status"] = strconv.Itoa(int(*req.Status))
 	}
 	
 	if !req.StartTime.IsZero() {
@@ -0,0 +1,229 @@
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// ErrWithdrawOrderIdRequired is returned by WithdrawOnce when the request has no WithdrawOrderId
+var ErrWithdrawOrderIdRequired = errors.New("withdrawOrderId is required")
+
+// ErrWithdrawalOutcomeUnknown is returned when a withdrawal failed ambiguously and
+// could not be found in the withdrawal history. Calling WithdrawOnce again with
+// the same WithdrawOrderId is safe: it submits only if the withdrawal is still absent.
+var ErrWithdrawalOutcomeUnknown = errors.New("withdrawal outcome unknown")
+
+// ErrWithdrawalWaitTimeout is returned when a withdrawal does not reach a terminal status in time
+var ErrWithdrawalWaitTimeout = errors.New("timed out waiting for terminal withdrawal status")
+
+// withdrawalClockSkew is how far before the local submission time the history
+// is searched for a withdrawal after an ambiguous failure
+const withdrawalClockSkew = time.Hour
+
+// WithdrawOnceOptions configures WithdrawOnce
+type WithdrawOnceOptions struct {
+	// Since is the earliest time a withdrawal with the same WithdrawOrderId may
+	// have been submitted. The history is searched from Since in 90 day windows
+	// (default 90 days ago). A withdrawal submitted before Since is not found
+	// and is sent again, so set Since when retrying older withdrawals.
+	Since time.Time
+	// LookupAttempts is the number of history lookups after an ambiguous failure (default 5)
+	LookupAttempts int
+	// LookupDelay is the delay before each lookup (default 2s)
+	LookupDelay time.Duration
+}
+
+// WithdrawOnceResult is the outcome of WithdrawOnce
+type WithdrawOnceResult struct {
+	Id              string
+	WithdrawOrderId string
+	// TrId is the travel rule record of a withdrawal submitted by
+	// WithdrawLocalEntityOnce. Id is empty for such a withdrawal until it is
+	// found in the history.
+	TrId int64
+	// Existing is true when the withdrawal had already been submitted and was not sent again
+	Existing bool
+	// Recovered is true when the Id was recovered from the history after an ambiguous failure
+	Recovered bool
+}
+
+// WithdrawOnce checks req against the policy and submits it at most once per
+// WithdrawOrderId. The history is searched for the WithdrawOrderId before the
+// policy is checked, and after a network failure or 5xx the Id is recovered
+// from the history instead of resubmitting.
+func (g *WithdrawalGuard) WithdrawOnce(req models.WithdrawalRequest, opts WithdrawOnceOptions) (*WithdrawOnceResult, error) {
+	return g.withdrawOnce(req, opts, func(result *WithdrawOnceResult) error {
+		resp, err := g.service.Withdraw(req)
+		if err != nil {
+			return err
+		}
+		result.Id = resp.Id
+		return nil
+	})
+}
+
+// WithdrawLocalEntityOnce is WithdrawOnce for travel rule withdrawals made with
+// WithdrawLocalEntity. A questionnaire that is not accepted is returned as an error.
+func (g *WithdrawalGuard) WithdrawLocalEntityOnce(req models.LocalEntityWithdrawalRequest, opts WithdrawOnceOptions) (*WithdrawOnceResult, error) {
+	withdrawal := models.WithdrawalRequest{
+		Coin:               req.Coin,
+		Network:            req.Network,
+		Address:            req.Address,
+		AddressTag:         req.AddressTag,
+		Amount:             req.Amount,
+		WithdrawOrderId:    req.WithdrawOrderId,
+		TransactionFeeFlag: req.TransactionFeeFlag,
+		Name:               req.Name,
+		WalletType:         req.WalletType,
+		RecvWindow:         req.RecvWindow,
+	}
+
+	return g.withdrawOnce(withdrawal, opts, func(result *WithdrawOnceResult) error {
+		resp, err := g.service.WithdrawLocalEntity(req)
+		if err != nil {
+			return err
+		}
+		if !resp.Accepted {
+			return fmt.Errorf("travel rule withdrawal %s was not accepted: %s", req.WithdrawOrderId, resp.Info)
+		}
+		result.TrId = resp.TrId
+		return nil
+	})
+}
+
+// withdrawOnce runs the lookup, check and submit steps shared by WithdrawOnce
+// and WithdrawLocalEntityOnce. submit sends the withdrawal and fills in the
+// identifiers of result.
+func (g *WithdrawalGuard) withdrawOnce(req models.WithdrawalRequest, opts WithdrawOnceOptions, submit func(result *WithdrawOnceResult) error) (*WithdrawOnceResult, error) {
+	if req.WithdrawOrderId == "" {
+		return nil, ErrWithdrawOrderIdRequired
+	}
+	if opts.Since.IsZero() {
+		opts.Since = time.Now().Add(-capitalHistoryMaxRange)
+	}
+	if opts.LookupAttempts <= 0 {
+		opts.LookupAttempts = 5
+	}
+	if opts.LookupDelay <= 0 {
+		opts.LookupDelay = 2 * time.Second
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	result := &WithdrawOnceResult{WithdrawOrderId: req.WithdrawOrderId}
+
+	existing, err := g.service.findByWithdrawOrderId(req.Coin, req.WithdrawOrderId, opts.Since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing withdrawal: %w", err)
+	}
+	if existing != nil {
+		result.Id = existing.Id
+		result.Existing = true
+		return result, nil
+	}
+
+	if err := g.check(req); err != nil {
+		return nil, err
+	}
+
+	amount, err := utils.ParseDecimal(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	submitted := time.Now()
+	err = submit(result)
+	if err != nil && !IsUnknownOutcome(err) {
+		return nil, err
+	}
+
+	// An ambiguous withdrawal may have gone through, so it counts towards the
+	// limits until the history shows otherwise
+	g.recent = append(g.recent, recentWithdrawal{
+		id:              result.Id,
+		withdrawOrderId: req.WithdrawOrderId,
+		coin:            req.Coin,
+		amount:          amount,
+		at:              submitted,
+	})
+
+	if err == nil {
+		return result, nil
+	}
+
+	submitErr := err
+	for i := 0; i < opts.LookupAttempts; i++ {
+		time.Sleep(opts.LookupDelay)
+
+		existing, err := g.service.findByWithdrawOrderId(req.Coin, req.WithdrawOrderId, submitted.Add(-withdrawalClockSkew))
+		if err != nil {
+			return nil, fmt.Errorf("%w: withdrawal %s failed with %v, then the history lookup failed: %w", ErrWithdrawalOutcomeUnknown, req.WithdrawOrderId, submitErr, err)
+		}
+		if existing != nil {
+			result.Id = existing.Id
+			result.Recovered = true
+			return result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: withdrawal %s failed with %v and is not in the history", ErrWithdrawalOutcomeUnknown, req.WithdrawOrderId, submitErr)
+}
+
+// findByWithdrawOrderId searches the history from since until now for the
+// withdrawal with the given WithdrawOrderId, returning nil if there is none
+func (s *WithdrawalService) findByWithdrawOrderId(coin, withdrawOrderId string, since time.Time) (*models.WithdrawalHistory, error) {
+	it := s.IterateWithdrawalHistory(models.WithdrawalHistoryRequest{
+		Coin:            coin,
+		WithdrawOrderId: withdrawOrderId,
+		StartTime:       since,
+	})
+	for it.Next() {
+		if w := it.Value(); w.WithdrawOrderId == withdrawOrderId {
+			return &w, nil
+		}
+	}
+	return nil, it.Err()
+}
+
+// WaitForWithdrawal polls the withdrawal history until the withdrawal with the
+// given WithdrawOrderId reaches a terminal status (completed, rejected, failure
+// or cancelled) or the timeout elapses. Only withdrawals submitted within the
+// last 90 days are found. The returned record carries the TxId and
+// TransactionFee; callers should check its Status to tell success from failure.
+func (s *WithdrawalService) WaitForWithdrawal(withdrawOrderId string, pollInterval, timeout time.Duration) (*models.WithdrawalHistory, error) {
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+
+	since := time.Now().Add(-capitalHistoryMaxRange)
+	deadline := time.Now().Add(timeout)
+	var last *models.WithdrawalHistory
+	var lastErr error
+
+	for {
+		w, err := s.findByWithdrawOrderId("", withdrawOrderId, since)
+		if err != nil {
+			lastErr = err
+		} else if w != nil {
+			last = w
+			if w.Status.IsTerminal() {
+				return w, nil
+			}
+		}
+
+		if time.Now().Add(pollInterval).After(deadline) {
+			break
+		}
+		time.Sleep(pollInterval)
+	}
+
+	if last == nil && lastErr != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWithdrawalWaitTimeout, lastErr)
+	}
+	return last, ErrWithdrawalWaitTimeout
+}
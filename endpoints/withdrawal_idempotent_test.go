@@ -0,0 +1,116 @@
+package endpoints_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+)
+
+func TestWithdrawOnce(t *testing.T) {
+	tests := []struct {
+		name          string
+		amount        string
+		fault         *binancetest.Fault
+		wantRecovered bool
+		wantErr       error
+		wantRule      endpoints.PolicyRule
+		// wantWithdrawals is the number of withdrawals the server recorded
+		wantWithdrawals int
+	}{
+		{
+			name:            "submitted",
+			amount:          "10",
+			wantWithdrawals: 1,
+		},
+		{
+			name:            "response lost after submission is recovered from the history",
+			amount:          "10",
+			fault:           &binancetest.Fault{Status: http.StatusGatewayTimeout, Code: -1007, Msg: "Timeout waiting for response from backend server.", Processed: true},
+			wantRecovered:   true,
+			wantWithdrawals: 1,
+		},
+		{
+			name:            "lost before submission is reported as unknown",
+			amount:          "10",
+			fault:           &binancetest.Fault{Status: http.StatusServiceUnavailable, Code: -1001, Msg: "Internal error; unable to process your request. Please try again."},
+			wantErr:         endpoints.ErrWithdrawalOutcomeUnknown,
+			wantWithdrawals: 0,
+		},
+		{
+			name:            "policy is enforced",
+			amount:          "51",
+			wantRule:        endpoints.PolicyRuleSingleLimit,
+			wantWithdrawals: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newValidatorServer(t, "100")
+			c := srv.NewClient()
+			if tt.fault != nil {
+				f := *tt.fault
+				f.Method, f.Path, f.Times = http.MethodPost, "/sapi/v1/capital/withdraw/apply", 1
+				srv.InjectFault(f)
+			}
+
+			guard := endpoints.NewWithdrawalGuard(c.Withdrawal, endpoints.WithdrawalPolicy{
+				Limits: map[string]endpoints.CoinLimit{"XRP": {Single: "50"}},
+			})
+			req := models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, AddressTag: "12345", Amount: tt.amount, WithdrawOrderId: "payout-1"}
+			opts := endpoints.WithdrawOnceOptions{LookupAttempts: 2, LookupDelay: time.Millisecond}
+
+			result, err := guard.WithdrawOnce(req, opts)
+
+			var policyErr *endpoints.PolicyError
+			switch {
+			case tt.wantRule != "":
+				if !errors.As(err, &policyErr) || policyErr.Rule != tt.wantRule {
+					t.Fatalf("expected a %s rejection, got %v", tt.wantRule, err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			case err != nil:
+				t.Fatalf("WithdrawOnce: %v", err)
+			default:
+				if result.Id == "" || result.Existing || result.Recovered != tt.wantRecovered {
+					t.Errorf("expected a new withdrawal with recovered %v, got %+v", tt.wantRecovered, result)
+				}
+
+				// Retrying with the same WithdrawOrderId finds the withdrawal
+				// instead of sending it again
+				again, err := guard.WithdrawOnce(req, opts)
+				if err != nil {
+					t.Fatalf("WithdrawOnce again: %v", err)
+				}
+				if !again.Existing || again.Id != result.Id {
+					t.Errorf("expected the existing withdrawal %s, got %+v", result.Id, again)
+				}
+			}
+
+			if n := len(srv.Withdrawals()); n != tt.wantWithdrawals {
+				t.Errorf("expected %d withdrawals, got %d", tt.wantWithdrawals, n)
+			}
+		})
+	}
+}
+
+func TestWithdrawOnceRequiresWithdrawOrderId(t *testing.T) {
+	srv, _ := newValidatorServer(t, "100")
+	guard := endpoints.NewWithdrawalGuard(srv.NewClient().Withdrawal, endpoints.WithdrawalPolicy{})
+
+	_, err := guard.WithdrawOnce(models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, AddressTag: "12345", Amount: "10"}, endpoints.WithdrawOnceOptions{})
+	if !errors.Is(err, endpoints.ErrWithdrawOrderIdRequired) {
+		t.Fatalf("expected ErrWithdrawOrderIdRequired, got %v", err)
+	}
+	if n := len(srv.Withdrawals()); n != 0 {
+		t.Errorf("expected no withdrawals, got %d", n)
+	}
+}
@@ -0,0 +1,415 @@
+package endpoints
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// PolicyRule identifies the withdrawal policy rule that rejected a request
+type PolicyRule string
+
+const (
+	PolicyRuleAllowlist   PolicyRule = "ALLOWLIST"
+	PolicyRuleSingleLimit PolicyRule = "SINGLE_LIMIT"
+	PolicyRuleDailyLimit  PolicyRule = "DAILY_LIMIT"
+	PolicyRuleQuota       PolicyRule = "QUOTA"
+	PolicyRuleApproval    PolicyRule = "APPROVAL"
+	PolicyRuleNoLimits    PolicyRule = "NO_LIMITS"
+)
+
+// PolicyError is returned when a withdrawal is rejected by the policy
+type PolicyError struct {
+	Rule    PolicyRule
+	Coin    string
+	Address string
+	Reason  string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("withdrawal rejected by policy (%s): %s", e.Rule, e.Reason)
+}
+
+// AllowedAddress is an allowlisted withdrawal destination. An empty Network
+// matches any network; a set one matches withdrawals on that network, or
+// without a network when it is the coin's default network in
+// WithdrawalPolicy.Coins. A set AddressTag must match exactly; an empty one only
+// matches withdrawals without a tag, or with any tag on a network that is
+// known not to use memos.
+type AllowedAddress struct {
+	Coin       string
+	Network    string
+	Address    string
+	AddressTag string
+}
+
+// CoinLimit limits withdrawals of a single coin. Empty values are not enforced.
+type CoinLimit struct {
+	// Single is the maximum amount of one withdrawal
+	Single string
+	// Rolling24h is the maximum total amount withdrawn over any 24 hours
+	Rolling24h string
+}
+
+// WithdrawalPolicy configures a WithdrawalGuard
+type WithdrawalPolicy struct {
+	// AllowedAddresses restricts withdrawals to these destinations when RequireAllowlist is set
+	AllowedAddresses []AllowedAddress
+	// RequireAllowlist rejects withdrawals to addresses not in the allowlist
+	RequireAllowlist bool
+	// Coins supplies network metadata for the allowlist check. Without it every
+	// network is treated as requiring a memo, so tags must always match exactly,
+	// and withdrawals without a network only match entries without one.
+	Coins *CoinCache
+	// Limits holds per-coin limits keyed by coin
+	Limits map[string]CoinLimit
+	// RequireLimits rejects withdrawals of coins that have no entry in Limits
+	RequireLimits bool
+	// CheckQuota rejects withdrawals that exceed the remaining GetWithdrawalQuota headroom.
+	// Without ValueInUSD only an exhausted quota is detected.
+	CheckQuota bool
+	// ValueInUSD converts an amount of coin to USD for the quota check
+	ValueInUSD func(coin, amount string) (string, error)
+	// Approve is called for every withdrawal that passes the other rules;
+	// returning an error rejects the withdrawal
+	Approve func(req models.WithdrawalRequest) error
+}
+
+// recentWithdrawal is a withdrawal submitted through the guard, kept until it
+// is visible in the withdrawal history
+type recentWithdrawal struct {
+	id              string
+	withdrawOrderId string
+	coin            string
+	amount          *big.Rat
+	at              time.Time
+}
+
+// WithdrawalGuard wraps WithdrawalService and enforces a WithdrawalPolicy
+// before each withdrawal is submitted
+type WithdrawalGuard struct {
+	service *WithdrawalService
+	policy  WithdrawalPolicy
+
+	mu        sync.Mutex
+	allowlist []AllowedAddress
+	recent    []recentWithdrawal
+}
+
+// NewWithdrawalGuard creates a guard enforcing policy on withdrawals made through service
+func NewWithdrawalGuard(service *WithdrawalService, policy WithdrawalPolicy) *WithdrawalGuard {
+	return &WithdrawalGuard{
+		service:   service,
+		policy:    policy,
+		allowlist: append([]AllowedAddress(nil), policy.AllowedAddresses...),
+	}
+}
+
+// SyncAllowlist replaces the allowlist with the configured addresses plus every
+// whitelisted address returned by GetWithdrawalAddressList
+func (g *WithdrawalGuard) SyncAllowlist() error {
+	addresses, err := g.service.GetWithdrawalAddressList()
+	if err != nil {
+		return fmt.Errorf("failed to sync withdrawal allowlist: %w", err)
+	}
+
+	allowlist := append([]AllowedAddress(nil), g.policy.AllowedAddresses...)
+	for _, a := range addresses {
+		if !a.WhiteStatus {
+			continue
+		}
+		allowlist = append(allowlist, AllowedAddress{
+			Coin:       a.Coin,
+			Network:    a.Network,
+			Address:    a.Address,
+			AddressTag: a.AddressTag,
+		})
+	}
+
+	g.mu.Lock()
+	g.allowlist = allowlist
+	g.mu.Unlock()
+
+	return nil
+}
+
+// Check evaluates the policy for req without submitting it
+func (g *WithdrawalGuard) Check(req models.WithdrawalRequest) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.check(req)
+}
+
+// Withdraw checks req against the policy and submits it if allowed. Checks and
+// submission are serialized so concurrent withdrawals cannot overrun a limit.
+// Use WithdrawOnce to make retries safe.
+func (g *WithdrawalGuard) Withdraw(req models.WithdrawalRequest) (*models.WithdrawalResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.check(req); err != nil {
+		return nil, err
+	}
+
+	amount, err := utils.ParseDecimal(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.service.Withdraw(req)
+	if err != nil {
+		return nil, err
+	}
+
+	g.recent = append(g.recent, recentWithdrawal{
+		id:              resp.Id,
+		withdrawOrderId: req.WithdrawOrderId,
+		coin:            req.Coin,
+		amount:          amount,
+		at:              time.Now(),
+	})
+
+	return resp, nil
+}
+
+func (g *WithdrawalGuard) check(req models.WithdrawalRequest) error {
+	reject := func(rule PolicyRule, format string, args ...interface{}) error {
+		return &PolicyError{
+			Rule:    rule,
+			Coin:    req.Coin,
+			Address: req.Address,
+			Reason:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	amount, err := parseAmount(req.Amount)
+	if err != nil {
+		return fmt.Errorf("invalid withdrawal amount: %w", err)
+	}
+
+	if g.policy.RequireAllowlist {
+		allowed, err := g.isAllowed(req)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			if req.AddressTag != "" {
+				return reject(PolicyRuleAllowlist, "address %s with tag %s is not allowlisted for %s", req.Address, req.AddressTag, req.Coin)
+			}
+			return reject(PolicyRuleAllowlist, "address %s is not allowlisted for %s", req.Address, req.Coin)
+		}
+	}
+
+	limit, ok := g.policy.Limits[req.Coin]
+	if !ok && g.policy.RequireLimits {
+		return reject(PolicyRuleNoLimits, "no withdrawal limits configured for %s", req.Coin)
+	}
+
+	if limit.Single != "" {
+		single, err := utils.ParseDecimal(limit.Single)
+		if err != nil {
+			return fmt.Errorf("invalid single withdrawal limit of %s: %w", req.Coin, err)
+		}
+		if amount.Cmp(single) > 0 {
+			return reject(PolicyRuleSingleLimit, "amount %s exceeds the single withdrawal limit of %s %s", req.Amount, limit.Single, req.Coin)
+		}
+	}
+
+	if limit.Rolling24h != "" {
+		rolling, err := utils.ParseDecimal(limit.Rolling24h)
+		if err != nil {
+			return fmt.Errorf("invalid 24h withdrawal limit of %s: %w", req.Coin, err)
+		}
+
+		used, err := g.withdrawnSince(req.Coin, time.Now().Add(-24*time.Hour))
+		if err != nil {
+			return err
+		}
+
+		total := new(big.Rat).Add(used, amount)
+		if total.Cmp(rolling) > 0 {
+			return reject(PolicyRuleDailyLimit, "withdrawing %s would bring the 24h total to %s, over the limit of %s %s",
+				req.Amount, utils.FormatDecimal(total, 8), limit.Rolling24h, req.Coin)
+		}
+	}
+
+	if g.policy.CheckQuota {
+		if err := g.checkQuota(req, reject); err != nil {
+			return err
+		}
+	}
+
+	if g.policy.Approve != nil {
+		if err := g.policy.Approve(req); err != nil {
+			return reject(PolicyRuleApproval, "%v", err)
+		}
+	}
+
+	return nil
+}
+
+func (g *WithdrawalGuard) isAllowed(req models.WithdrawalRequest) (bool, error) {
+	network, resolved := req.Network, req.Network != ""
+	for _, a := range g.allowlist {
+		if a.Coin != req.Coin || a.Address != req.Address {
+			continue
+		}
+		if a.Network != "" {
+			// Binance sends a withdrawal without a network on the default one
+			if !resolved {
+				var err error
+				if network, err = g.defaultNetwork(req.Coin); err != nil {
+					return false, err
+				}
+				resolved = true
+			}
+			if a.Network != network {
+				continue
+			}
+		}
+		if a.AddressTag == req.AddressTag {
+			return true, nil
+		}
+		if a.AddressTag != "" {
+			continue
+		}
+
+		// The memo selects the recipient on shared deposit addresses, so an
+		// entry without one cannot vouch for a tagged withdrawal there
+		memo, err := g.memoRequired(req)
+		if err != nil {
+			return false, err
+		}
+		if !memo {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// defaultNetwork returns the default network of coin, or an empty string when
+// it is unknown because no coin metadata is configured
+func (g *WithdrawalGuard) defaultNetwork(coin string) (string, error) {
+	if g.policy.Coins == nil {
+		return "", nil
+	}
+
+	info, ok, err := g.policy.Coins.Coin(coin)
+	if err != nil {
+		return "", fmt.Errorf("failed to load coin configuration: %w", err)
+	}
+	if !ok {
+		return "", nil
+	}
+
+	if network := findNetwork(info, ""); network != nil {
+		return network.Network, nil
+	}
+	return "", nil
+}
+
+// memoRequired reports whether the network of req identifies recipients by
+// memo. Networks are assumed to require one when no coin metadata is configured.
+func (g *WithdrawalGuard) memoRequired(req models.WithdrawalRequest) (bool, error) {
+	if g.policy.Coins == nil {
+		return true, nil
+	}
+
+	coin, ok, err := g.policy.Coins.Coin(req.Coin)
+	if err != nil {
+		return false, fmt.Errorf("failed to load coin configuration: %w", err)
+	}
+	if !ok {
+		return true, nil
+	}
+
+	network := findNetwork(coin, req.Network)
+	return network == nil || network.SameAddress, nil
+}
+
+func (g *WithdrawalGuard) checkQuota(req models.WithdrawalRequest, reject func(PolicyRule, string, ...interface{}) error) error {
+	quota, err := g.service.GetWithdrawalQuota()
+	if err != nil {
+		return err
+	}
+
+	var p utils.DecimalParser
+	remaining := new(big.Rat).Sub(p.Parse(quota.WdQuota), p.Parse(quota.UsedWdQuota))
+	if p.Err != nil {
+		return fmt.Errorf("invalid withdrawal quota: %w", p.Err)
+	}
+	if remaining.Sign() <= 0 {
+		return reject(PolicyRuleQuota, "the 24h withdrawal quota of %s USD is exhausted", quota.WdQuota)
+	}
+
+	if g.policy.ValueInUSD == nil {
+		return nil
+	}
+
+	usd, err := g.policy.ValueInUSD(req.Coin, req.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to value withdrawal in USD: %w", err)
+	}
+
+	value, err := utils.ParseDecimal(usd)
+	if err != nil {
+		return fmt.Errorf("invalid USD value of withdrawal: %w", err)
+	}
+
+	if value.Cmp(remaining) > 0 {
+		return reject(PolicyRuleQuota, "withdrawal worth %s USD exceeds the remaining quota of %s USD", usd, utils.FormatDecimal(remaining, 2))
+	}
+
+	return nil
+}
+
+// withdrawnSince sums withdrawals of coin since the given time that were not
+// cancelled, rejected or failed, including ones submitted through this guard,
+// or with an unknown outcome, that are not yet visible in the history
+func (g *WithdrawalGuard) withdrawnSince(coin string, since time.Time) (*big.Rat, error) {
+	history, err := g.service.GetWithdrawalHistory(models.WithdrawalHistoryRequest{
+		Coin:      coin,
+		StartTime: since,
+		EndTime:   time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Rat)
+	seen := make(map[string]bool, len(history))
+	seenOrderIds := make(map[string]bool)
+	for _, w := range history {
+		seen[w.Id] = true
+		if w.WithdrawOrderId != "" {
+			seenOrderIds[w.WithdrawOrderId] = true
+		}
+		if w.Status.IsTerminal() && !w.Status.IsSuccess() {
+			continue
+		}
+		amount, err := utils.ParseDecimal(w.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount of withdrawal %s: %w", w.Id, err)
+		}
+		total.Add(total, amount)
+	}
+
+	recent := g.recent[:0]
+	for _, r := range g.recent {
+		if r.at.Before(since) {
+			continue
+		}
+		recent = append(recent, r)
+		visible := (r.id != "" && seen[r.id]) || (r.withdrawOrderId != "" && seenOrderIds[r.withdrawOrderId])
+		if r.coin == coin && !visible {
+			total.Add(total, r.amount)
+		}
+	}
+	g.recent = recent
+
+	return total, nil
+}
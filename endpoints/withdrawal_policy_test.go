@@ -0,0 +1,110 @@
+package endpoints_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+)
+
+const (
+	xrpSharedAddress = "rLHzPsX6oXkzU2qL12kHCH8G8cnZv1rBJh"
+	tronAddress      = "TN3W4H6rK2ce4vX9YnFQHwKENnHjoxb3m9"
+	notAllowlisted   = "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59"
+	bscOnlyAddress   = "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY"
+)
+
+func TestWithdrawalGuardAllowlist(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      models.WithdrawalRequest
+		wantRule endpoints.PolicyRule
+		wantErr  bool
+	}{
+		{
+			name: "tag matches the allowlisted tag",
+			req:  models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, AddressTag: "12345", Amount: "10"},
+		},
+		{
+			name:     "different tag on a memo network",
+			req:      models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, AddressTag: "54321", Amount: "10"},
+			wantRule: endpoints.PolicyRuleAllowlist,
+		},
+		{
+			name:     "missing tag for a tagged entry",
+			req:      models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, Amount: "10"},
+			wantRule: endpoints.PolicyRuleAllowlist,
+		},
+		{
+			name:     "untagged entry does not vouch for a tag on a memo network",
+			req:      models.WithdrawalRequest{Coin: "XRP", Address: xrpSharedAddress, AddressTag: "777", Amount: "10"},
+			wantRule: endpoints.PolicyRuleAllowlist,
+		},
+		{
+			name: "untagged entry on a network without memos",
+			req:  models.WithdrawalRequest{Coin: "USDT", Network: "TRX", Address: tronAddress, AddressTag: "ignored", Amount: "10"},
+		},
+		{
+			name: "entry pinned to the requested network",
+			req:  models.WithdrawalRequest{Coin: "XRP", Network: "BSC", Address: bscOnlyAddress, Amount: "10"},
+		},
+		{
+			name:     "no network means the default network, not the pinned one",
+			req:      models.WithdrawalRequest{Coin: "XRP", Address: bscOnlyAddress, Amount: "10"},
+			wantRule: endpoints.PolicyRuleAllowlist,
+		},
+		{
+			name:     "address not whitelisted on Binance",
+			req:      models.WithdrawalRequest{Coin: "XRP", Address: notAllowlisted, AddressTag: "1", Amount: "10"},
+			wantRule: endpoints.PolicyRuleAllowlist,
+		},
+		{
+			name:    "zero amount",
+			req:     models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, AddressTag: "12345", Amount: "0"},
+			wantErr: true,
+		},
+		{
+			name:     "over the single limit",
+			req:      models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, AddressTag: "12345", Amount: "51"},
+			wantRule: endpoints.PolicyRuleSingleLimit,
+		},
+	}
+
+	srv, _ := newValidatorServer(t, "100")
+	c := srv.NewClient()
+	srv.AddWithdrawalAddress(models.WithdrawalAddress{Coin: "XRP", Network: "XRP", Address: xrpAddress, AddressTag: "12345", WhiteStatus: true})
+	srv.AddWithdrawalAddress(models.WithdrawalAddress{Coin: "XRP", Network: "XRP", Address: xrpSharedAddress, WhiteStatus: true})
+	srv.AddWithdrawalAddress(models.WithdrawalAddress{Coin: "USDT", Network: "TRX", Address: tronAddress, WhiteStatus: true})
+	srv.AddWithdrawalAddress(models.WithdrawalAddress{Coin: "XRP", Network: "XRP", Address: notAllowlisted, AddressTag: "1", WhiteStatus: false})
+	srv.AddWithdrawalAddress(models.WithdrawalAddress{Coin: "XRP", Network: "BSC", Address: bscOnlyAddress, WhiteStatus: true})
+
+	guard := endpoints.NewWithdrawalGuard(c.Withdrawal, endpoints.WithdrawalPolicy{
+		RequireAllowlist: true,
+		Coins:            endpoints.NewCoinCache(c.Account, 0),
+		Limits:           map[string]endpoints.CoinLimit{"XRP": {Single: "50"}},
+	})
+	if err := guard.SyncAllowlist(); err != nil {
+		t.Fatalf("SyncAllowlist: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := guard.Check(tt.req)
+
+			var policyErr *endpoints.PolicyError
+			switch {
+			case tt.wantRule != "":
+				if !errors.As(err, &policyErr) || policyErr.Rule != tt.wantRule {
+					t.Fatalf("expected a %s rejection, got %v", tt.wantRule, err)
+				}
+			case tt.wantErr:
+				if err == nil || errors.As(err, &policyErr) {
+					t.Fatalf("expected an invalid request error, got %v", err)
+				}
+			case err != nil:
+				t.Fatalf("expected the withdrawal to be allowed, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// WithdrawLocalEntity submits a withdrawal with the travel rule questionnaire,
+// for accounts onboarded with a local entity that requires travel rule information
+// API endpoint: POST /sapi/v1/localentity/withdraw/apply
+func (s *WithdrawalService) WithdrawLocalEntity(req models.LocalEntityWithdrawalRequest) (*models.LocalEntityWithdrawalResponse, error) {
+	questionnaire, err := json.Marshal(req.Questionnaire)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal questionnaire: %w", err)
+	}
+
+	params := make(map[string]string)
+	params["coin"] = req.Coin
+	params["address"] = req.Address
+	params["amount"] = req.Amount
+	params["questionnaire"] = string(questionnaire)
+
+	if req.WithdrawOrderId != "" {
+		params["withdrawOrderId"] = req.WithdrawOrderId
+	}
+
+	if req.Network != "" {
+		params["network"] = req.Network
+	}
+
+	if req.AddressTag != "" {
+		params["addressTag"] = req.AddressTag
+	}
+
+	if req.TransactionFeeFlag {
+		params["transactionFeeFlag"] = "true"
+	}
+
+	if req.Name != "" {
+		params["name"] = req.Name
+	}
+
+	if req.WalletType != 0 {
+		params["walletType"] = strconv.Itoa(int(req.WalletType))
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Post("/sapi/v1/localentity/withdraw/apply", params, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit local entity withdrawal: %w", err)
+	}
+
+	var result models.LocalEntityWithdrawalResponse
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetLocalEntityWithdrawalHistory retrieves withdrawals with their travel rule status
+// API endpoint: GET /sapi/v1/localentity/withdraw/history
+func (s *WithdrawalService) GetLocalEntityWithdrawalHistory(req models.LocalEntityWithdrawalHistoryRequest) ([]models.LocalEntityWithdrawal, error) {
+	params := make(map[string]string)
+
+	if req.TrId != "" {
+		params["trId"] = req.TrId
+	}
+
+	if req.TxId != "" {
+		params["txId"] = req.TxId
+	}
+
+	if req.WithdrawOrderId != "" {
+		params["withdrawOrderId"] = req.WithdrawOrderId
+	}
+
+	if req.Network != "" {
+		params["network"] = req.Network
+	}
+
+	if req.Coin != "" {
+		params["coin"] = req.Coin
+	}
+
+	if req.TravelRuleStatus != nil {
+		params["travelRuleStatus"] = strconv.Itoa(int(*req.TravelRuleStatus))
+	}
+
+	if !req.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(req.StartTime.UnixMilli(), 10)
+	}
+
+	if !req.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(req.EndTime.UnixMilli(), 10)
+	}
+
+	if req.Offset > 0 {
+		params["offset"] = strconv.Itoa(req.Offset)
+	}
+
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	if req.RecvWindow > 0 {
+		params["recvWindow"] = strconv.FormatInt(req.RecvWindow, 10)
+	}
+
+	resp, err := s.client.Get("/sapi/v1/localentity/withdraw/history", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local entity withdrawal history: %w", err)
+	}
+
+	var history []models.LocalEntityWithdrawal
+	if err := json.Unmarshal(resp, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,215 @@
+package endpoints
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// FieldError describes a problem with a single request field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// WithdrawalValidationError is returned when a withdrawal request fails pre-flight validation
+type WithdrawalValidationError struct {
+	Errors []FieldError
+}
+
+func (e *WithdrawalValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "invalid withdrawal request: " + strings.Join(msgs, "; ")
+}
+
+// HasField reports whether validation failed for the given field
+func (e *WithdrawalValidationError) HasField(field string) bool {
+	for _, fe := range e.Errors {
+		if fe.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// WithdrawalValidator checks withdrawal requests against the coin and network
+// configuration returned by AccountService.GetAllCoins
+type WithdrawalValidator struct {
+	coins *CoinCache
+}
+
+// NewWithdrawalValidator creates a validator that caches coin configuration for cacheTTL.
+// A zero cacheTTL uses DefaultCoinCacheTTL.
+func NewWithdrawalValidator(account *AccountService, cacheTTL time.Duration) *WithdrawalValidator {
+	return NewWithdrawalValidatorWithCache(NewCoinCache(account, cacheTTL))
+}
+
+// NewWithdrawalValidatorWithCache creates a validator that shares an existing coin cache
+func NewWithdrawalValidatorWithCache(coins *CoinCache) *WithdrawalValidator {
+	return &WithdrawalValidator{coins: coins}
+}
+
+// Invalidate drops the cached coin configuration so the next validation refetches it
+func (v *WithdrawalValidator) Invalidate() {
+	v.coins.Invalidate()
+}
+
+// Validate checks a withdrawal request against the coin's network configuration.
+// It returns a *WithdrawalValidationError listing every failing field, or an
+// error if the coin configuration could not be fetched.
+func (v *WithdrawalValidator) Validate(req models.WithdrawalRequest) error {
+	var errs []FieldError
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	result := func() error {
+		if len(errs) == 0 {
+			return nil
+		}
+		return &WithdrawalValidationError{Errors: errs}
+	}
+
+	if req.Coin == "" {
+		fail("coin", "is required")
+		return result()
+	}
+
+	coin, ok, err := v.coins.Coin(req.Coin)
+	if err != nil {
+		return fmt.Errorf("failed to load coin configuration: %w", err)
+	}
+	if !ok {
+		fail("coin", "unknown coin %s", req.Coin)
+		return result()
+	}
+
+	if !coin.WithdrawAllEnable {
+		fail("coin", "withdrawals of %s are disabled", req.Coin)
+	}
+
+	network := findNetwork(coin, req.Network)
+	if network == nil {
+		if req.Network == "" {
+			fail("network", "no default network for %s", req.Coin)
+		} else {
+			fail("network", "network %s is not supported for %s", req.Network, req.Coin)
+		}
+		return result()
+	}
+
+	if !network.WithdrawEnable {
+		fail("network", "withdrawals on %s are disabled", network.Network)
+	}
+	if network.Busy {
+		fail("network", "network %s is busy", network.Network)
+	}
+
+	if req.Address == "" {
+		fail("address", "is required")
+	} else if ok, err := matchesRegex(network.AddressRegex, req.Address); err != nil {
+		fail("address", "cannot be checked: the %s address format is invalid: %v", network.Network, err)
+	} else if !ok {
+		fail("address", "does not match the %s address format", network.Network)
+	}
+
+	if req.AddressTag != "" {
+		if ok, err := matchesRegex(network.MemoRegex, req.AddressTag); err != nil {
+			fail("addressTag", "cannot be checked: the %s memo format is invalid: %v", network.Network, err)
+		} else if !ok {
+			fail("addressTag", "does not match the %s memo format", network.Network)
+		}
+	} else if network.SameAddress {
+		fail("addressTag", "a memo is required for %s on %s", req.Coin, network.Network)
+	}
+
+	amount, err := parseAmount(req.Amount)
+	if err != nil {
+		fail("amount", "%v", err)
+		return result()
+	}
+
+	var p utils.DecimalParser
+	minAmount, maxAmount := p.Parse(network.WithdrawMin), p.Parse(network.WithdrawMax)
+	multiple, fee, free := p.Parse(network.WithdrawIntegerMultiple), p.Parse(network.WithdrawFee), p.Parse(coin.Free)
+	if p.Err != nil {
+		return fmt.Errorf("invalid %s configuration of %s: %w", network.Network, req.Coin, p.Err)
+	}
+
+	if amount.Cmp(minAmount) < 0 {
+		fail("amount", "is below the minimum of %s", network.WithdrawMin)
+	}
+
+	if maxAmount.Sign() > 0 && amount.Cmp(maxAmount) > 0 {
+		fail("amount", "exceeds the maximum of %s", network.WithdrawMax)
+	}
+
+	if multiple.Sign() > 0 {
+		if !new(big.Rat).Quo(amount, multiple).IsInt() {
+			fail("amount", "must be a multiple of %s", network.WithdrawIntegerMultiple)
+		}
+	}
+
+	// The network fee is charged on top of the amount, so the spot balance must cover both.
+	// The balance comes from the cached coin list and may lag slightly; funding
+	// wallet balances are not part of GetAllCoins and cannot be checked here.
+	if req.WalletType == 0 {
+		total := new(big.Rat).Add(amount, fee)
+		if total.Cmp(free) > 0 {
+			fail("amount", "amount plus fee %s exceeds the available balance of %s", utils.FormatDecimal(total, 8), coin.Free)
+		}
+	}
+
+	return result()
+}
+
+// findNetwork returns the requested network of a coin, or its default network when name is empty
+func findNetwork(coin *models.CoinInfo, name string) *models.NetworkInfo {
+	for i := range coin.NetworkList {
+		n := &coin.NetworkList[i]
+		if (name == "" && n.IsDefault) || (name != "" && n.Network == name) {
+			return n
+		}
+	}
+	return nil
+}
+
+// matchesRegex reports whether s matches pattern. An empty pattern is not
+// enforced; one that does not compile is an error, so the check fails closed.
+func matchesRegex(pattern, s string) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
+
+// plainDecimalPattern matches the amount formats Binance accepts: digits with
+// an optional fraction, without sign, exponent or fraction forms like 1/3
+var plainDecimalPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// parseAmount parses a withdrawal amount, which must be a positive plain decimal
+func parseAmount(s string) (*big.Rat, error) {
+	if !plainDecimalPattern.MatchString(s) {
+		return nil, fmt.Errorf("must be a positive decimal, got %q", s)
+	}
+	amount, err := utils.ParseDecimal(s)
+	if err != nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("must be a positive decimal, got %q", s)
+	}
+	return amount, nil
+}
@@ -0,0 +1,111 @@
+package endpoints_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+)
+
+const xrpAddress = "rEb8TK3gBgk5auZkwc6sHnwrGVJH8DuaLh"
+
+// newValidatorServer returns a fake server with XRP on a memo network and the
+// given spot balance, and a validator reading its coin configuration
+func newValidatorServer(t *testing.T, free string) (*binancetest.Server, *endpoints.WithdrawalValidator) {
+	t.Helper()
+	srv := binancetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddCoin(models.CoinInfo{
+		Coin:              "XRP",
+		DepositAllEnable:  true,
+		WithdrawAllEnable: true,
+		NetworkList: []models.NetworkInfo{
+			{
+				Coin:                    "XRP",
+				Network:                 "XRP",
+				IsDefault:               true,
+				WithdrawEnable:          true,
+				SameAddress:             true,
+				AddressRegex:            `^r[1-9A-HJ-NP-Za-km-z]{25,34}$`,
+				MemoRegex:               `^[0-9A-Za-z\-_,]{1,120}$`,
+				WithdrawFee:             "0.25",
+				WithdrawMin:             "2",
+				WithdrawMax:             "1000",
+				WithdrawIntegerMultiple: "0.000001",
+			},
+			{Coin: "XRP", Network: "BSC", WithdrawEnable: false, WithdrawMin: "1", WithdrawFee: "0.1"},
+			{Coin: "XRP", Network: "BROKEN", WithdrawEnable: true, AddressRegex: `^r[`, WithdrawMin: "1", WithdrawFee: "0.1"},
+		},
+	})
+	srv.SetBalance("XRP", free)
+	return srv, endpoints.NewWithdrawalValidator(srv.NewClient().Account, 0)
+}
+
+func TestWithdrawalValidator(t *testing.T) {
+	valid := models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, AddressTag: "12345", Amount: "10"}
+
+	tests := []struct {
+		name       string
+		modify     func(r *models.WithdrawalRequest)
+		wantFields []string
+	}{
+		{name: "valid", modify: func(r *models.WithdrawalRequest) {}},
+		{name: "unknown coin", modify: func(r *models.WithdrawalRequest) { r.Coin = "NOPE" }, wantFields: []string{"coin"}},
+		{name: "unsupported network", modify: func(r *models.WithdrawalRequest) { r.Network = "SOL" }, wantFields: []string{"network"}},
+		{name: "disabled network", modify: func(r *models.WithdrawalRequest) { r.Network = "BSC"; r.AddressTag = "" }, wantFields: []string{"network"}},
+		{name: "address format", modify: func(r *models.WithdrawalRequest) { r.Address = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed" }, wantFields: []string{"address"}},
+		{name: "invalid address regex fails closed", modify: func(r *models.WithdrawalRequest) { r.Network = "BROKEN"; r.AddressTag = "" }, wantFields: []string{"address"}},
+		{name: "missing memo", modify: func(r *models.WithdrawalRequest) { r.AddressTag = "" }, wantFields: []string{"addressTag"}},
+		{name: "memo format", modify: func(r *models.WithdrawalRequest) { r.AddressTag = "memo with spaces" }, wantFields: []string{"addressTag"}},
+		{name: "below minimum", modify: func(r *models.WithdrawalRequest) { r.Amount = "1" }, wantFields: []string{"amount"}},
+		{name: "above maximum", modify: func(r *models.WithdrawalRequest) { r.Amount = "1001" }, wantFields: []string{"amount"}},
+		{name: "not a multiple", modify: func(r *models.WithdrawalRequest) { r.Amount = "10.0000001" }, wantFields: []string{"amount"}},
+		{name: "fee not covered", modify: func(r *models.WithdrawalRequest) { r.Amount = "99.8" }, wantFields: []string{"amount"}},
+		{name: "exponent", modify: func(r *models.WithdrawalRequest) { r.Amount = "1e1" }, wantFields: []string{"amount"}},
+		{name: "negative", modify: func(r *models.WithdrawalRequest) { r.Amount = "-10" }, wantFields: []string{"amount"}},
+		{name: "fraction", modify: func(r *models.WithdrawalRequest) { r.Amount = "20/2" }, wantFields: []string{"amount"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, v := newValidatorServer(t, "100")
+			req := valid
+			tt.modify(&req)
+
+			err := v.Validate(req)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var verr *endpoints.WithdrawalValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			for _, field := range tt.wantFields {
+				if !verr.HasField(field) {
+					t.Errorf("expected an error for %s, got %v", field, verr)
+				}
+			}
+		})
+	}
+}
+
+func TestWithdrawalValidatorAcceptsWhatTheServerAccepts(t *testing.T) {
+	srv, v := newValidatorServer(t, "100")
+	req := models.WithdrawalRequest{Coin: "XRP", Address: xrpAddress, AddressTag: "12345", Amount: "99.75"}
+
+	if err := v.Validate(req); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if _, err := srv.NewClient().Withdrawal.Withdraw(req); err != nil {
+		t.Fatalf("Withdraw: %v", err)
+	}
+	if free := srv.Balance("XRP").Free; free != "0.00000000" {
+		t.Errorf("expected the amount and fee to use the whole balance, %s is left", free)
+	}
+}
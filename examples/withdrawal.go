@@ -198,21 +198,21 @@ func main() {
 
 		if err == nil && len(history) > 0 {
 			w := history[0]
-			status := getStatusText(w.Status)
+			status := w.Status.String()
 			fmt.Printf("[%s] %s", time.Now().Format("15:04:05"), status)
 
 			if w.TxId != "" {
 				fmt.Printf(" - TxID: %s", w.TxId)
-			} else if w.Status == 6 {
+			} else if w.Status == models.WithdrawalStatusCompleted {
 				fmt.Print(" - Internal transfer")
 			}
 			fmt.Println()
 
 			// 检查是否完成或失败
-			if w.Status == 6 {
+			if w.Status.IsSuccess() {
 				fmt.Println("\n✅ Withdrawal completed!")
 				break
-			} else if w.Status == 1 || w.Status == 3 || w.Status == 5 {
+			} else if w.Status.IsTerminal() {
 				fmt.Printf("\n❌ Withdrawal failed: %s\n", status)
 				break
 			}
@@ -221,24 +221,3 @@ func main() {
 
 	fmt.Println("\n✅ Done!")
 }
-
-func getStatusText(status int) string {
-	switch status {
-	case 0:
-		return "Email Sent"
-	case 1:
-		return "Cancelled"
-	case 2:
-		return "Awaiting Approval"
-	case 3:
-		return "Rejected"
-	case 4:
-		return "Processing"
-	case 5:
-		return "Failed"
-	case 6:
-		return "Completed"
-	default:
-		return fmt.Sprintf("Unknown(%d)", status)
-	}
-}
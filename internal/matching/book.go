@@ -0,0 +1,409 @@
+package matching
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/MartianPay/go-binance/models"
+)
+
+// Book holds the orders and trades of one account and matches them against
+// last prices. Every order fills completely at a single price. A Book is not
+// safe for concurrent use; callers serialize access with their own lock.
+type Book struct {
+	// Symbol returns the trading rules of a symbol
+	Symbol func(symbol string) (models.SymbolInfo, bool)
+	// Balance returns the free and locked balance of an asset. The book
+	// updates the returned values in place.
+	Balance func(asset string) (free, locked *big.Rat)
+	// Fee returns the commission rate of a maker or taker fill
+	Fee func(maker bool) *big.Rat
+	// Now returns the current time in milliseconds
+	Now func() int64
+	// NewId returns a new order or trade ID
+	NewId func() int64
+	// IdPrefix starts the client order IDs generated for orders without one
+	IdPrefix string
+
+	Orders []*Order
+	Trades []models.Trade
+}
+
+// Parse validates a new order the way Binance does, including the symbol's
+// filters, without checking balances
+func (b *Book) Parse(req models.NewOrderRequest) (*Order, error) {
+	info, ok := b.Symbol(req.Symbol)
+	if !ok {
+		return nil, ErrInvalidSymbol
+	}
+
+	o := &Order{
+		Order: models.Order{
+			Symbol:        info.Symbol,
+			OrderListId:   -1,
+			ClientOrderId: req.NewClientOrderId,
+			Side:          req.Side,
+			Type:          req.Type,
+			TimeInForce:   req.TimeInForce,
+			IcebergQty:    Format(new(big.Rat)),
+		},
+		price:     new(big.Rat),
+		stopPrice: new(big.Rat),
+		qty:       new(big.Rat),
+		quoteQty:  new(big.Rat),
+		executed:  new(big.Rat),
+		cumQuote:  new(big.Rat),
+		reserved:  new(big.Rat),
+	}
+
+	switch o.Side {
+	case models.SideBuy, models.SideSell:
+	case "":
+		return nil, ErrMandatory("side")
+	default:
+		return nil, newError(-1117, "Invalid side.")
+	}
+
+	var needPrice, needStop, needTimeInForce bool
+	switch o.Type {
+	case models.OrderTypeMarket:
+	case models.OrderTypeLimit:
+		needPrice, needTimeInForce = true, true
+	case models.OrderTypeLimitMaker:
+		needPrice = true
+	case models.OrderTypeStopLoss, models.OrderTypeTakeProfit:
+		needStop = true
+	case models.OrderTypeStopLossLimit, models.OrderTypeTakeProfitLimit:
+		needPrice, needStop, needTimeInForce = true, true, true
+	case "":
+		return nil, ErrMandatory("type")
+	default:
+		return nil, newError(-1116, "Invalid orderType.")
+	}
+
+	if needTimeInForce {
+		switch o.TimeInForce {
+		case models.TimeInForceGTC, models.TimeInForceIOC, models.TimeInForceFOK:
+		case "":
+			return nil, ErrMandatory("timeInForce")
+		default:
+			return nil, newError(-1115, "Invalid timeInForce.")
+		}
+	} else if o.TimeInForce != "" {
+		return nil, ErrNotRequired("timeInForce")
+	}
+
+	var err error
+	if o.Type == models.OrderTypeMarket && req.QuoteOrderQty != "" {
+		if req.Quantity != "" {
+			return nil, ErrNotRequired("quoteOrderQty")
+		}
+		if o.quoteQty, err = positive(req.QuoteOrderQty, "quoteOrderQty"); err != nil {
+			return nil, err
+		}
+	} else if o.qty, err = positive(req.Quantity, "quantity"); err != nil {
+		return nil, err
+	}
+
+	if needPrice {
+		if o.price, err = positive(req.Price, "price"); err != nil {
+			return nil, err
+		}
+	} else if req.Price != "" {
+		return nil, ErrNotRequired("price")
+	}
+
+	if needStop {
+		if o.stopPrice, err = positive(req.StopPrice, "stopPrice"); err != nil {
+			return nil, err
+		}
+	} else if req.StopPrice != "" {
+		return nil, ErrNotRequired("stopPrice")
+	}
+
+	if req.IcebergQty != "" {
+		return nil, newError(-1013, "Iceberg orders are not supported.")
+	}
+
+	switch req.NewOrderRespType {
+	case "", models.OrderResponseTypeACK, models.OrderResponseTypeRESULT, models.OrderResponseTypeFULL:
+	default:
+		return nil, ErrIllegal("newOrderRespType")
+	}
+
+	if o.ClientOrderId != "" {
+		for _, existing := range b.Orders {
+			if existing.ClientOrderId == o.ClientOrderId && !existing.Status.IsTerminal() {
+				return nil, newError(-2010, "Duplicate order sent.")
+			}
+		}
+	}
+
+	if err := checkFilters(info, o, nil); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// Place reserves the balance an order needs, records it and fills it if it
+// is marketable at market.
+//
+// Buys reserve the quote amount at the limit price, the market price or, for
+// STOP_LOSS and TAKE_PROFIT orders, the stop price. A stop market buy that
+// triggers above its stop price must be covered by the free balance when it
+// fills; if it is not, the order is REJECTED.
+func (b *Book) Place(o *Order, market *big.Rat) ([]models.OrderFill, error) {
+	info, _ := b.Symbol(o.Symbol)
+
+	if o.Type == models.OrderTypeLimitMaker && o.crosses(market) {
+		return nil, newError(-2010, "Order would immediately match and take.")
+	}
+	if o.isStop() && o.triggers(market) {
+		return nil, newError(-2010, "Order would trigger immediately.")
+	}
+
+	if o.quoteQty.Sign() > 0 {
+		o.qty = floorStep(new(big.Rat).Quo(o.quoteQty, market), info)
+		if err := checkFilters(info, o, nil); err != nil {
+			return nil, err
+		}
+	}
+	if o.isMarket() {
+		reference := market
+		if o.isStop() {
+			reference = o.stopPrice
+		}
+		if err := checkFilters(info, o, reference); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.isBuy() {
+		reference := o.price
+		switch {
+		case o.quoteQty.Sign() > 0:
+			reference = nil
+			o.reserved.Set(o.quoteQty)
+		case o.Type == models.OrderTypeMarket:
+			reference = market
+		case o.isMarket():
+			reference = o.stopPrice
+		}
+		if reference != nil {
+			o.reserved.Mul(o.qty, reference)
+		}
+		o.reserveAsset = info.QuoteAsset
+	} else {
+		o.reserved.Set(o.qty)
+		o.reserveAsset = info.BaseAsset
+	}
+
+	free, locked := b.Balance(o.reserveAsset)
+	if free.Cmp(o.reserved) < 0 {
+		return nil, ErrInsufficientBalance
+	}
+	free.Sub(free, o.reserved)
+	locked.Add(locked, o.reserved)
+
+	now := b.Now()
+	o.OrderId = b.NewId()
+	if o.ClientOrderId == "" {
+		o.ClientOrderId = fmt.Sprintf("%s%d", b.IdPrefix, o.OrderId)
+	}
+	o.Status = models.OrderStatusNew
+	o.Time = now
+	o.UpdateTime = now
+	if !o.isStop() {
+		o.IsWorking = true
+		o.WorkingTime = now
+	}
+	o.Sync()
+	b.Orders = append(b.Orders, o)
+
+	if o.isStop() {
+		return []models.OrderFill{}, nil
+	}
+	return b.execute(o, market, false), nil
+}
+
+// OnPrice triggers stop orders of symbol and fills the resting orders that
+// price reaches
+func (b *Book) OnPrice(symbol string, price *big.Rat) {
+	now := b.Now()
+
+	for _, o := range b.Orders {
+		if o.Symbol != symbol || o.Status.IsTerminal() {
+			continue
+		}
+
+		if !o.IsWorking {
+			if !o.triggers(price) {
+				continue
+			}
+			o.IsWorking = true
+			o.WorkingTime = now
+			o.UpdateTime = now
+			b.execute(o, price, false)
+			continue
+		}
+
+		b.execute(o, price, true)
+	}
+}
+
+// Find looks an order up by orderId or, when orderId is 0, by client order
+// ID. It returns nil without an error when no order matches.
+func (b *Book) Find(symbol string, orderId int64, clientOrderId string) (*Order, error) {
+	if _, ok := b.Symbol(symbol); !ok {
+		return nil, ErrInvalidSymbol
+	}
+	if orderId == 0 && clientOrderId == "" {
+		return nil, newError(-1102, "Param 'origClientOrderId' or 'orderId' must be sent, but both were empty/null!")
+	}
+
+	// Client order IDs can be reused once an order is closed, so the latest match wins
+	for i := len(b.Orders) - 1; i >= 0; i-- {
+		o := b.Orders[i]
+		if o.Symbol != symbol {
+			continue
+		}
+		if (orderId != 0 && o.OrderId == orderId) || (orderId == 0 && o.ClientOrderId == clientOrderId) {
+			return o, nil
+		}
+	}
+	return nil, nil
+}
+
+// Cancel cancels an open order and releases its reserved balance. An empty
+// newClientOrderId is generated.
+func (b *Book) Cancel(o *Order, newClientOrderId string) models.CancelOrderResponse {
+	b.release(o)
+	o.Status = models.OrderStatusCanceled
+	o.UpdateTime = b.Now()
+	o.Sync()
+
+	if newClientOrderId == "" {
+		newClientOrderId = fmt.Sprintf("%s-cancel%d", b.IdPrefix, b.NewId())
+	}
+
+	return models.CancelOrderResponse{
+		Symbol:              o.Symbol,
+		OrigClientOrderId:   o.ClientOrderId,
+		OrderId:             o.OrderId,
+		OrderListId:         o.OrderListId,
+		ClientOrderId:       newClientOrderId,
+		TransactTime:        o.UpdateTime,
+		Price:               o.Price,
+		OrigQty:             o.OrigQty,
+		ExecutedQty:         o.ExecutedQty,
+		CummulativeQuoteQty: o.CummulativeQuoteQty,
+		Status:              o.Status,
+		TimeInForce:         o.TimeInForce,
+		Type:                o.Type,
+		Side:                o.Side,
+	}
+}
+
+// execute fills a working order that is marketable at price, or expires an
+// IOC or FOK order that is not
+func (b *Book) execute(o *Order, price *big.Rat, maker bool) []models.OrderFill {
+	if !o.isMarket() && !o.crosses(price) {
+		if o.TimeInForce == models.TimeInForceIOC || o.TimeInForce == models.TimeInForceFOK {
+			b.close(o, models.OrderStatusExpired)
+		}
+		return []models.OrderFill{}
+	}
+
+	// Resting limit orders fill at their own price, taker orders at the market
+	if maker {
+		price = o.price
+	}
+	fill, ok := b.fill(o, price, maker)
+	if !ok {
+		// Only a stop market buy filling above its stop price can outgrow its
+		// reservation; the engine refuses it rather than filling on credit
+		b.close(o, models.OrderStatusRejected)
+		return []models.OrderFill{}
+	}
+	return []models.OrderFill{fill}
+}
+
+// fill executes the whole order at price, charging commission on the asset
+// received. It reports false if the free balance, including the order's
+// reservation, does not cover the order.
+func (b *Book) fill(o *Order, price *big.Rat, maker bool) (models.OrderFill, bool) {
+	info, _ := b.Symbol(o.Symbol)
+	b.release(o)
+
+	baseFree, _ := b.Balance(info.BaseAsset)
+	quoteFree, _ := b.Balance(info.QuoteAsset)
+	quoteQty := new(big.Rat).Mul(o.qty, price)
+	rate := b.Fee(maker)
+
+	var commission *big.Rat
+	var commissionAsset string
+	if o.isBuy() {
+		if quoteFree.Cmp(quoteQty) < 0 {
+			return models.OrderFill{}, false
+		}
+		commission, commissionAsset = new(big.Rat).Mul(o.qty, rate), info.BaseAsset
+		quoteFree.Sub(quoteFree, quoteQty)
+		baseFree.Add(baseFree, new(big.Rat).Sub(o.qty, commission))
+	} else {
+		if baseFree.Cmp(o.qty) < 0 {
+			return models.OrderFill{}, false
+		}
+		commission, commissionAsset = new(big.Rat).Mul(quoteQty, rate), info.QuoteAsset
+		baseFree.Sub(baseFree, o.qty)
+		quoteFree.Add(quoteFree, new(big.Rat).Sub(quoteQty, commission))
+	}
+
+	now := b.Now()
+	o.executed.Set(o.qty)
+	o.cumQuote.Set(quoteQty)
+	o.Status = models.OrderStatusFilled
+	o.UpdateTime = now
+	o.Sync()
+
+	trade := models.Trade{
+		Symbol:          o.Symbol,
+		Id:              b.NewId(),
+		OrderId:         o.OrderId,
+		OrderListId:     o.OrderListId,
+		Price:           Format(price),
+		Qty:             Format(o.qty),
+		QuoteQty:        Format(quoteQty),
+		Commission:      Format(commission),
+		CommissionAsset: commissionAsset,
+		Time:            now,
+		IsBuyer:         o.isBuy(),
+		IsMaker:         maker,
+		IsBestMatch:     true,
+	}
+	b.Trades = append(b.Trades, trade)
+
+	return models.OrderFill{
+		Price:           trade.Price,
+		Qty:             trade.Qty,
+		Commission:      trade.Commission,
+		CommissionAsset: trade.CommissionAsset,
+		TradeId:         trade.Id,
+	}, true
+}
+
+// release returns the balance reserved by an order to the free balance
+func (b *Book) release(o *Order) {
+	free, locked := b.Balance(o.reserveAsset)
+	locked.Sub(locked, o.reserved)
+	free.Add(free, o.reserved)
+	o.reserved.SetInt64(0)
+}
+
+// close ends an open order with status, releasing its reservation
+func (b *Book) close(o *Order, status models.OrderStatus) {
+	b.release(o)
+	o.Status = status
+	o.UpdateTime = b.Now()
+	o.Sync()
+}
@@ -0,0 +1,94 @@
+package matching
+
+import (
+	"math/big"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// checkFilters applies the LOT_SIZE, PRICE_FILTER and NOTIONAL or
+// MIN_NOTIONAL filters of a symbol to an order. The notional of orders
+// without a limit price is checked at market, and skipped when market is nil.
+func checkFilters(info models.SymbolInfo, o *Order, market *big.Rat) error {
+	for _, f := range info.Filters {
+		switch models.FilterType(filterString(f, "filterType")) {
+		case models.FilterTypeLotSize:
+			if o.qty.Sign() > 0 && !inStep(o.qty, filterValue(f, "minQty"), filterValue(f, "maxQty"), filterValue(f, "stepSize")) {
+				return newError(-1013, "Filter failure: LOT_SIZE")
+			}
+		case models.FilterTypePriceFilter:
+			for _, price := range []*big.Rat{o.price, o.stopPrice} {
+				if price.Sign() > 0 && !inStep(price, filterValue(f, "minPrice"), filterValue(f, "maxPrice"), filterValue(f, "tickSize")) {
+					return newError(-1013, "Filter failure: PRICE_FILTER")
+				}
+			}
+		case models.FilterTypeNotional, models.FilterTypeMinNotional:
+			price := o.price
+			if o.isMarket() {
+				price = market
+			}
+			if price == nil || o.qty.Sign() == 0 {
+				continue
+			}
+			notional := new(big.Rat).Mul(o.qty, price)
+			if !inStep(notional, filterValue(f, "minNotional"), filterValue(f, "maxNotional"), nil) {
+				return newError(-1013, "Filter failure: "+filterString(f, "filterType"))
+			}
+		}
+	}
+	return nil
+}
+
+// inStep reports whether v is within [min, max] and a whole number of steps
+// above min. Zero or missing bounds and steps are not enforced.
+func inStep(v, min, max, step *big.Rat) bool {
+	if min != nil && v.Cmp(min) < 0 {
+		return false
+	}
+	if max != nil && max.Sign() > 0 && v.Cmp(max) > 0 {
+		return false
+	}
+	if step != nil && step.Sign() > 0 {
+		steps := new(big.Rat).Sub(v, min)
+		if min == nil {
+			steps.Set(v)
+		}
+		if !steps.Quo(steps, step).IsInt() {
+			return false
+		}
+	}
+	return true
+}
+
+// floorStep rounds a quantity computed from quoteOrderQty down to the
+// LOT_SIZE step of the symbol, or to 8 decimal places without one
+func floorStep(qty *big.Rat, info models.SymbolInfo) *big.Rat {
+	step := big.NewRat(1, 100000000)
+	for _, f := range info.Filters {
+		if models.FilterType(filterString(f, "filterType")) == models.FilterTypeLotSize {
+			if s := filterValue(f, "stepSize"); s != nil && s.Sign() > 0 {
+				step = s
+			}
+		}
+	}
+
+	steps := new(big.Rat).Quo(qty, step)
+	n := new(big.Int).Quo(steps.Num(), steps.Denom())
+	return new(big.Rat).Mul(new(big.Rat).SetInt(n), step)
+}
+
+func filterString(f map[string]interface{}, key string) string {
+	s, _ := f[key].(string)
+	return s
+}
+
+// filterValue parses a decimal filter value; missing values are zero and
+// invalid ones nil
+func filterValue(f map[string]interface{}, key string) *big.Rat {
+	r, err := utils.ParseDecimal(filterString(f, key))
+	if err != nil {
+		return nil
+	}
+	return r
+}
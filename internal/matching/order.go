@@ -0,0 +1,179 @@
+// Package matching validates and matches spot orders against a last price the
+// way Binance does. It is shared by the binancetest fake server and the paper
+// trading engine.
+package matching
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// Error is a Binance error code and message for a rejected request
+type Error struct {
+	Code int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Msg, e.Code)
+}
+
+func newError(code int, msg string) *Error {
+	return &Error{Code: code, Msg: msg}
+}
+
+var (
+	ErrInvalidSymbol       = newError(-1121, "Invalid symbol.")
+	ErrUnknownOrder        = newError(-2011, "Unknown order sent.")
+	ErrOrderNotExist       = newError(-2013, "Order does not exist.")
+	ErrInsufficientBalance = newError(-2010, "Account has insufficient balance for requested action.")
+)
+
+// ErrMandatory is returned when a required parameter is missing
+func ErrMandatory(name string) *Error {
+	return newError(-1102, fmt.Sprintf("Mandatory parameter '%s' was not sent, was empty/null, or malformed.", name))
+}
+
+// ErrIllegal is returned when a parameter cannot be parsed
+func ErrIllegal(name string) *Error {
+	return newError(-1100, fmt.Sprintf("Illegal characters found in parameter '%s'.", name))
+}
+
+// ErrNotRequired is returned when a parameter is sent that the order type does not take
+func ErrNotRequired(name string) *Error {
+	return newError(-1106, fmt.Sprintf("Parameter '%s' sent when not required.", name))
+}
+
+// Order is an order and the exact amounts behind its string fields
+type Order struct {
+	models.Order
+
+	price     *big.Rat
+	stopPrice *big.Rat
+	qty       *big.Rat
+	quoteQty  *big.Rat // quoteOrderQty of MARKET orders
+	executed  *big.Rat
+	cumQuote  *big.Rat
+
+	// reserved is the amount of reserveAsset locked while the order is open
+	reserveAsset string
+	reserved     *big.Rat
+}
+
+func (o *Order) isBuy() bool {
+	return o.Side == models.SideBuy
+}
+
+func (o *Order) isStop() bool {
+	switch o.Type {
+	case models.OrderTypeStopLoss, models.OrderTypeStopLossLimit, models.OrderTypeTakeProfit, models.OrderTypeTakeProfitLimit:
+		return true
+	}
+	return false
+}
+
+// isMarket reports whether the order fills at the market price once working
+func (o *Order) isMarket() bool {
+	switch o.Type {
+	case models.OrderTypeMarket, models.OrderTypeStopLoss, models.OrderTypeTakeProfit:
+		return true
+	}
+	return false
+}
+
+// crosses reports whether a limit order is marketable at price
+func (o *Order) crosses(price *big.Rat) bool {
+	if o.isBuy() {
+		return price.Cmp(o.price) <= 0
+	}
+	return price.Cmp(o.price) >= 0
+}
+
+// triggers reports whether price reaches the stop price of a stop order
+func (o *Order) triggers(price *big.Rat) bool {
+	c := price.Cmp(o.stopPrice)
+	switch o.Type {
+	case models.OrderTypeStopLoss, models.OrderTypeStopLossLimit:
+		if o.isBuy() {
+			return c >= 0
+		}
+		return c <= 0
+	default:
+		if o.isBuy() {
+			return c <= 0
+		}
+		return c >= 0
+	}
+}
+
+// Sync updates the string fields of the order from its amounts
+func (o *Order) Sync() {
+	o.Price = Format(o.price)
+	o.StopPrice = Format(o.stopPrice)
+	o.OrigQty = Format(o.qty)
+	o.OrigQuoteOrderQty = Format(o.quoteQty)
+	o.ExecutedQty = Format(o.executed)
+	o.CummulativeQuoteQty = Format(o.cumQuote)
+}
+
+// Response builds the placement response of the given type. ACK responses
+// carry only the identifiers and the transaction time.
+func (o *Order) Response(respType models.OrderResponseType, fills []models.OrderFill) *models.OrderResponse {
+	o.Sync()
+
+	resp := &models.OrderResponse{
+		Symbol:        o.Symbol,
+		OrderId:       o.OrderId,
+		OrderListId:   o.OrderListId,
+		ClientOrderId: o.ClientOrderId,
+		TransactTime:  o.UpdateTime,
+	}
+	if respType == models.OrderResponseTypeACK {
+		return resp
+	}
+
+	resp.Price = o.Price
+	resp.OrigQty = o.OrigQty
+	resp.ExecutedQty = o.ExecutedQty
+	resp.CummulativeQuoteQty = o.CummulativeQuoteQty
+	resp.Status = o.Status
+	resp.TimeInForce = o.TimeInForce
+	resp.Type = o.Type
+	resp.Side = o.Side
+	resp.WorkingTime = o.WorkingTime
+	if respType == models.OrderResponseTypeFULL {
+		resp.Fills = fills
+	}
+	return resp
+}
+
+// RespType returns the response type requested for req, or the one Binance
+// uses when none is requested
+func RespType(req models.NewOrderRequest) models.OrderResponseType {
+	if req.NewOrderRespType != "" {
+		return req.NewOrderRespType
+	}
+	if req.Type == models.OrderTypeMarket || req.Type == models.OrderTypeLimit {
+		return models.OrderResponseTypeFULL
+	}
+	return models.OrderResponseTypeACK
+}
+
+// Format formats r with 8 decimal places, as Binance does
+func Format(r *big.Rat) string {
+	return r.FloatString(8)
+}
+
+func positive(s, name string) (*big.Rat, error) {
+	if s == "" {
+		return nil, ErrMandatory(name)
+	}
+	r, err := utils.ParseDecimal(s)
+	if err != nil || r.Sign() <= 0 {
+		return nil, ErrIllegal(name)
+	}
+	return r, nil
+}
@@ -0,0 +1,56 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// csvHeader lists the columns written by WriteCSV
+var csvHeader = []string{"entry_id", "leg", "time", "type", "account", "asset", "debit", "credit", "description"}
+
+// WriteCSV writes one row per leg, so entries can be grouped by entry_id
+func WriteCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	for _, e := range entries {
+		for i, l := range e.Legs {
+			row := []string{
+				e.Id,
+				fmt.Sprint(i + 1),
+				e.Time.UTC().Format(time.RFC3339Nano),
+				string(e.Type),
+				l.Account,
+				l.Asset,
+				l.Debit,
+				l.Credit,
+				e.Description,
+			}
+			if err := cw.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV: %w", err)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// WriteJSONL writes one JSON object per entry and line
+func WriteJSONL(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write JSON lines: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ledger
+
+import (
+	"fmt"
+	"time"
+
+	binance "github.com/MartianPay/go-binance"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/reconciliation"
+)
+
+// Limits of the asset dividend endpoint
+const (
+	dividendPageSize = 500
+	dividendMaxRange = 180 * 24 * time.Hour
+)
+
+// Fetch retrieves every deposit, withdrawal, trade of the given symbols,
+// universal transfer, dust conversion and dividend between start and end and
+// converts them into ledger entries. Isolated margin transfers, which can only
+// be queried per symbol, are left out.
+func Fetch(c *binance.BinanceClient, start, end time.Time, symbols []string) ([]Entry, error) {
+	var transferTypes []models.UniversalTransferType
+	for _, t := range models.UniversalTransferTypes {
+		if !t.RequiresFromSymbol() && !t.RequiresToSymbol() {
+			transferTypes = append(transferTypes, t)
+		}
+	}
+
+	activity, err := reconciliation.New(c).FetchActivity(start, end, symbols, transferTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	dividends, err := fetchDividends(c, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromActivity(activity, dividends)
+}
+
+// fetchDividends fetches the dividends between start and end in windows of at
+// most 180 days, the longest range the endpoint accepts
+func fetchDividends(c *binance.BinanceClient, start, end time.Time) ([]models.AssetDividend, error) {
+	var all []models.AssetDividend
+	seen := make(map[int64]bool)
+
+	for windowStart := start; !windowStart.After(end); {
+		windowEnd := windowStart.Add(dividendMaxRange - time.Millisecond)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+		if err := fetchDividendWindow(c, windowStart, windowEnd, seen, &all); err != nil {
+			return nil, err
+		}
+		windowStart = windowEnd.Add(time.Millisecond)
+	}
+
+	return all, nil
+}
+
+// fetchDividendWindow pages backwards through the dividends of one window,
+// which the endpoint returns newest first, appending those not yet seen
+func fetchDividendWindow(c *binance.BinanceClient, start, end time.Time, seen map[int64]bool, all *[]models.AssetDividend) error {
+	for !end.Before(start) {
+		page, err := c.Account.GetAssetDividend(models.AssetDividendRequest{
+			StartTime: start,
+			EndTime:   end,
+			Limit:     dividendPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch dividends: %w", err)
+		}
+
+		oldest := end.UnixMilli()
+		for _, d := range page.Rows {
+			if !seen[d.Id] {
+				seen[d.Id] = true
+				*all = append(*all, d)
+			}
+			if d.DivTime < oldest {
+				oldest = d.DivTime
+			}
+		}
+
+		if len(page.Rows) < dividendPageSize {
+			return nil
+		}
+
+		// Records at the oldest time are fetched again and dropped above
+		next := time.UnixMilli(oldest)
+		if !next.Before(end) {
+			next = end.Add(-time.Millisecond)
+		}
+		end = next
+	}
+
+	return nil
+}
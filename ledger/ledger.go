@@ -0,0 +1,253 @@
+// Package ledger normalizes balance-affecting account activity into
+// double-entry ledger entries that accounting systems can ingest.
+package ledger
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/reconciliation"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// EntryType classifies a ledger entry
+type EntryType string
+
+const (
+	EntryDeposit    EntryType = "DEPOSIT"
+	EntryWithdrawal EntryType = "WITHDRAWAL"
+	EntryTrade      EntryType = "TRADE"
+	EntryTransfer   EntryType = "TRANSFER"
+	EntryDust       EntryType = "DUST"
+	EntryDividend   EntryType = "DIVIDEND"
+)
+
+// Ledger accounts. Wallet accounts are named "wallet:" followed by the wallet
+// name of the universal transfer types, e.g. wallet:MAIN or wallet:UMFUTURE.
+const (
+	AccountSpot          = "wallet:MAIN"
+	AccountFunding       = "wallet:FUNDING"
+	AccountExternal      = "external"
+	AccountConversion    = "conversion"
+	AccountTradingFees   = "expense:trading_fees"
+	AccountWithdrawalFee = "expense:withdrawal_fees"
+	AccountDustFees      = "expense:dust_fees"
+	AccountDividends     = "income:dividends"
+)
+
+// Leg is one side of a ledger entry. Exactly one of Debit and Credit is set.
+type Leg struct {
+	Account string `json:"account"`
+	Asset   string `json:"asset"`
+	Debit   string `json:"debit,omitempty"`
+	Credit  string `json:"credit,omitempty"`
+}
+
+// Entry is a balanced ledger entry: for every asset the debits equal the credits
+type Entry struct {
+	// Id is derived from the source record, so the same activity always yields the same Id
+	Id          string    `json:"id"`
+	Type        EntryType `json:"type"`
+	Time        time.Time `json:"time"`
+	Description string    `json:"description"`
+	Legs        []Leg     `json:"legs"`
+}
+
+// transfer moves amount of asset from one account to another
+func transfer(from, to, asset string, amount *big.Rat) []Leg {
+	a := utils.FormatDecimal(amount, 8)
+	return []Leg{
+		{Account: to, Asset: asset, Debit: a},
+		{Account: from, Asset: asset, Credit: a},
+	}
+}
+
+// walletAccount returns the ledger account of a deposit or withdrawal wallet
+func walletAccount(w models.WalletType) string {
+	if w == models.WalletTypeFunding {
+		return AccountFunding
+	}
+	return AccountSpot
+}
+
+// transferWallets are the wallet names that make up universal transfer types
+var transferWallets = []string{"MAIN", "UMFUTURE", "CMFUTURE", "MARGIN", "ISOLATEDMARGIN", "FUNDING", "OPTION", "PORTFOLIO_MARGIN"}
+
+// transferAccounts splits a transfer type such as MAIN_PORTFOLIO_MARGIN into its source and destination accounts
+func transferAccounts(t models.UniversalTransferType) (string, string, error) {
+	for _, from := range transferWallets {
+		rest, ok := strings.CutPrefix(string(t), from+"_")
+		if !ok {
+			continue
+		}
+		for _, to := range transferWallets {
+			if rest == to {
+				return "wallet:" + from, "wallet:" + to, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unknown transfer type %s", t)
+}
+
+// FromActivity converts the activity and dividends into ledger entries ordered by time
+func FromActivity(a *reconciliation.Activity, dividends []models.AssetDividend) ([]Entry, error) {
+	var entries []Entry
+
+	for _, d := range a.Deposits {
+		if !d.Status.IsCredited() {
+			continue
+		}
+		amount, err := utils.ParseDecimal(d.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount of deposit %s: %w", d.Id, err)
+		}
+		entries = append(entries, Entry{
+			Id:          "deposit:" + d.Id,
+			Type:        EntryDeposit,
+			Time:        time.UnixMilli(d.InsertTime),
+			Description: fmt.Sprintf("Deposit of %s %s via %s, tx %s", d.Amount, d.Coin, d.Network, d.TxId),
+			Legs:        transfer(AccountExternal, walletAccount(d.WalletType), d.Coin, amount),
+		})
+	}
+
+	for _, w := range a.Withdrawals {
+		if w.Status == models.WithdrawalStatusCancelled || w.Status == models.WithdrawalStatusRejected || w.Status == models.WithdrawalStatusFailure {
+			continue
+		}
+		t, err := time.Parse(models.WithdrawalTimeLayout, w.ApplyTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse apply time of withdrawal %s: %w", w.Id, err)
+		}
+
+		var p utils.DecimalParser
+		amount, fee := p.Parse(w.Amount), p.Parse(w.TransactionFee)
+		if p.Err != nil {
+			return nil, fmt.Errorf("invalid amount of withdrawal %s: %w", w.Id, p.Err)
+		}
+
+		legs := transfer(walletAccount(w.WalletType), AccountExternal, w.Coin, amount)
+		if fee.Sign() != 0 {
+			legs = append(legs, transfer(walletAccount(w.WalletType), AccountWithdrawalFee, w.Coin, fee)...)
+		}
+
+		entries = append(entries, Entry{
+			Id:          "withdrawal:" + w.Id,
+			Type:        EntryWithdrawal,
+			Time:        t,
+			Description: fmt.Sprintf("Withdrawal of %s %s to %s via %s", w.Amount, w.Coin, w.Address, w.Network),
+			Legs:        legs,
+		})
+	}
+
+	for _, t := range a.Trades {
+		info, ok := a.Symbols[t.Symbol]
+		if !ok {
+			return nil, fmt.Errorf("unknown symbol %s", t.Symbol)
+		}
+
+		var p utils.DecimalParser
+		qty, quoteQty, commission := p.Parse(t.Qty), p.Parse(t.QuoteQty), p.Parse(t.Commission)
+		if p.Err != nil {
+			return nil, fmt.Errorf("invalid amount of trade %s:%d: %w", t.Symbol, t.Id, p.Err)
+		}
+
+		// Each asset passes through the conversion account so both currencies balance
+		var legs []Leg
+		side := "Sell"
+		if t.IsBuyer {
+			side = "Buy"
+			legs = append(transfer(AccountConversion, AccountSpot, info.BaseAsset, qty), transfer(AccountSpot, AccountConversion, info.QuoteAsset, quoteQty)...)
+		} else {
+			legs = append(transfer(AccountSpot, AccountConversion, info.BaseAsset, qty), transfer(AccountConversion, AccountSpot, info.QuoteAsset, quoteQty)...)
+		}
+		if commission.Sign() != 0 {
+			legs = append(legs, transfer(AccountSpot, AccountTradingFees, t.CommissionAsset, commission)...)
+		}
+
+		entries = append(entries, Entry{
+			Id:          "trade:" + t.Symbol + ":" + strconv.FormatInt(t.Id, 10),
+			Type:        EntryTrade,
+			Time:        time.UnixMilli(t.Time),
+			Description: fmt.Sprintf("%s %s %s at %s, order %d", side, t.Qty, t.Symbol, t.Price, t.OrderId),
+			Legs:        legs,
+		})
+	}
+
+	for _, t := range a.Transfers {
+		if t.Status != "CONFIRMED" {
+			continue
+		}
+		from, to, err := transferAccounts(t.Type)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := utils.ParseDecimal(t.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount of transfer %d: %w", t.TranId, err)
+		}
+		entries = append(entries, Entry{
+			Id:          "transfer:" + strconv.FormatInt(t.TranId, 10),
+			Type:        EntryTransfer,
+			Time:        time.UnixMilli(t.Timestamp),
+			Description: fmt.Sprintf("Transfer of %s %s (%s)", t.Amount, t.Asset, t.Type),
+			Legs:        transfer(from, to, t.Asset, amount),
+		})
+	}
+
+	for _, d := range a.Dust {
+		for _, item := range d.UserAssetDribbletDetails {
+			id := "dust:" + strconv.FormatInt(item.TransId, 10) + ":" + item.FromAsset
+			var p utils.DecimalParser
+			amount, received, charge := p.Parse(item.Amount), p.Parse(item.TransferedAmount), p.Parse(item.ServiceChargeAmount)
+			if p.Err != nil {
+				return nil, fmt.Errorf("invalid amount of %s: %w", id, p.Err)
+			}
+
+			legs := transfer(AccountSpot, AccountConversion, item.FromAsset, amount)
+			legs = append(legs, transfer(AccountConversion, AccountSpot, "BNB", received)...)
+			if charge.Sign() != 0 {
+				legs = append(legs, transfer(AccountConversion, AccountDustFees, "BNB", charge)...)
+			}
+
+			entries = append(entries, Entry{
+				Id:          id,
+				Type:        EntryDust,
+				Time:        time.UnixMilli(item.OperateTime),
+				Description: fmt.Sprintf("Dust conversion of %s %s to %s BNB", item.Amount, item.FromAsset, item.TransferedAmount),
+				Legs:        legs,
+			})
+		}
+	}
+
+	for _, d := range dividends {
+		amount, err := utils.ParseDecimal(d.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount of dividend %d: %w", d.Id, err)
+		}
+		entries = append(entries, Entry{
+			Id:          "dividend:" + strconv.FormatInt(d.Id, 10),
+			Type:        EntryDividend,
+			Time:        time.UnixMilli(d.DivTime),
+			Description: d.EnInfo,
+			Legs:        transfer(AccountDividends, AccountSpot, d.Asset, amount),
+		})
+	}
+
+	for i := range entries {
+		entries[i].Time = entries[i].Time.UTC()
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		return entries[i].Id < entries[j].Id
+	})
+
+	return entries, nil
+}
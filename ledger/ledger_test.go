@@ -0,0 +1,93 @@
+package ledger_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/ledger"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// amount parses a leg amount, which is empty on the side the leg does not use
+func amount(t *testing.T, s string) *big.Rat {
+	t.Helper()
+	if s == "" {
+		return new(big.Rat)
+	}
+	r, err := utils.ParseDecimal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestFetch(t *testing.T) {
+	srv := binancetest.NewServer()
+	defer srv.Close()
+	c := srv.NewClient()
+
+	now := time.Now()
+	srv.AddDeposit(models.DepositHistory{Coin: "USDT", Amount: "10000", Status: models.DepositStatusSuccess})
+	if _, err := c.Trading.NewOrder(models.NewOrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "0.1"}); err != nil {
+		t.Fatalf("NewOrder: %v", err)
+	}
+	if _, err := c.Withdrawal.Withdraw(models.WithdrawalRequest{Coin: "BTC", Address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", Amount: "0.05"}); err != nil {
+		t.Fatalf("Withdraw: %v", err)
+	}
+	// The first dividend is further back than the 180 days a single request can cover
+	srv.AddDividend(models.AssetDividend{Asset: "BNB", Amount: "1", DivTime: now.Add(-400 * 24 * time.Hour).UnixMilli(), EnInfo: "Airdrop"})
+	srv.AddDividend(models.AssetDividend{Asset: "BNB", Amount: "0.5", EnInfo: "Staking reward"})
+
+	entries, err := ledger.Fetch(c, now.Add(-401*24*time.Hour), now.Add(time.Minute), []string{"BTCUSDT"})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	counts := make(map[ledger.EntryType]int)
+	spot := make(map[string]*big.Rat)
+	for i, e := range entries {
+		counts[e.Type]++
+		if i > 0 && e.Time.Before(entries[i-1].Time) {
+			t.Errorf("entry %s is out of order", e.Id)
+		}
+
+		sums := make(map[string]*big.Rat)
+		for _, l := range e.Legs {
+			change := new(big.Rat).Sub(amount(t, l.Debit), amount(t, l.Credit))
+			if sums[l.Asset] == nil {
+				sums[l.Asset] = new(big.Rat)
+			}
+			sums[l.Asset].Add(sums[l.Asset], change)
+
+			if l.Account == ledger.AccountSpot {
+				if spot[l.Asset] == nil {
+					spot[l.Asset] = new(big.Rat)
+				}
+				spot[l.Asset].Add(spot[l.Asset], change)
+			}
+		}
+		for asset, sum := range sums {
+			if sum.Sign() != 0 {
+				t.Errorf("entry %s does not balance in %s", e.Id, asset)
+			}
+		}
+	}
+
+	want := map[ledger.EntryType]int{ledger.EntryDeposit: 1, ledger.EntryTrade: 1, ledger.EntryWithdrawal: 1, ledger.EntryDividend: 2}
+	for typ, n := range want {
+		if counts[typ] != n {
+			t.Errorf("expected %d %s entries, got %d", n, typ, counts[typ])
+		}
+	}
+
+	// The spot account of the ledger ends at the balances of the wallet
+	for _, asset := range []string{"USDT", "BTC", "BNB"} {
+		free := srv.Balance(asset).Free
+		if spot[asset] == nil || spot[asset].Cmp(amount(t, free)) != 0 {
+			t.Errorf("%s: ledger ends at %v, wallet holds %s", asset, spot[asset], free)
+		}
+	}
+}
@@ -48,12 +48,12 @@ type AccountInfo struct {
 }
 
 type AssetTransferRequest struct {
-	Type       string `json:"type"`
-	Asset      string `json:"asset"`
-	Amount     string `json:"amount"`
-	FromSymbol string `json:"fromSymbol,omitempty"`
-	ToSymbol   string `json:"toSymbol,omitempty"`
-	RecvWindow int64  `json:"recvWindow,omitempty"`
+	Type       UniversalTransferType `json:"type"`
+	Asset      string                `json:"asset"`
+	Amount     string                `json:"amount"`
+	FromSymbol string                `json:"fromSymbol,omitempty"`
+	ToSymbol   string                `json:"toSymbol,omitempty"`
+	RecvWindow int64                 `json:"recvWindow,omitempty"`
 }
 
 type AssetTransferResponse struct {
@@ -0,0 +1,84 @@
+package models
+
+// AccountStatus is the response of the account status endpoint; Data is "Normal" for a healthy account
+type AccountStatus struct {
+	Data string `json:"data"`
+}
+
+// APITradingStatus is the response of the API trading status endpoint
+type APITradingStatus struct {
+	Data APITradingStatusData `json:"data"`
+}
+
+// APITradingStatusData reports whether API trading is locked by the quantitative rules
+type APITradingStatusData struct {
+	IsLocked           bool               `json:"isLocked"`
+	PlannedRecoverTime int64              `json:"plannedRecoverTime"` // ms; 0 when not locked
+	TriggerCondition   map[string]float64 `json:"triggerCondition"`   // e.g. GCR, IFER, UFR
+	UpdateTime         int64              `json:"updateTime"`
+	// Indicators holds per-symbol indicators that triggered or are close to a lock
+	Indicators map[string][]APITradingIndicator `json:"indicators,omitempty"`
+}
+
+// APITradingIndicator is one quantitative rule indicator of a symbol
+type APITradingIndicator struct {
+	Indicator    string  `json:"i"`
+	Count        int     `json:"c"`
+	CurrentValue float64 `json:"v"`
+	TriggerValue float64 `json:"t"`
+}
+
+// APIRestrictions are the permissions and IP restriction of the API key
+type APIRestrictions struct {
+	IPRestrict                     bool  `json:"ipRestrict"`
+	CreateTime                     int64 `json:"createTime"`
+	EnableReading                  bool  `json:"enableReading"`
+	EnableSpotAndMarginTrading     bool  `json:"enableSpotAndMarginTrading"`
+	EnableWithdrawals              bool  `json:"enableWithdrawals"`
+	EnableInternalTransfer         bool  `json:"enableInternalTransfer"`
+	PermitsUniversalTransfer       bool  `json:"permitsUniversalTransfer"`
+	EnableMargin                   bool  `json:"enableMargin"`
+	EnableFutures                  bool  `json:"enableFutures"`
+	EnableVanillaOptions           bool  `json:"enableVanillaOptions"`
+	EnablePortfolioMarginTrading   bool  `json:"enablePortfolioMarginTrading"`
+	EnableFixAPITrade              bool  `json:"enableFixApiTrade"`
+	EnableFixReadOnly              bool  `json:"enableFixReadOnly"`
+	TradingAuthorityExpirationTime int64 `json:"tradingAuthorityExpirationTime,omitempty"` // ms; set for keys with time-limited trading
+}
+
+// APIPermission names a permission an API key can be granted
+type APIPermission string
+
+const (
+	APIPermissionReading           APIPermission = "READING"
+	APIPermissionSpotTrading       APIPermission = "SPOT_AND_MARGIN_TRADING"
+	APIPermissionWithdrawals       APIPermission = "WITHDRAWALS"
+	APIPermissionInternalTransfer  APIPermission = "INTERNAL_TRANSFER"
+	APIPermissionUniversalTransfer APIPermission = "UNIVERSAL_TRANSFER"
+	APIPermissionMargin            APIPermission = "MARGIN"
+	APIPermissionFutures           APIPermission = "FUTURES"
+	APIPermissionVanillaOptions    APIPermission = "VANILLA_OPTIONS"
+)
+
+// Has reports whether the key is granted permission p
+func (r APIRestrictions) Has(p APIPermission) bool {
+	switch p {
+	case APIPermissionReading:
+		return r.EnableReading
+	case APIPermissionSpotTrading:
+		return r.EnableSpotAndMarginTrading
+	case APIPermissionWithdrawals:
+		return r.EnableWithdrawals
+	case APIPermissionInternalTransfer:
+		return r.EnableInternalTransfer
+	case APIPermissionUniversalTransfer:
+		return r.PermitsUniversalTransfer
+	case APIPermissionMargin:
+		return r.EnableMargin
+	case APIPermissionFutures:
+		return r.EnableFutures
+	case APIPermissionVanillaOptions:
+		return r.EnableVanillaOptions
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AssetDetail is the deposit and withdrawal configuration of an asset
+type AssetDetail struct {
+	MinWithdrawAmount string      `json:"minWithdrawAmount"`
+	DepositStatus     bool        `json:"depositStatus"`
+	WithdrawFee       json.Number `json:"withdrawFee"`
+	WithdrawStatus    bool        `json:"withdrawStatus"`
+	DepositTip        string      `json:"depositTip,omitempty"`
+}
+
+// AssetDetailRequest requests asset details; an empty Asset returns every asset
+type AssetDetailRequest struct {
+	Asset      string `json:"asset,omitempty"`
+	RecvWindow int64  `json:"recvWindow,omitempty"`
+}
+
+// TradeFeeRequest requests trade fees; an empty Symbol returns every symbol
+type TradeFeeRequest struct {
+	Symbol     string `json:"symbol,omitempty"`
+	RecvWindow int64  `json:"recvWindow,omitempty"`
+}
+
+// TradeFee is the maker and taker commission rate of a symbol
+type TradeFee struct {
+	Symbol          string `json:"symbol"`
+	MakerCommission string `json:"makerCommission"`
+	TakerCommission string `json:"takerCommission"`
+}
+
+// DustAccountType selects the wallet dust is converted from
+type DustAccountType string
+
+const (
+	DustAccountSpot   DustAccountType = "SPOT"
+	DustAccountMargin DustAccountType = "MARGIN"
+)
+
+// DustConvertibleRequest lists assets that can be converted to BNB
+type DustConvertibleRequest struct {
+	AccountType DustAccountType `json:"accountType,omitempty"`
+	RecvWindow  int64           `json:"recvWindow,omitempty"`
+}
+
+// DustConvertible lists the assets that can be converted to BNB and their value
+type DustConvertible struct {
+	Details            []DustConvertibleAsset `json:"details"`
+	TotalTransferBtc   string                 `json:"totalTransferBtc"`
+	TotalTransferBNB   string                 `json:"totalTransferBNB"`
+	DribbletPercentage string                 `json:"dribbletPercentage"`
+}
+
+// DustConvertibleAsset is an asset that can be converted to BNB
+type DustConvertibleAsset struct {
+	Asset            string `json:"asset"`
+	AssetFullName    string `json:"assetFullName"`
+	AmountFree       string `json:"amountFree"`
+	ToBTC            string `json:"toBTC"`
+	ToBNB            string `json:"toBNB"`
+	ToBNBOffExchange string `json:"toBNBOffExchange"`
+	Exchange         string `json:"exchange"`
+}
+
+// DustTransferRequest converts small balances of the given assets to BNB
+type DustTransferRequest struct {
+	Assets      []string        `json:"asset"`
+	AccountType DustAccountType `json:"accountType,omitempty"`
+	RecvWindow  int64           `json:"recvWindow,omitempty"`
+}
+
+// DustTransferResponse is the result of a dust conversion
+type DustTransferResponse struct {
+	TotalServiceCharge string               `json:"totalServiceCharge"`
+	TotalTransfered    string               `json:"totalTransfered"`
+	TransferResult     []DustTransferResult `json:"transferResult"`
+}
+
+// DustTransferResult is the conversion of one asset
+type DustTransferResult struct {
+	Amount              string `json:"amount"`
+	FromAsset           string `json:"fromAsset"`
+	OperateTime         int64  `json:"operateTime"`
+	ServiceChargeAmount string `json:"serviceChargeAmount"`
+	TranId              int64  `json:"tranId"`
+	TransferedAmount    string `json:"transferedAmount"`
+}
+
+// DustLogRequest queries dust conversion history
+type DustLogRequest struct {
+	StartTime  time.Time `json:"startTime,omitempty"`
+	EndTime    time.Time `json:"endTime,omitempty"`
+	RecvWindow int64     `json:"recvWindow,omitempty"`
+}
+
+// DustLog is the dust conversion history
+type DustLog struct {
+	Total              int              `json:"total"`
+	UserAssetDribblets []DustConversion `json:"userAssetDribblets"`
+}
+
+// DustConversion is one dust conversion, which may cover several assets
+type DustConversion struct {
+	OperateTime              int64                `json:"operateTime"`
+	TotalTransferedAmount    string               `json:"totalTransferedAmount"`
+	TotalServiceChargeAmount string               `json:"totalServiceChargeAmount"`
+	TransId                  int64                `json:"transId"`
+	UserAssetDribbletDetails []DustConversionItem `json:"userAssetDribbletDetails"`
+}
+
+// DustConversionItem is the conversion of one asset within a dust conversion
+type DustConversionItem struct {
+	TransId             int64  `json:"transId"`
+	ServiceChargeAmount string `json:"serviceChargeAmount"`
+	Amount              string `json:"amount"`
+	OperateTime         int64  `json:"operateTime"`
+	TransferedAmount    string `json:"transferedAmount"`
+	FromAsset           string `json:"fromAsset"`
+}
+
+// AssetDividendRequest queries asset dividend records
+type AssetDividendRequest struct {
+	Asset      string    `json:"asset,omitempty"`
+	StartTime  time.Time `json:"startTime,omitempty"`
+	EndTime    time.Time `json:"endTime,omitempty"`
+	Limit      int       `json:"limit,omitempty"` // Default 20; max 500
+	RecvWindow int64     `json:"recvWindow,omitempty"`
+}
+
+// AssetDividends is a page of asset dividend records
+type AssetDividends struct {
+	Rows  []AssetDividend `json:"rows"`
+	Total int             `json:"total"`
+}
+
+// AssetDividend is a distribution such as an airdrop, staking reward or fork credit
+type AssetDividend struct {
+	Id      int64  `json:"id"`
+	Amount  string `json:"amount"`
+	Asset   string `json:"asset"`
+	DivTime int64  `json:"divTime"`
+	EnInfo  string `json:"enInfo"`
+	TranId  int64  `json:"tranId"`
+}
@@ -1,6 +1,61 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// DepositStatus represents the status of a deposit
+type DepositStatus int
+
+const (
+	DepositStatusPending                DepositStatus = 0
+	DepositStatusSuccess                DepositStatus = 1
+	DepositStatusRejected               DepositStatus = 2
+	DepositStatusCreditedCannotWithdraw DepositStatus = 6
+	DepositStatusWrongDeposit           DepositStatus = 7
+	DepositStatusWaitingUserConfirm     DepositStatus = 8
+)
+
+func (s DepositStatus) String() string {
+	switch s {
+	case DepositStatusPending:
+		return "Pending"
+	case DepositStatusSuccess:
+		return "Success"
+	case DepositStatusRejected:
+		return "Rejected"
+	case DepositStatusCreditedCannotWithdraw:
+		return "Credited But Cannot Withdraw"
+	case DepositStatusWrongDeposit:
+		return "Wrong Deposit"
+	case DepositStatusWaitingUserConfirm:
+		return "Waiting User Confirm"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(s))
+	}
+}
+
+// IsTerminal reports whether the deposit will not change status any more
+func (s DepositStatus) IsTerminal() bool {
+	switch s {
+	case DepositStatusSuccess, DepositStatusRejected, DepositStatusWrongDeposit:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsSuccess reports whether the deposit has been fully credited and can be withdrawn
+func (s DepositStatus) IsSuccess() bool {
+	return s == DepositStatusSuccess
+}
+
+// IsCredited reports whether the deposit amount has been credited to the account,
+// even if it cannot be withdrawn yet
+func (s DepositStatus) IsCredited() bool {
+	return s == DepositStatusSuccess || s == DepositStatusCreditedCannotWithdraw
+}
 
 type DepositAddress struct {
 	Address string `json:"address"`
@@ -10,34 +65,60 @@ type DepositAddress struct {
 }
 
 type DepositHistory struct {
-	Id              string    `json:"id"`
-	Amount          string    `json:"amount"`
-	Coin            string    `json:"coin"`
-	Network         string    `json:"network"`
-	Status          int       `json:"status"`
-	Address         string    `json:"address"`
-	AddressTag      string    `json:"addressTag,omitempty"`
-	TxId            string    `json:"txId"`
-	InsertTime      int64     `json:"insertTime"`
-	TransferType    int       `json:"transferType"`
-	UnlockConfirm   int       `json:"unlockConfirm,omitempty"`
-	ConfirmTimes    string    `json:"confirmTimes"`
-	WalletType      int       `json:"walletType"`
+	Id            string        `json:"id"`
+	Amount        string        `json:"amount"`
+	Coin          string        `json:"coin"`
+	Network       string        `json:"network"`
+	Status        DepositStatus `json:"status"`
+	Address       string        `json:"address"`
+	AddressTag    string        `json:"addressTag,omitempty"`
+	TxId          string        `json:"txId"`
+	InsertTime    int64         `json:"insertTime"`
+	TransferType  TransferType  `json:"transferType"`
+	UnlockConfirm int           `json:"unlockConfirm,omitempty"`
+	ConfirmTimes  string        `json:"confirmTimes"`
+	WalletType    WalletType    `json:"walletType"`
 }
 
 type DepositAddressRequest struct {
 	Coin       string `json:"coin"`
 	Network    string `json:"network,omitempty"`
+	Amount     string `json:"amount,omitempty"` // Required by networks that bind the address to an amount, such as Lightning
+	RecvWindow int64  `json:"recvWindow,omitempty"`
+}
+
+// DepositAddressListRequest requests every deposit address of a coin
+type DepositAddressListRequest struct {
+	Coin       string `json:"coin"`
+	Network    string `json:"network,omitempty"`
+	RecvWindow int64  `json:"recvWindow,omitempty"`
+}
+
+// DepositAddressListItem is one of the deposit addresses of a coin
+type DepositAddressListItem struct {
+	Coin      string `json:"coin"`
+	Address   string `json:"address"`
+	Tag       string `json:"tag"`
+	Network   string `json:"network,omitempty"`
+	IsDefault int    `json:"isDefault"` // 1 for the network's default address
+}
+
+// SubAccountDepositAddressRequest requests a sub-account's deposit address from the master account
+type SubAccountDepositAddressRequest struct {
+	Email      string `json:"email"`
+	Coin       string `json:"coin"`
+	Network    string `json:"network,omitempty"`
+	Amount     string `json:"amount,omitempty"`
 	RecvWindow int64  `json:"recvWindow,omitempty"`
 }
 
 type DepositHistoryRequest struct {
-	Coin       string    `json:"coin,omitempty"`
-	Status     int       `json:"status,omitempty"`
-	StartTime  time.Time `json:"startTime,omitempty"`
-	EndTime    time.Time `json:"endTime,omitempty"`
-	Offset     int       `json:"offset,omitempty"`
-	Limit      int       `json:"limit,omitempty"`
-	RecvWindow int64     `json:"recvWindow,omitempty"`
-	TxId       string    `json:"txId,omitempty"`
-}
\ No newline at end of file
+	Coin       string         `json:"coin,omitempty"`
+	Status     *DepositStatus `json:"status,omitempty"` // nil returns deposits of every status
+	StartTime  time.Time      `json:"startTime,omitempty"`
+	EndTime    time.Time      `json:"endTime,omitempty"`
+	Offset     int            `json:"offset,omitempty"`
+	Limit      int            `json:"limit,omitempty"`
+	RecvWindow int64          `json:"recvWindow,omitempty"`
+	TxId       string         `json:"txId,omitempty"`
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// SnapshotType is the account type of a daily account snapshot
+type SnapshotType string
+
+const (
+	SnapshotTypeSpot    SnapshotType = "SPOT"
+	SnapshotTypeMargin  SnapshotType = "MARGIN"
+	SnapshotTypeFutures SnapshotType = "FUTURES"
+)
+
+// AccountSnapshotRequest requests daily account snapshots. The range may not
+// exceed 30 days and only the last month is available.
+type AccountSnapshotRequest struct {
+	StartTime  time.Time `json:"startTime,omitempty"`
+	EndTime    time.Time `json:"endTime,omitempty"`
+	Limit      int       `json:"limit,omitempty"` // Default 7; min 7, max 30
+	RecvWindow int64     `json:"recvWindow,omitempty"`
+}
+
+// AccountSnapshotResponse is the envelope of the account snapshot endpoint
+type AccountSnapshotResponse[T any] struct {
+	Code        int    `json:"code"`
+	Msg         string `json:"msg"`
+	SnapshotVos []T    `json:"snapshotVos"`
+}
+
+// SpotSnapshot is the end of day spot wallet balance
+type SpotSnapshot struct {
+	Type       string           `json:"type"`
+	UpdateTime int64            `json:"updateTime"`
+	Data       SpotSnapshotData `json:"data"`
+}
+
+type SpotSnapshotData struct {
+	Balances        []Balance `json:"balances"`
+	TotalAssetOfBtc string    `json:"totalAssetOfBtc"`
+}
+
+// MarginSnapshot is the end of day cross margin account balance
+type MarginSnapshot struct {
+	Type       string             `json:"type"`
+	UpdateTime int64              `json:"updateTime"`
+	Data       MarginSnapshotData `json:"data"`
+}
+
+type MarginSnapshotData struct {
+	MarginLevel         string            `json:"marginLevel"`
+	TotalAssetOfBtc     string            `json:"totalAssetOfBtc"`
+	TotalLiabilityOfBtc string            `json:"totalLiabilityOfBtc"`
+	TotalNetAssetOfBtc  string            `json:"totalNetAssetOfBtc"`
+	UserAssets          []MarginUserAsset `json:"userAssets"`
+}
+
+type MarginUserAsset struct {
+	Asset    string `json:"asset"`
+	Borrowed string `json:"borrowed"`
+	Free     string `json:"free"`
+	Interest string `json:"interest"`
+	Locked   string `json:"locked"`
+	NetAsset string `json:"netAsset"`
+}
+
+// FuturesSnapshot is the end of day USDⓈ-M futures account balance and positions
+type FuturesSnapshot struct {
+	Type       string              `json:"type"`
+	UpdateTime int64               `json:"updateTime"`
+	Data       FuturesSnapshotData `json:"data"`
+}
+
+type FuturesSnapshotData struct {
+	Assets   []FuturesSnapshotAsset    `json:"assets"`
+	Position []FuturesSnapshotPosition `json:"position"`
+}
+
+type FuturesSnapshotAsset struct {
+	Asset         string `json:"asset"`
+	MarginBalance string `json:"marginBalance"`
+	WalletBalance string `json:"walletBalance"`
+}
+
+type FuturesSnapshotPosition struct {
+	EntryPrice       string `json:"entryPrice"`
+	MarkPrice        string `json:"markPrice"`
+	PositionAmt      string `json:"positionAmt"`
+	Symbol           string `json:"symbol"`
+	UnRealizedProfit string `json:"unRealizedProfit"`
+}
+
+// AssetBalance is an asset's balance normalised across account types
+type AssetBalance struct {
+	Asset    string
+	Free     string
+	Locked   string
+	Borrowed string
+	Interest string
+	// Total is the net holding: free + locked - borrowed - interest for spot and
+	// margin, the wallet balance for futures
+	Total string
+}
+
+// BalanceTable holds per-asset balances of one account at one point in time
+type BalanceTable struct {
+	Account  SnapshotType
+	Time     time.Time
+	Balances map[string]AssetBalance
+}
+
+// Assets returns the assets in the table in alphabetical order
+func (t *BalanceTable) Assets() []string {
+	assets := make([]string, 0, len(t.Balances))
+	for asset := range t.Balances {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
@@ -0,0 +1,13 @@
+package models
+
+// TickerPriceRequest requests latest prices; with no symbols every symbol is returned
+type TickerPriceRequest struct {
+	Symbol  string   `json:"symbol,omitempty"`  // Single symbol
+	Symbols []string `json:"symbols,omitempty"` // Multiple symbols
+}
+
+// TickerPrice is the latest price of a symbol
+type TickerPrice struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
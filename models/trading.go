@@ -46,6 +46,16 @@ const (
 	OrderStatusExpiredInMatch  OrderStatus = "EXPIRED_IN_MATCH"
 )
 
+// IsTerminal reports whether no further updates are expected for an order in this status
+func (s OrderStatus) IsTerminal() bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired, OrderStatusExpiredInMatch:
+		return true
+	default:
+		return false
+	}
+}
+
 // OrderResponseType represents response type for orders
 type OrderResponseType string
 
@@ -327,4 +337,51 @@ type NotionalFilter struct {
 	MaxNotional       string `json:"maxNotional"`
 	ApplyMaxToMarket  bool   `json:"applyMaxToMarket"`
 	AvgPriceMins      int    `json:"avgPriceMins"`
-}
\ No newline at end of file
+}
+
+// ExecutionType represents the execution type of a user data stream execution report
+type ExecutionType string
+
+const (
+	ExecutionTypeNew             ExecutionType = "NEW"
+	ExecutionTypeCanceled        ExecutionType = "CANCELED"
+	ExecutionTypeReplaced        ExecutionType = "REPLACED"
+	ExecutionTypeRejected        ExecutionType = "REJECTED"
+	ExecutionTypeTrade           ExecutionType = "TRADE"
+	ExecutionTypeExpired         ExecutionType = "EXPIRED"
+	ExecutionTypeTradePrevention ExecutionType = "TRADE_PREVENTION"
+)
+
+// ExecutionReport represents an executionReport event from the user data stream
+type ExecutionReport struct {
+	EventType           string        `json:"e"`
+	EventTime           int64         `json:"E"`
+	Symbol              string        `json:"s"`
+	ClientOrderId       string        `json:"c"`
+	Side                OrderSide     `json:"S"`
+	Type                OrderType     `json:"o"`
+	TimeInForce         TimeInForce   `json:"f"`
+	Quantity            string        `json:"q"`
+	Price               string        `json:"p"`
+	StopPrice           string        `json:"P"`
+	IcebergQty          string        `json:"F"`
+	OrderListId         int64         `json:"g"`
+	OrigClientOrderId   string        `json:"C"`
+	ExecutionType       ExecutionType `json:"x"`
+	Status              OrderStatus   `json:"X"`
+	RejectReason        string        `json:"r"`
+	OrderId             int64         `json:"i"`
+	LastExecutedQty     string        `json:"l"`
+	CumulativeFilledQty string        `json:"z"`
+	LastExecutedPrice   string        `json:"L"`
+	Commission          string        `json:"n"`
+	CommissionAsset     string        `json:"N"`
+	TransactionTime     int64         `json:"T"`
+	TradeId             int64         `json:"t"`
+	IsWorking           bool          `json:"w"`
+	IsMaker             bool          `json:"m"`
+	CreationTime        int64         `json:"O"`
+	CumulativeQuoteQty  string        `json:"Z"`
+	LastQuoteQty        string        `json:"Y"`
+	QuoteOrderQty       string        `json:"Q"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+// UniversalTransferType is the source and destination wallet of a universal transfer
+type UniversalTransferType string
+
+const (
+	TransferMainUMFuture                 UniversalTransferType = "MAIN_UMFUTURE"
+	TransferMainCMFuture                 UniversalTransferType = "MAIN_CMFUTURE"
+	TransferMainMargin                   UniversalTransferType = "MAIN_MARGIN"
+	TransferUMFutureMain                 UniversalTransferType = "UMFUTURE_MAIN"
+	TransferUMFutureMargin               UniversalTransferType = "UMFUTURE_MARGIN"
+	TransferCMFutureMain                 UniversalTransferType = "CMFUTURE_MAIN"
+	TransferCMFutureMargin               UniversalTransferType = "CMFUTURE_MARGIN"
+	TransferMarginMain                   UniversalTransferType = "MARGIN_MAIN"
+	TransferMarginUMFuture               UniversalTransferType = "MARGIN_UMFUTURE"
+	TransferMarginCMFuture               UniversalTransferType = "MARGIN_CMFUTURE"
+	TransferIsolatedMarginMargin         UniversalTransferType = "ISOLATEDMARGIN_MARGIN"
+	TransferMarginIsolatedMargin         UniversalTransferType = "MARGIN_ISOLATEDMARGIN"
+	TransferIsolatedMarginIsolatedMargin UniversalTransferType = "ISOLATEDMARGIN_ISOLATEDMARGIN"
+	TransferMainFunding                  UniversalTransferType = "MAIN_FUNDING"
+	TransferFundingMain                  UniversalTransferType = "FUNDING_MAIN"
+	TransferFundingUMFuture              UniversalTransferType = "FUNDING_UMFUTURE"
+	TransferUMFutureFunding              UniversalTransferType = "UMFUTURE_FUNDING"
+	TransferMarginFunding                UniversalTransferType = "MARGIN_FUNDING"
+	TransferFundingMargin                UniversalTransferType = "FUNDING_MARGIN"
+	TransferFundingCMFuture              UniversalTransferType = "FUNDING_CMFUTURE"
+	TransferCMFutureFunding              UniversalTransferType = "CMFUTURE_FUNDING"
+	TransferMainOption                   UniversalTransferType = "MAIN_OPTION"
+	TransferOptionMain                   UniversalTransferType = "OPTION_MAIN"
+	TransferUMFutureOption               UniversalTransferType = "UMFUTURE_OPTION"
+	TransferOptionUMFuture               UniversalTransferType = "OPTION_UMFUTURE"
+	TransferMarginOption                 UniversalTransferType = "MARGIN_OPTION"
+	TransferOptionMargin                 UniversalTransferType = "OPTION_MARGIN"
+	TransferFundingOption                UniversalTransferType = "FUNDING_OPTION"
+	TransferOptionFunding                UniversalTransferType = "OPTION_FUNDING"
+	TransferMainPortfolioMargin          UniversalTransferType = "MAIN_PORTFOLIO_MARGIN"
+	TransferPortfolioMarginMain          UniversalTransferType = "PORTFOLIO_MARGIN_MAIN"
+)
+
+// UniversalTransferTypes lists every documented universal transfer type
+var UniversalTransferTypes = []UniversalTransferType{
+	TransferMainUMFuture, TransferMainCMFuture, TransferMainMargin,
+	TransferUMFutureMain, TransferUMFutureMargin,
+	TransferCMFutureMain, TransferCMFutureMargin,
+	TransferMarginMain, TransferMarginUMFuture, TransferMarginCMFuture,
+	TransferIsolatedMarginMargin, TransferMarginIsolatedMargin, TransferIsolatedMarginIsolatedMargin,
+	TransferMainFunding, TransferFundingMain,
+	TransferFundingUMFuture, TransferUMFutureFunding,
+	TransferMarginFunding, TransferFundingMargin,
+	TransferFundingCMFuture, TransferCMFutureFunding,
+	TransferMainOption, TransferOptionMain,
+	TransferUMFutureOption, TransferOptionUMFuture,
+	TransferMarginOption, TransferOptionMargin,
+	TransferFundingOption, TransferOptionFunding,
+	TransferMainPortfolioMargin, TransferPortfolioMarginMain,
+}
+
+// IsValid reports whether t is a documented transfer type
+func (t UniversalTransferType) IsValid() bool {
+	for _, v := range UniversalTransferTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresFromSymbol reports whether transfers of this type must name the source isolated margin symbol
+func (t UniversalTransferType) RequiresFromSymbol() bool {
+	return t == TransferIsolatedMarginMargin || t == TransferIsolatedMarginIsolatedMargin
+}
+
+// RequiresToSymbol reports whether transfers of this type must name the destination isolated margin symbol
+func (t UniversalTransferType) RequiresToSymbol() bool {
+	return t == TransferMarginIsolatedMargin || t == TransferIsolatedMarginIsolatedMargin
+}
+
+// TransferHistoryRequest queries universal transfer history
+type TransferHistoryRequest struct {
+	Type       UniversalTransferType `json:"type"`
+	StartTime  time.Time             `json:"startTime,omitempty"`
+	EndTime    time.Time             `json:"endTime,omitempty"`
+	Current    int                   `json:"current,omitempty"` // Page number, starting at 1
+	Size       int                   `json:"size,omitempty"`    // Default 10; max 100
+	FromSymbol string                `json:"fromSymbol,omitempty"`
+	ToSymbol   string                `json:"toSymbol,omitempty"`
+	RecvWindow int64                 `json:"recvWindow,omitempty"`
+}
+
+// TransferHistory is a page of universal transfer records
+type TransferHistory struct {
+	Total int              `json:"total"`
+	Rows  []TransferRecord `json:"rows"`
+}
+
+// TransferRecord is a single universal transfer
+type TransferRecord struct {
+	Asset     string                `json:"asset"`
+	Amount    string                `json:"amount"`
+	Type      UniversalTransferType `json:"type"`
+	Status    string                `json:"status"`
+	TranId    int64                 `json:"tranId"`
+	Timestamp int64                 `json:"timestamp"`
+}
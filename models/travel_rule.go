@@ -0,0 +1,271 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TravelRuleStatus is the travel rule check status of a deposit or withdrawal
+type TravelRuleStatus int
+
+const (
+	TravelRuleStatusCompleted TravelRuleStatus = 0
+	TravelRuleStatusPending   TravelRuleStatus = 1
+	TravelRuleStatusFailed    TravelRuleStatus = 2
+)
+
+func (s TravelRuleStatus) String() string {
+	switch s {
+	case TravelRuleStatusCompleted:
+		return "Completed"
+	case TravelRuleStatusPending:
+		return "Pending"
+	case TravelRuleStatusFailed:
+		return "Failed"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(s))
+	}
+}
+
+// DepositCreditApplyRequest asks Binance to credit a deposit that arrived
+// without the required memo or on a suspended network
+type DepositCreditApplyRequest struct {
+	DepositId    int64  `json:"depositId,omitempty"` // Deposit record ID, takes priority over TxId
+	TxId         string `json:"txId,omitempty"`
+	SubAccountId int64  `json:"subAccountId,omitempty"`
+	SubUserId    int64  `json:"subUserId,omitempty"`
+}
+
+// DepositCreditApplyResponse is the result of a deposit credit application
+type DepositCreditApplyResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    bool   `json:"data"`
+	Success bool   `json:"success"`
+}
+
+// LocalEntityDepositHistoryRequest queries deposits subject to the travel rule
+type LocalEntityDepositHistoryRequest struct {
+	TrId                 string            `json:"trId,omitempty"`   // Comma separated travel rule record IDs
+	TxId                 string            `json:"txId,omitempty"`   // Comma separated transaction IDs
+	TranId               string            `json:"tranId,omitempty"` // Comma separated wallet transaction IDs
+	Network              string            `json:"network,omitempty"`
+	Coin                 string            `json:"coin,omitempty"`
+	TravelRuleStatus     *TravelRuleStatus `json:"travelRuleStatus,omitempty"`
+	PendingQuestionnaire *bool             `json:"pendingQuestionnaire,omitempty"` // Only deposits still awaiting a questionnaire
+	StartTime            time.Time         `json:"startTime,omitempty"`
+	EndTime              time.Time         `json:"endTime,omitempty"`
+	Offset               int               `json:"offset,omitempty"`
+	Limit                int               `json:"limit,omitempty"`
+}
+
+// LocalEntityDeposit is a deposit record with its travel rule state
+type LocalEntityDeposit struct {
+	TrId                 int64            `json:"trId"`
+	TranId               int64            `json:"tranId"`
+	Amount               string           `json:"amount"`
+	Coin                 string           `json:"coin"`
+	Network              string           `json:"network"`
+	DepositStatus        DepositStatus    `json:"depositStatus"`
+	TravelRuleStatus     TravelRuleStatus `json:"travelRuleStatus"`
+	Address              string           `json:"address"`
+	AddressTag           string           `json:"addressTag"`
+	TxId                 string           `json:"txId"`
+	InsertTime           int64            `json:"insertTime"`
+	TransferType         TransferType     `json:"transferType"`
+	ConfirmTimes         string           `json:"confirmTimes"`
+	UnlockConfirm        int              `json:"unlockConfirm"`
+	WalletType           WalletType       `json:"walletType"`
+	RequireQuestionnaire bool             `json:"requireQuestionnaire"`
+	Questionnaire        string           `json:"questionnaire"`
+}
+
+// DepositQuestionnaire is the questionnaire of a travel rule deposit. Use
+// OriginatorQuestionnaire, or RawQuestionnaire for a format not modelled here.
+type DepositQuestionnaire interface {
+	depositQuestionnaire()
+}
+
+// Deposit questionnaire answers, following Binance's "Deposit Questionnaire Content"
+const (
+	DepositOriginatorSelf  = 1 // the deposit comes from the account holder's own address
+	DepositOriginatorOther = 2
+
+	OriginatorIndividual = 0
+	OriginatorCorporate  = 1
+
+	ReceiveFromPrivateWallet = 0
+	ReceiveFromVASP          = 1
+)
+
+// OriginatorQuestionnaire is the deposit questionnaire, describing who sent the deposit
+type OriginatorQuestionnaire struct {
+	DepositOriginator int    `json:"depositOriginator"`
+	OrgType           int    `json:"orgType,omitempty"`
+	OrgName           string `json:"orgName,omitempty"`
+	Country           string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	City              string `json:"city,omitempty"`
+	ReceiveFrom       int    `json:"receiveFrom"`
+	Vasp              string `json:"vasp,omitempty"`     // VASP code from GetVASPList when ReceiveFrom is ReceiveFromVASP
+	VaspName          string `json:"vaspName,omitempty"` // Free text when the VASP is not in the list
+	Declaration       bool   `json:"declaration"`
+}
+
+func (OriginatorQuestionnaire) depositQuestionnaire() {}
+
+// DepositQuestionnaireRequest submits the travel rule questionnaire for a deposit
+type DepositQuestionnaireRequest struct {
+	TranId        int64                `json:"tranId"`
+	Questionnaire DepositQuestionnaire `json:"questionnaire"`
+}
+
+// DepositQuestionnaireResponse is the result of submitting a deposit questionnaire
+type DepositQuestionnaireResponse struct {
+	TrId     int64  `json:"trId"`
+	Accepted bool   `json:"accepted"`
+	Info     string `json:"info"`
+}
+
+// VASP is a virtual asset service provider onboarded for travel rule exchanges
+type VASP struct {
+	VaspName string `json:"vaspName"`
+	VaspCode string `json:"vaspCode"`
+}
+
+// QuestionnaireRequirements identifies the local entity the account is onboarded
+// with, which determines the questionnaire format
+type QuestionnaireRequirements struct {
+	QuestionnaireCountryCode string `json:"questionnaireCountryCode"`
+}
+
+// Questionnaire answers shared by the travel rule withdrawal questionnaires.
+// The accepted values follow Binance's "Withdrawal Questionnaire Content" for each local entity.
+const (
+	AddressOwnerYes = 1 // the withdrawal goes to the account holder's own address
+	AddressOwnerNo  = 2
+
+	BeneficiaryIndividual = 0
+	BeneficiaryCorporate  = 1
+
+	SendToPrivateWallet = 0
+	SendToVASP          = 1
+)
+
+// TravelRuleBeneficiary holds the beneficiary details common to every jurisdiction
+type TravelRuleBeneficiary struct {
+	IsAddressOwner int    `json:"isAddressOwner"`
+	BnfType        int    `json:"bnfType,omitempty"`
+	BnfName        string `json:"bnfName,omitempty"`
+	BnfCorpName    string `json:"bnfCorpName,omitempty"`
+	Country        string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	City           string `json:"city,omitempty"`
+	SendTo         int    `json:"sendTo"`
+	Vasp           string `json:"vasp,omitempty"`     // VASP code from GetVASPList when SendTo is SendToVASP
+	VaspName       string `json:"vaspName,omitempty"` // Free text when the VASP is not in the list
+}
+
+// WithdrawalQuestionnaire is the questionnaire of a travel rule withdrawal.
+// Use the type matching the country code returned by GetOnboardedEntity, or
+// RawQuestionnaire for a format not modelled here.
+type WithdrawalQuestionnaire interface {
+	withdrawalQuestionnaire()
+}
+
+// BeneficiaryQuestionnaire is the withdrawal questionnaire of the local
+// entities that ask only for the beneficiary and a declaration: the EU
+// entities regulated under the Transfer of Funds Regulation, New Zealand,
+// Bahrain and the UAE
+type BeneficiaryQuestionnaire struct {
+	TravelRuleBeneficiary
+	Declaration bool `json:"declaration"`
+}
+
+func (BeneficiaryQuestionnaire) withdrawalQuestionnaire() {}
+
+// JPWithdrawalQuestionnaire is the questionnaire for Binance Japan, which also
+// requires the purpose of the transfer
+type JPWithdrawalQuestionnaire struct {
+	TravelRuleBeneficiary
+	BnfArea          string `json:"bnfArea,omitempty"`
+	TxnPurpose       string `json:"txnPurpose"`
+	TxnPurposeOthers string `json:"txnPurposeOthers,omitempty"`
+	Declaration      bool   `json:"declaration"`
+}
+
+func (JPWithdrawalQuestionnaire) withdrawalQuestionnaire() {}
+
+// RawQuestionnaire is a questionnaire already encoded as JSON
+type RawQuestionnaire json.RawMessage
+
+// MarshalJSON returns the questionnaire as is
+func (q RawQuestionnaire) MarshalJSON() ([]byte, error) {
+	if q == nil {
+		return []byte("null"), nil
+	}
+	return q, nil
+}
+
+func (RawQuestionnaire) withdrawalQuestionnaire() {}
+func (RawQuestionnaire) depositQuestionnaire()    {}
+
+// LocalEntityWithdrawalRequest submits a withdrawal with travel rule information
+type LocalEntityWithdrawalRequest struct {
+	Coin               string                  `json:"coin"`
+	WithdrawOrderId    string                  `json:"withdrawOrderId,omitempty"`
+	Network            string                  `json:"network,omitempty"`
+	Address            string                  `json:"address"`
+	AddressTag         string                  `json:"addressTag,omitempty"`
+	Amount             string                  `json:"amount"`
+	TransactionFeeFlag bool                    `json:"transactionFeeFlag,omitempty"`
+	Name               string                  `json:"name,omitempty"`
+	WalletType         WalletType              `json:"walletType,omitempty"`
+	Questionnaire      WithdrawalQuestionnaire `json:"questionnaire"`
+	RecvWindow         int64                   `json:"recvWindow,omitempty"`
+}
+
+// LocalEntityWithdrawalResponse is the result of a travel rule withdrawal
+type LocalEntityWithdrawalResponse struct {
+	TrId     int64  `json:"trId"`
+	Accepted bool   `json:"accpted"` // sic, as spelled by the API
+	Info     string `json:"info"`
+}
+
+// LocalEntityWithdrawalHistoryRequest queries withdrawals subject to the travel rule
+type LocalEntityWithdrawalHistoryRequest struct {
+	TrId             string            `json:"trId,omitempty"` // Comma separated travel rule record IDs
+	TxId             string            `json:"txId,omitempty"` // Comma separated transaction IDs
+	WithdrawOrderId  string            `json:"withdrawOrderId,omitempty"`
+	Network          string            `json:"network,omitempty"`
+	Coin             string            `json:"coin,omitempty"`
+	TravelRuleStatus *TravelRuleStatus `json:"travelRuleStatus,omitempty"`
+	StartTime        time.Time         `json:"startTime,omitempty"`
+	EndTime          time.Time         `json:"endTime,omitempty"`
+	Offset           int               `json:"offset,omitempty"`
+	Limit            int               `json:"limit,omitempty"`
+	RecvWindow       int64             `json:"recvWindow,omitempty"`
+}
+
+// LocalEntityWithdrawal is a withdrawal record with its travel rule state
+type LocalEntityWithdrawal struct {
+	Id               string           `json:"id"`
+	TrId             int64            `json:"trId"`
+	Amount           string           `json:"amount"`
+	TransactionFee   string           `json:"transactionFee"`
+	Coin             string           `json:"coin"`
+	WithdrawalStatus WithdrawalStatus `json:"withdrawalStatus"`
+	TravelRuleStatus TravelRuleStatus `json:"travelRuleStatus"`
+	Address          string           `json:"address"`
+	AddressTag       string           `json:"addressTag,omitempty"`
+	TxId             string           `json:"txId"`
+	ApplyTime        string           `json:"applyTime"`
+	Network          string           `json:"network"`
+	TransferType     TransferType     `json:"transferType"`
+	WithdrawOrderId  string           `json:"withdrawOrderId,omitempty"`
+	Info             string           `json:"info,omitempty"`
+	ConfirmNo        int              `json:"confirmNo"`
+	WalletType       WalletType       `json:"walletType"`
+	TxKey            string           `json:"txKey,omitempty"`
+	Questionnaire    string           `json:"questionnaire"`
+	CompleteTime     string           `json:"completeTime,omitempty"`
+}
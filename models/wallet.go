@@ -0,0 +1,42 @@
+package models
+
+import "fmt"
+
+// TransferType indicates whether a deposit or withdrawal moved funds on-chain
+// or between Binance accounts
+type TransferType int
+
+const (
+	TransferTypeExternal TransferType = 0
+	TransferTypeInternal TransferType = 1
+)
+
+func (t TransferType) String() string {
+	switch t {
+	case TransferTypeExternal:
+		return "External"
+	case TransferTypeInternal:
+		return "Internal"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(t))
+	}
+}
+
+// WalletType identifies the wallet a deposit is credited to or a withdrawal is paid from
+type WalletType int
+
+const (
+	WalletTypeSpot    WalletType = 0
+	WalletTypeFunding WalletType = 1
+)
+
+func (t WalletType) String() string {
+	switch t {
+	case WalletTypeSpot:
+		return "Spot"
+	case WalletTypeFunding:
+		return "Funding"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(t))
+	}
+}
@@ -1,53 +1,108 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// WithdrawalStatus represents the status of a withdrawal
+type WithdrawalStatus int
+
+const (
+	WithdrawalStatusEmailSent        WithdrawalStatus = 0
+	WithdrawalStatusCancelled        WithdrawalStatus = 1
+	WithdrawalStatusAwaitingApproval WithdrawalStatus = 2
+	WithdrawalStatusRejected         WithdrawalStatus = 3
+	WithdrawalStatusProcessing       WithdrawalStatus = 4
+	WithdrawalStatusFailure          WithdrawalStatus = 5
+	WithdrawalStatusCompleted        WithdrawalStatus = 6
+)
+
+func (s WithdrawalStatus) String() string {
+	switch s {
+	case WithdrawalStatusEmailSent:
+		return "Email Sent"
+	case WithdrawalStatusCancelled:
+		return "Cancelled"
+	case WithdrawalStatusAwaitingApproval:
+		return "Awaiting Approval"
+	case WithdrawalStatusRejected:
+		return "Rejected"
+	case WithdrawalStatusProcessing:
+		return "Processing"
+	case WithdrawalStatusFailure:
+		return "Failed"
+	case WithdrawalStatusCompleted:
+		return "Completed"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(s))
+	}
+}
+
+// IsTerminal reports whether the withdrawal will not change status any more
+func (s WithdrawalStatus) IsTerminal() bool {
+	switch s {
+	case WithdrawalStatusCancelled, WithdrawalStatusRejected, WithdrawalStatusFailure, WithdrawalStatusCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsSuccess reports whether the withdrawal has completed
+func (s WithdrawalStatus) IsSuccess() bool {
+	return s == WithdrawalStatusCompleted
+}
 
 type WithdrawalRequest struct {
-	Coin              string  `json:"coin"`
-	Network           string  `json:"network,omitempty"`
-	Address           string  `json:"address"`
-	AddressTag        string  `json:"addressTag,omitempty"`
-	Amount            string  `json:"amount"`
-	WithdrawOrderId   string  `json:"withdrawOrderId,omitempty"`
-	TransactionFeeFlag bool    `json:"transactionFeeFlag,omitempty"`
-	Name              string  `json:"name,omitempty"`
-	WalletType        int     `json:"walletType,omitempty"`
-	RecvWindow        int64   `json:"recvWindow,omitempty"`
+	Coin               string     `json:"coin"`
+	Network            string     `json:"network,omitempty"`
+	Address            string     `json:"address"`
+	AddressTag         string     `json:"addressTag,omitempty"`
+	Amount             string     `json:"amount"`
+	WithdrawOrderId    string     `json:"withdrawOrderId,omitempty"`
+	TransactionFeeFlag bool       `json:"transactionFeeFlag,omitempty"`
+	Name               string     `json:"name,omitempty"`
+	WalletType         WalletType `json:"walletType,omitempty"`
+	RecvWindow         int64      `json:"recvWindow,omitempty"`
 }
 
 type WithdrawalResponse struct {
 	Id string `json:"id"`
 }
 
+// WithdrawalTimeLayout is the layout of the withdrawal apply and complete times, in UTC
+const WithdrawalTimeLayout = "2006-01-02 15:04:05"
+
 type WithdrawalHistory struct {
-	Id              string    `json:"id"`
-	Amount          string    `json:"amount"`
-	TransactionFee  string    `json:"transactionFee"`
-	Coin            string    `json:"coin"`
-	Status          int       `json:"status"`
-	Address         string    `json:"address"`
-	TxId            string    `json:"txId"`
-	ApplyTime       string    `json:"applyTime"`
-	Network         string    `json:"network"`
-	TransferType    int       `json:"transferType"`
-	WithdrawOrderId string    `json:"withdrawOrderId,omitempty"`
-	Info            string    `json:"info,omitempty"`
-	ConfirmNo       int       `json:"confirmNo"`
-	WalletType      int       `json:"walletType"`
-	TxKey           string    `json:"txKey,omitempty"`
-	CompleteTime    string    `json:"completeTime,omitempty"`
+	Id              string           `json:"id"`
+	Amount          string           `json:"amount"`
+	TransactionFee  string           `json:"transactionFee"`
+	Coin            string           `json:"coin"`
+	Status          WithdrawalStatus `json:"status"`
+	Address         string           `json:"address"`
+	TxId            string           `json:"txId"`
+	ApplyTime       string           `json:"applyTime"`
+	Network         string           `json:"network"`
+	TransferType    TransferType     `json:"transferType"`
+	WithdrawOrderId string           `json:"withdrawOrderId,omitempty"`
+	Info            string           `json:"info,omitempty"`
+	ConfirmNo       int              `json:"confirmNo"`
+	WalletType      WalletType       `json:"walletType"`
+	TxKey           string           `json:"txKey,omitempty"`
+	CompleteTime    string           `json:"completeTime,omitempty"`
 }
 
 type WithdrawalHistoryRequest struct {
-	Coin            string    `json:"coin,omitempty"`
-	WithdrawOrderId string    `json:"withdrawOrderId,omitempty"`
-	Status          int       `json:"status,omitempty"`
-	StartTime       time.Time `json:"startTime,omitempty"`
-	EndTime         time.Time `json:"endTime,omitempty"`
-	Offset          int       `json:"offset,omitempty"`
-	Limit           int       `json:"limit,omitempty"`
-	IdList          string    `json:"idList,omitempty"` // 提现ID列表，以逗号分隔，最大支持45个
-	RecvWindow      int64     `json:"recvWindow,omitempty"`
+	Coin            string            `json:"coin,omitempty"`
+	WithdrawOrderId string            `json:"withdrawOrderId,omitempty"`
+	Status          *WithdrawalStatus `json:"status,omitempty"` // nil returns withdrawals of every status
+	StartTime       time.Time         `json:"startTime,omitempty"`
+	EndTime         time.Time         `json:"endTime,omitempty"`
+	Offset          int               `json:"offset,omitempty"`
+	Limit           int               `json:"limit,omitempty"`
+	IdList          string            `json:"idList,omitempty"` // 提现ID列表，以逗号分隔，最大支持45个
+	RecvWindow      int64             `json:"recvWindow,omitempty"`
 }
 
 // WithdrawalQuota - 24小时提现限额信息
@@ -59,6 +114,7 @@ type WithdrawalQuota struct {
 // WithdrawalAddress - 提现地址信息
 type WithdrawalAddress struct {
 	Address      string `json:"address"`
+	AddressTag   string `json:"addressTag"`
 	Coin         string `json:"coin"`
 	Name         string `json:"name"`
 	Network      string `json:"network"`
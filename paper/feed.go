@@ -0,0 +1,80 @@
+package paper
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// ErrNoPrice is returned when the feed has no price for a symbol
+var ErrNoPrice = errors.New("no price for symbol")
+
+// PriceFeed returns the latest price of a symbol
+type PriceFeed interface {
+	Price(symbol string) (*big.Rat, error)
+}
+
+// StaticFeed is a PriceFeed whose prices are set by the caller, for example
+// from a websocket stream or a historical replay
+type StaticFeed struct {
+	mu     sync.RWMutex
+	prices map[string]*big.Rat
+}
+
+// NewStaticFeed creates a feed with no prices
+func NewStaticFeed() *StaticFeed {
+	return &StaticFeed{prices: make(map[string]*big.Rat)}
+}
+
+// Set sets the price of a symbol
+func (f *StaticFeed) Set(symbol, price string) error {
+	p, err := utils.ParseDecimal(price)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prices[symbol] = p
+	return nil
+}
+
+// Price returns the last price set for symbol
+func (f *StaticFeed) Price(symbol string) (*big.Rat, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	p, ok := f.prices[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrNoPrice, symbol)
+	}
+	return new(big.Rat).Set(p), nil
+}
+
+// TickerFeed is a PriceFeed that fetches the latest price from the market,
+// so strategies can be dry-run against live prices
+type TickerFeed struct {
+	market *endpoints.MarketDataService
+}
+
+// NewTickerFeed creates a feed using market
+func NewTickerFeed(market *endpoints.MarketDataService) *TickerFeed {
+	return &TickerFeed{market: market}
+}
+
+// Price fetches the latest price of symbol
+func (f *TickerFeed) Price(symbol string) (*big.Rat, error) {
+	prices, err := f.market.GetTickerPrices(models.TickerPriceRequest{Symbol: symbol})
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("%w %s", ErrNoPrice, symbol)
+	}
+	return utils.ParseDecimal(prices[0].Price)
+}
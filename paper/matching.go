@@ -0,0 +1,78 @@
+package paper
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/MartianPay/go-binance/internal/matching"
+	"github.com/MartianPay/go-binance/models"
+)
+
+var (
+	errInvalidSymbol = toAPIError(matching.ErrInvalidSymbol)
+	errUnknownOrder  = toAPIError(matching.ErrUnknownOrder)
+	errOrderNotExist = toAPIError(matching.ErrOrderNotExist)
+)
+
+// newBook creates the order book of an engine, settling against its balances;
+// the engine's lock must be held while the book is used
+func (e *Engine) newBook() *matching.Book {
+	return &matching.Book{
+		Symbol: func(symbol string) (models.SymbolInfo, bool) {
+			info, ok := e.symbols[symbol]
+			return info, ok
+		},
+		Balance: func(asset string) (*big.Rat, *big.Rat) {
+			b := e.balance(asset)
+			return b.free, b.locked
+		},
+		Fee: func(maker bool) *big.Rat {
+			if maker {
+				return e.maker
+			}
+			return e.taker
+		},
+		Now: func() int64 { return e.now().UnixMilli() },
+		NewId: func() int64 {
+			e.nextId++
+			return e.nextId
+		},
+		IdPrefix: "paper",
+	}
+}
+
+// toAPIError converts a matching error into the error Binance returns
+func toAPIError(err error) error {
+	var me *matching.Error
+	if errors.As(err, &me) {
+		return apiError(me.Code, me.Msg)
+	}
+	return err
+}
+
+// parseOrder validates a new order the way Binance does; the lock must be held
+func (e *Engine) parseOrder(req models.NewOrderRequest) (*matching.Order, error) {
+	o, err := e.book.Parse(req)
+	return o, toAPIError(err)
+}
+
+// place reserves the balance an order needs and fills it if it is marketable
+// at market; the lock must be held
+func (e *Engine) place(o *matching.Order, market *big.Rat) ([]models.OrderFill, error) {
+	fills, err := e.book.Place(o, market)
+	return fills, toAPIError(err)
+}
+
+// onPrice records the price of symbol, triggers stop orders and fills the
+// resting orders it reaches; the lock must be held
+func (e *Engine) onPrice(symbol string, price *big.Rat) {
+	e.prices[symbol] = price
+	e.book.OnPrice(symbol, price)
+}
+
+// findOrder looks an order up by orderId or origClientOrderId. It returns nil
+// without an error when no order matches.
+func (e *Engine) findOrder(symbol string, orderId int64, clientOrderId string) (*matching.Order, error) {
+	o, err := e.book.Find(symbol, orderId, clientOrderId)
+	return o, toAPIError(err)
+}
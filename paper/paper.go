@@ -0,0 +1,407 @@
+// Package paper simulates spot trading locally. An Engine exposes the same
+// methods as endpoints.TradingService, matches orders against a price feed
+// with virtual balances and fees, and returns the errors Binance would, so a
+// strategy written against the Trader interface can be dry-run in production
+// without risking funds.
+package paper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MartianPay/go-binance/client"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/internal/matching"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// Trader is the trading API shared by endpoints.TradingService and Engine
+type Trader interface {
+	NewOrder(req models.NewOrderRequest) (*models.OrderResponse, error)
+	TestNewOrder(req models.NewOrderRequest) error
+	QueryOrder(req models.QueryOrderRequest) (*models.Order, error)
+	CancelOrder(req models.CancelOrderRequest) (*models.CancelOrderResponse, error)
+	CancelAllOpenOrders(symbol string, recvWindow int64) ([]models.CancelOrderResponse, error)
+	GetOpenOrders(req models.OpenOrdersRequest) ([]models.Order, error)
+	GetAllOrders(req models.AllOrdersRequest) ([]models.Order, error)
+	GetMyTrades(req models.MyTradesRequest) ([]models.Trade, error)
+	GetAccountInfo(recvWindow int64) (*models.TradingAccountInfo, error)
+}
+
+var (
+	_ Trader = (*endpoints.TradingService)(nil)
+	_ Trader = (*Engine)(nil)
+)
+
+// Options configure an Engine
+type Options struct {
+	MakerFee string // Commission rate of resting orders; default "0.001"
+	TakerFee string // Commission rate of orders that fill on arrival; default "0.001"
+	// Now is the clock used for order and trade times; time.Now when nil
+	Now func() time.Time
+}
+
+type balance struct {
+	free   *big.Rat
+	locked *big.Rat
+}
+
+// Engine is a simulated spot account. It is safe for concurrent use.
+type Engine struct {
+	feed    PriceFeed
+	symbols map[string]models.SymbolInfo
+	now     func() time.Time
+
+	mu       sync.Mutex
+	maker    *big.Rat
+	taker    *big.Rat
+	balances map[string]*balance
+	prices   map[string]*big.Rat
+	book     *matching.Book
+	nextId   int64
+}
+
+// NewEngine creates an engine trading symbols, such as those returned by
+// GetExchangeInfo, at prices from feed. Balances start empty.
+func NewEngine(symbols []models.SymbolInfo, feed PriceFeed, opts Options) (*Engine, error) {
+	e := &Engine{
+		feed:     feed,
+		symbols:  make(map[string]models.SymbolInfo, len(symbols)),
+		now:      opts.Now,
+		balances: make(map[string]*balance),
+		prices:   make(map[string]*big.Rat),
+	}
+	if e.now == nil {
+		e.now = time.Now
+	}
+	e.book = e.newBook()
+	for _, s := range symbols {
+		e.symbols[s.Symbol] = s
+	}
+
+	var err error
+	if e.maker, err = feeRate(opts.MakerFee); err != nil {
+		return nil, fmt.Errorf("invalid maker fee: %w", err)
+	}
+	if e.taker, err = feeRate(opts.TakerFee); err != nil {
+		return nil, fmt.Errorf("invalid taker fee: %w", err)
+	}
+
+	return e, nil
+}
+
+func feeRate(s string) (*big.Rat, error) {
+	if s == "" {
+		return big.NewRat(1, 1000), nil
+	}
+	return utils.ParseDecimal(s)
+}
+
+// SetBalance sets the free balance of an asset
+func (e *Engine) SetBalance(asset, free string) error {
+	amount, err := utils.ParseDecimal(free)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.balance(asset).free = amount
+	return nil
+}
+
+// Balance returns the balance of an asset
+func (e *Engine) Balance(asset string) models.Balance {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b := e.balance(asset)
+	return models.Balance{Asset: asset, Free: matching.Format(b.free), Locked: matching.Format(b.locked)}
+}
+
+func (e *Engine) balance(asset string) *balance {
+	b, ok := e.balances[asset]
+	if !ok {
+		b = &balance{free: new(big.Rat), locked: new(big.Rat)}
+		e.balances[asset] = b
+	}
+	return b
+}
+
+// apiError builds the error Binance returns, so callers can handle simulated
+// and live errors alike with errors.As(err, **client.APIError)
+func apiError(code int, msg string) *client.APIError {
+	body, _ := json.Marshal(struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}{code, msg})
+	return &client.APIError{StatusCode: http.StatusBadRequest, Code: code, Message: msg, Body: string(body)}
+}
+
+// OnPrice applies a new price of a symbol, triggering stop orders and filling
+// resting orders it reaches. Use it to drive the engine from a price stream.
+func (e *Engine) OnPrice(symbol, price string) error {
+	p, err := utils.ParseDecimal(price)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onPrice(symbol, p)
+	return nil
+}
+
+// Refresh fetches the price of every symbol with open orders from the feed and
+// applies it
+func (e *Engine) Refresh() error {
+	e.mu.Lock()
+	symbols := make(map[string]bool)
+	for _, o := range e.book.Orders {
+		if !o.Status.IsTerminal() {
+			symbols[o.Symbol] = true
+		}
+	}
+	e.mu.Unlock()
+
+	for symbol := range symbols {
+		price, err := e.feed.Price(symbol)
+		if err != nil {
+			return fmt.Errorf("failed to get price of %s: %w", symbol, err)
+		}
+
+		e.mu.Lock()
+		e.onPrice(symbol, price)
+		e.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Run calls Refresh every interval until ctx is done, so resting and stop
+// orders fill as the market moves. Refresh errors are passed to onError, which
+// may be nil.
+func (e *Engine) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := e.Refresh(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// NewOrder places an order at the current feed price. MARKET orders and
+// marketable LIMIT orders fill immediately; others rest until a price reaches them.
+func (e *Engine) NewOrder(req models.NewOrderRequest) (*models.OrderResponse, error) {
+	price, err := e.feed.Price(req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new order: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o, err := e.parseOrder(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new order: %w", err)
+	}
+
+	// Resting orders see the new price before the new order does
+	e.onPrice(req.Symbol, price)
+
+	fills, err := e.place(o, price)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new order: %w", err)
+	}
+
+	return o.Response(matching.RespType(req), fills), nil
+}
+
+// TestNewOrder validates an order like NewOrder without placing it
+func (e *Engine) TestNewOrder(req models.NewOrderRequest) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.parseOrder(req); err != nil {
+		return fmt.Errorf("failed to test new order: %w", err)
+	}
+	return nil
+}
+
+// QueryOrder checks an order's status
+func (e *Engine) QueryOrder(req models.QueryOrderRequest) (*models.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o, err := e.findOrder(req.Symbol, req.OrderId, req.OrigClientOrderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order: %w", err)
+	}
+	if o == nil {
+		return nil, fmt.Errorf("failed to query order: %w", errOrderNotExist)
+	}
+
+	order := o.Order
+	return &order, nil
+}
+
+// CancelOrder cancels an open order and releases its locked balance
+func (e *Engine) CancelOrder(req models.CancelOrderRequest) (*models.CancelOrderResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o, err := e.findOrder(req.Symbol, req.OrderId, req.OrigClientOrderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	if o == nil || o.Status.IsTerminal() {
+		return nil, fmt.Errorf("failed to cancel order: %w", errUnknownOrder)
+	}
+
+	result := e.book.Cancel(o, req.NewClientOrderId)
+	return &result, nil
+}
+
+// CancelAllOpenOrders cancels all open orders on a symbol
+func (e *Engine) CancelAllOpenOrders(symbol string, recvWindow int64) ([]models.CancelOrderResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.symbols[symbol]; !ok {
+		return nil, fmt.Errorf("failed to cancel all open orders: %w", errInvalidSymbol)
+	}
+
+	var results []models.CancelOrderResponse
+	for _, o := range e.book.Orders {
+		if o.Symbol == symbol && !o.Status.IsTerminal() {
+			results = append(results, e.book.Cancel(o, ""))
+		}
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("failed to cancel all open orders: %w", errUnknownOrder)
+	}
+
+	return results, nil
+}
+
+// GetOpenOrders gets all open orders, or those of req.Symbol
+func (e *Engine) GetOpenOrders(req models.OpenOrdersRequest) ([]models.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.symbols[req.Symbol]; req.Symbol != "" && !ok {
+		return nil, fmt.Errorf("failed to get open orders: %w", errInvalidSymbol)
+	}
+
+	orders := []models.Order{}
+	for _, o := range e.book.Orders {
+		if (req.Symbol == "" || o.Symbol == req.Symbol) && !o.Status.IsTerminal() {
+			orders = append(orders, o.Order)
+		}
+	}
+	return orders, nil
+}
+
+// GetAllOrders gets all orders of a symbol, with the same orderId, time and
+// limit semantics as the API
+func (e *Engine) GetAllOrders(req models.AllOrdersRequest) ([]models.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.symbols[req.Symbol]; !ok {
+		return nil, fmt.Errorf("failed to get all orders: %w", errInvalidSymbol)
+	}
+
+	orders := []models.Order{}
+	for _, o := range e.book.Orders {
+		if o.Symbol == req.Symbol && o.OrderId >= req.OrderId && inRange(o.Time, req.StartTime, req.EndTime) {
+			orders = append(orders, o.Order)
+		}
+	}
+	return limit(orders, req.OrderId == 0, req.Limit), nil
+}
+
+// GetMyTrades gets the simulated trades of a symbol
+func (e *Engine) GetMyTrades(req models.MyTradesRequest) ([]models.Trade, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.symbols[req.Symbol]; !ok {
+		return nil, fmt.Errorf("failed to get my trades: %w", errInvalidSymbol)
+	}
+
+	trades := []models.Trade{}
+	for _, t := range e.book.Trades {
+		if t.Symbol == req.Symbol && (req.OrderId == 0 || t.OrderId == req.OrderId) &&
+			t.Id >= req.FromId && inRange(t.Time, req.StartTime, req.EndTime) {
+			trades = append(trades, t)
+		}
+	}
+	return limit(trades, req.FromId == 0, req.Limit), nil
+}
+
+// GetAccountInfo gets the virtual balances, with commissions in basis points
+func (e *Engine) GetAccountInfo(recvWindow int64) (*models.TradingAccountInfo, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info := &models.TradingAccountInfo{
+		MakerCommission: basisPoints(e.maker),
+		TakerCommission: basisPoints(e.taker),
+		CanTrade:        true,
+		UpdateTime:      e.now().UnixMilli(),
+		AccountType:     "SPOT",
+		Balances:        []models.Balance{},
+		Permissions:     []string{"SPOT"},
+	}
+
+	assets := make([]string, 0, len(e.balances))
+	for asset := range e.balances {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	for _, asset := range assets {
+		b := e.balances[asset]
+		info.Balances = append(info.Balances, models.Balance{Asset: asset, Free: matching.Format(b.free), Locked: matching.Format(b.locked)})
+	}
+
+	return info, nil
+}
+
+func basisPoints(rate *big.Rat) int64 {
+	bps := new(big.Rat).Mul(rate, big.NewRat(10000, 1))
+	return new(big.Int).Quo(bps.Num(), bps.Denom()).Int64()
+}
+
+func inRange(ms int64, start, end time.Time) bool {
+	return (start.IsZero() || ms >= start.UnixMilli()) && (end.IsZero() || ms <= end.UnixMilli())
+}
+
+// limit applies the API's limit (default 500, max 1000) to items in ascending
+// order, keeping the most recent items when fromEnd is set and the oldest otherwise
+func limit[T any](items []T, fromEnd bool, n int) []T {
+	if n <= 0 || n > 1000 {
+		n = 500
+	}
+	if len(items) <= n {
+		return items
+	}
+	if fromEnd {
+		return items[len(items)-n:]
+	}
+	return items[:n]
+}
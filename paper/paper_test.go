@@ -0,0 +1,196 @@
+package paper_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MartianPay/go-binance/client"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/paper"
+)
+
+var btcusdt = models.SymbolInfo{
+	Symbol:     "BTCUSDT",
+	Status:     "TRADING",
+	BaseAsset:  "BTC",
+	QuoteAsset: "USDT",
+	Filters: []map[string]interface{}{
+		{"filterType": "PRICE_FILTER", "minPrice": "0.01", "maxPrice": "1000000.00", "tickSize": "0.01"},
+		{"filterType": "LOT_SIZE", "minQty": "0.00001", "maxQty": "9000.00000", "stepSize": "0.00001"},
+		{"filterType": "NOTIONAL", "minNotional": "5.00", "maxNotional": "9000000.00"},
+	},
+}
+
+func newEngine(t *testing.T, balances map[string]string) *paper.Engine {
+	t.Helper()
+	feed := paper.NewStaticFeed()
+	if err := feed.Set("BTCUSDT", "50000"); err != nil {
+		t.Fatal(err)
+	}
+	e, err := paper.NewEngine([]models.SymbolInfo{btcusdt}, feed, paper.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for asset, free := range balances {
+		if err := e.SetBalance(asset, free); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e
+}
+
+func TestOrders(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances map[string]string
+		req      models.NewOrderRequest
+		// price, when set, is applied with OnPrice after the order is placed
+		price        string
+		wantCode     int
+		wantStatus   models.OrderStatus
+		wantBalances map[string]string
+	}{
+		{
+			name:         "market buy",
+			balances:     map[string]string{"USDT": "10000"},
+			req:          models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "0.1"},
+			wantStatus:   models.OrderStatusFilled,
+			wantBalances: map[string]string{"USDT": "5000.00000000", "BTC": "0.09990000"},
+		},
+		{
+			name:         "market buy by quote quantity",
+			balances:     map[string]string{"USDT": "1000"},
+			req:          models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeMarket, QuoteOrderQty: "1000"},
+			wantStatus:   models.OrderStatusFilled,
+			wantBalances: map[string]string{"USDT": "0.00000000", "BTC": "0.01998000"},
+		},
+		{
+			name:         "resting limit sell fills at its price",
+			balances:     map[string]string{"BTC": "1"},
+			req:          models.NewOrderRequest{Side: models.SideSell, Type: models.OrderTypeLimit, TimeInForce: models.TimeInForceGTC, Quantity: "1", Price: "51000"},
+			price:        "52000",
+			wantStatus:   models.OrderStatusFilled,
+			wantBalances: map[string]string{"BTC": "0.00000000", "USDT": "50949.00000000"},
+		},
+		{
+			name:         "stop loss buy triggered above its stop price fills from the free balance",
+			balances:     map[string]string{"USDT": "60000"},
+			req:          models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeStopLoss, Quantity: "1", StopPrice: "51000"},
+			price:        "52000",
+			wantStatus:   models.OrderStatusFilled,
+			wantBalances: map[string]string{"USDT": "8000.00000000", "BTC": "0.99900000"},
+		},
+		{
+			name:         "stop loss buy the balance cannot cover is rejected",
+			balances:     map[string]string{"USDT": "51000"},
+			req:          models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeStopLoss, Quantity: "1", StopPrice: "51000"},
+			price:        "52000",
+			wantStatus:   models.OrderStatusRejected,
+			wantBalances: map[string]string{"USDT": "51000.00000000", "BTC": "0.00000000"},
+		},
+		{
+			name:       "immediate or cancel limit that does not cross expires",
+			balances:   map[string]string{"USDT": "10000"},
+			req:        models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeLimit, TimeInForce: models.TimeInForceIOC, Quantity: "0.1", Price: "49000"},
+			wantStatus: models.OrderStatusExpired,
+		},
+		{
+			name:     "lot size",
+			balances: map[string]string{"USDT": "10000"},
+			req:      models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "0.000001"},
+			wantCode: -1013,
+		},
+		{
+			name:     "limit maker that would take",
+			balances: map[string]string{"USDT": "10000"},
+			req:      models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeLimitMaker, Quantity: "0.1", Price: "51000"},
+			wantCode: -2010,
+		},
+		{
+			name:     "stop that would trigger immediately",
+			balances: map[string]string{"BTC": "1"},
+			req:      models.NewOrderRequest{Side: models.SideSell, Type: models.OrderTypeStopLoss, Quantity: "1", StopPrice: "51000"},
+			wantCode: -2010,
+		},
+		{
+			name:     "price on a market order",
+			balances: map[string]string{"USDT": "10000"},
+			req:      models.NewOrderRequest{Side: models.SideBuy, Type: models.OrderTypeMarket, Quantity: "0.1", Price: "50000"},
+			wantCode: -1106,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(t, tt.balances)
+			tt.req.Symbol = "BTCUSDT"
+
+			resp, err := e.NewOrder(tt.req)
+			if tt.wantCode != 0 {
+				var apiErr *client.APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("expected an API error, got %v", err)
+				}
+				if apiErr.Code != tt.wantCode {
+					t.Errorf("expected code %d, got %d", tt.wantCode, apiErr.Code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewOrder: %v", err)
+			}
+
+			if tt.price != "" {
+				if err := e.OnPrice("BTCUSDT", tt.price); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			order, err := e.QueryOrder(models.QueryOrderRequest{Symbol: "BTCUSDT", OrderId: resp.OrderId})
+			if err != nil {
+				t.Fatalf("QueryOrder: %v", err)
+			}
+			if order.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, order.Status)
+			}
+
+			for asset, want := range tt.wantBalances {
+				b := e.Balance(asset)
+				if b.Free != want || b.Locked != "0.00000000" {
+					t.Errorf("%s: expected %s free and nothing locked, got %s free and %s locked", asset, want, b.Free, b.Locked)
+				}
+			}
+		})
+	}
+}
+
+func TestDuplicateClientOrderId(t *testing.T) {
+	e := newEngine(t, map[string]string{"USDT": "10000"})
+	req := models.NewOrderRequest{
+		Symbol:           "BTCUSDT",
+		Side:             models.SideBuy,
+		Type:             models.OrderTypeLimit,
+		TimeInForce:      models.TimeInForceGTC,
+		Quantity:         "0.01",
+		Price:            "40000",
+		NewClientOrderId: "strategy-1",
+	}
+
+	if _, err := e.NewOrder(req); err != nil {
+		t.Fatalf("NewOrder: %v", err)
+	}
+
+	_, err := e.NewOrder(req)
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != -2010 {
+		t.Fatalf("expected a duplicate order error, got %v", err)
+	}
+
+	// The ID can be reused once the first order is closed
+	if _, err := e.CancelOrder(models.CancelOrderRequest{Symbol: "BTCUSDT", OrigClientOrderId: "strategy-1"}); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if _, err := e.NewOrder(req); err != nil {
+		t.Fatalf("NewOrder after cancel: %v", err)
+	}
+}
@@ -0,0 +1,378 @@
+// Package pnl computes cost basis and realized and unrealized profit and loss
+// from spot trade fills, with lot-level detail for tax reporting.
+package pnl
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// Method selects the lots a disposal is matched against
+type Method string
+
+const (
+	// FIFO disposes of the oldest lots first
+	FIFO Method = "FIFO"
+	// LIFO disposes of the newest lots first
+	LIFO Method = "LIFO"
+	// AverageCost pools all acquisitions of an asset into one lot at the weighted average cost
+	AverageCost Method = "AVERAGE_COST"
+)
+
+// Lot is a quantity of an asset acquired at one time. Under AverageCost there
+// is a single pooled lot per asset.
+type Lot struct {
+	Asset string
+	// TradeId is the acquiring trade, or 0 for a pooled or opening lot
+	TradeId  int64
+	Time     time.Time
+	Quantity *big.Rat
+	// UnitCost is the cost of one unit in the report currency, including fees
+	UnitCost *big.Rat
+}
+
+// Disposal is the part of a disposal matched against one lot. Both legs of a
+// trade count: selling BTC for ETH disposes of BTC, and buying BTC with ETH
+// disposes of ETH.
+type Disposal struct {
+	Asset string
+	// Symbol and TradeId identify the disposing trade
+	Symbol     string
+	TradeId    int64
+	Time       time.Time
+	LotTradeId int64
+	Acquired   time.Time
+	Quantity   *big.Rat
+	// CostBasis, Proceeds and PnL are in the report currency; proceeds are net of fees
+	CostBasis *big.Rat
+	Proceeds  *big.Rat
+	PnL       *big.Rat
+	// Unmatched is set when the disposal exceeded the tracked holdings; such
+	// quantities have a zero cost basis
+	Unmatched bool
+}
+
+// AssetPnL is the result for one asset, in the report currency
+type AssetPnL struct {
+	Asset string
+	// Quantity and CostBasis describe the holdings still open
+	Quantity  *big.Rat
+	CostBasis *big.Rat
+	MarkPrice *big.Rat
+	Realized  *big.Rat
+	// Unrealized is Quantity at MarkPrice minus CostBasis; nil if no mark price was available
+	Unrealized *big.Rat
+	OpenLots   []Lot
+	Disposals  []Disposal
+}
+
+// Report is the PnL of every asset traded, except the report currency itself
+type Report struct {
+	Method   Method
+	Currency string
+	Time     time.Time
+	Assets   []AssetPnL
+	// Fees is the total commission converted into Currency at each trade's time
+	Fees *big.Rat
+	// Errors lists the assets that could not be valued at Time
+	Errors []error
+}
+
+// pool tracks the lots of one asset
+type pool struct {
+	lots      []*Lot
+	realized  *big.Rat
+	disposals []Disposal
+}
+
+// Engine accumulates trades and reports PnL
+type Engine struct {
+	method   Method
+	currency string
+	prices   PriceSource
+	symbols  map[string]models.SymbolInfo
+	pools    map[string]*pool
+	fees     *big.Rat
+}
+
+// NewEngine creates an engine. symbols maps each traded symbol to its base and
+// quote asset, prices values each trade and the open holdings, and currency is
+// the asset costs and PnL are reported in, such as USDT.
+func NewEngine(method Method, symbols []models.SymbolInfo, prices PriceSource, currency string) *Engine {
+	e := &Engine{
+		method:   method,
+		currency: currency,
+		prices:   prices,
+		symbols:  make(map[string]models.SymbolInfo, len(symbols)),
+		pools:    make(map[string]*pool),
+		fees:     new(big.Rat),
+	}
+	for _, s := range symbols {
+		e.symbols[s.Symbol] = s
+	}
+	return e
+}
+
+func (e *Engine) pool(asset string) *pool {
+	p, ok := e.pools[asset]
+	if !ok {
+		p = &pool{realized: new(big.Rat)}
+		e.pools[asset] = p
+	}
+	return p
+}
+
+// AddOpeningLot records holdings acquired before the first trade, such as a
+// deposit, so later disposals are matched against a known cost. unitCost is in
+// the report currency.
+func (e *Engine) AddOpeningLot(asset string, quantity, unitCost string, acquired time.Time) error {
+	if asset == e.currency {
+		return fmt.Errorf("%s is the report currency and has no cost basis", asset)
+	}
+	var p utils.DecimalParser
+	qty, cost := p.Parse(quantity), p.Parse(unitCost)
+	if p.Err != nil {
+		return p.Err
+	}
+	if qty.Sign() <= 0 {
+		return fmt.Errorf("opening lot quantity %s is not positive", quantity)
+	}
+	e.addLot(&Lot{Asset: asset, Time: acquired, Quantity: qty, UnitCost: cost})
+	return nil
+}
+
+// AddTrades applies trades in time order. Calls must be made in chronological
+// order; trades within a call may be in any order.
+func (e *Engine) AddTrades(trades []models.Trade) error {
+	sorted := append([]models.Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Time != sorted[j].Time {
+			return sorted[i].Time < sorted[j].Time
+		}
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	for _, t := range sorted {
+		if err := e.addTrade(t); err != nil {
+			return fmt.Errorf("trade %s:%d: %w", t.Symbol, t.Id, err)
+		}
+	}
+	return nil
+}
+
+// addTrade records the asset given up as a disposal and the asset received as
+// an acquisition, both valued in the report currency at the trade time
+func (e *Engine) addTrade(t models.Trade) error {
+	info, ok := e.symbols[t.Symbol]
+	if !ok {
+		return fmt.Errorf("unknown symbol %s", t.Symbol)
+	}
+
+	at := time.UnixMilli(t.Time)
+	var p utils.DecimalParser
+	price, qty, quoteQty, commission := p.Parse(t.Price), p.Parse(t.Qty), p.Parse(t.QuoteQty), p.Parse(t.Commission)
+	if p.Err != nil {
+		return fmt.Errorf("invalid amount: %w", p.Err)
+	}
+	base, quote := info.BaseAsset, info.QuoteAsset
+
+	rate, err := e.prices.Price(quote, e.currency, at)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s into %s: %w", quote, e.currency, err)
+	}
+	value := new(big.Rat).Mul(quoteQty, rate)
+
+	// Commission in the base or quote asset changes the quantity received or
+	// given up; any other asset (usually BNB) is disposed of at its value
+	baseQty, quoteLeg := new(big.Rat).Set(qty), new(big.Rat).Set(quoteQty)
+	fee := new(big.Rat)
+	if commission.Sign() != 0 {
+		switch t.CommissionAsset {
+		case quote:
+			if t.IsBuyer {
+				quoteLeg.Add(quoteLeg, commission)
+			} else {
+				quoteLeg.Sub(quoteLeg, commission)
+			}
+			fee.Mul(commission, rate)
+		case base:
+			if t.IsBuyer {
+				baseQty.Sub(baseQty, commission)
+			} else {
+				baseQty.Add(baseQty, commission)
+			}
+			fee.Mul(commission, price)
+			fee.Mul(fee, rate)
+		default:
+			feeRate, err := e.prices.Price(t.CommissionAsset, e.currency, at)
+			if err != nil {
+				return fmt.Errorf("failed to convert %s commission into %s: %w", t.CommissionAsset, e.currency, err)
+			}
+			fee.Mul(commission, feeRate)
+		}
+	}
+
+	if t.IsBuyer && baseQty.Sign() <= 0 {
+		return fmt.Errorf("bought %s %s but received nothing after the %s %s commission", t.Qty, base, t.Commission, t.CommissionAsset)
+	}
+	if !t.IsBuyer && quoteLeg.Sign() <= 0 {
+		return fmt.Errorf("sold %s %s but received nothing after the %s %s commission", t.Qty, base, t.Commission, t.CommissionAsset)
+	}
+
+	e.fees.Add(e.fees, fee)
+	if t.CommissionAsset != base && t.CommissionAsset != quote {
+		e.dispose(t.CommissionAsset, t, at, commission, fee)
+	}
+
+	// Fees add to the cost of a purchase and reduce the proceeds of a sale,
+	// except base asset commission, which the quantity already reflects
+	if t.IsBuyer {
+		cost := new(big.Rat).Set(value)
+		if t.CommissionAsset != base {
+			cost.Add(cost, fee)
+		}
+		e.dispose(quote, t, at, quoteLeg, new(big.Rat).Mul(quoteLeg, rate))
+		e.acquire(base, t, at, baseQty, cost)
+		return nil
+	}
+
+	proceeds := new(big.Rat).Set(value)
+	if t.CommissionAsset != base {
+		proceeds.Sub(proceeds, fee)
+	}
+	e.dispose(base, t, at, baseQty, proceeds)
+	e.acquire(quote, t, at, quoteLeg, new(big.Rat).Mul(quoteLeg, rate))
+	return nil
+}
+
+// acquire adds a lot of quantity costing cost in the report currency, which
+// itself is not tracked
+func (e *Engine) acquire(asset string, t models.Trade, at time.Time, quantity, cost *big.Rat) {
+	if asset == e.currency {
+		return
+	}
+	e.addLot(&Lot{Asset: asset, TradeId: t.Id, Time: at, Quantity: quantity, UnitCost: cost.Quo(cost, quantity)})
+}
+
+func (e *Engine) addLot(lot *Lot) {
+	p := e.pool(lot.Asset)
+	if e.method != AverageCost || len(p.lots) == 0 {
+		p.lots = append(p.lots, lot)
+		return
+	}
+
+	pooled := p.lots[0]
+	total := new(big.Rat).Mul(pooled.Quantity, pooled.UnitCost)
+	total.Add(total, new(big.Rat).Mul(lot.Quantity, lot.UnitCost))
+	pooled.Quantity = new(big.Rat).Add(pooled.Quantity, lot.Quantity)
+	pooled.UnitCost = total.Quo(total, pooled.Quantity)
+	pooled.TradeId = 0
+}
+
+// dispose matches quantity of asset against its open lots and records the
+// disposals. The report currency itself is not tracked.
+func (e *Engine) dispose(asset string, t models.Trade, at time.Time, quantity, proceeds *big.Rat) {
+	if asset == e.currency || quantity.Sign() <= 0 {
+		return
+	}
+	p := e.pool(asset)
+	remaining := new(big.Rat).Set(quantity)
+
+	record := func(lot *Lot, qty *big.Rat) {
+		share := new(big.Rat).Quo(qty, quantity)
+		d := Disposal{
+			Asset:     asset,
+			Symbol:    t.Symbol,
+			TradeId:   t.Id,
+			Time:      at,
+			Quantity:  qty,
+			CostBasis: new(big.Rat),
+			Proceeds:  share.Mul(share, proceeds),
+		}
+		if lot == nil {
+			d.Unmatched = true
+		} else {
+			d.LotTradeId = lot.TradeId
+			d.Acquired = lot.Time
+			d.CostBasis.Mul(qty, lot.UnitCost)
+		}
+		d.PnL = new(big.Rat).Sub(d.Proceeds, d.CostBasis)
+
+		p.realized.Add(p.realized, d.PnL)
+		p.disposals = append(p.disposals, d)
+	}
+
+	for remaining.Sign() > 0 && len(p.lots) > 0 {
+		i := 0
+		if e.method == LIFO {
+			i = len(p.lots) - 1
+		}
+		lot := p.lots[i]
+
+		qty := new(big.Rat).Set(remaining)
+		if lot.Quantity.Cmp(qty) < 0 {
+			qty.Set(lot.Quantity)
+		}
+
+		record(lot, qty)
+		remaining.Sub(remaining, qty)
+		lot.Quantity = new(big.Rat).Sub(lot.Quantity, qty)
+		if lot.Quantity.Sign() == 0 {
+			p.lots = append(p.lots[:i], p.lots[i+1:]...)
+		}
+	}
+
+	if remaining.Sign() > 0 {
+		record(nil, remaining)
+	}
+}
+
+// Report values the open holdings at the given time and returns the PnL of
+// every asset
+func (e *Engine) Report(at time.Time) *Report {
+	report := &Report{Method: e.method, Currency: e.currency, Time: at, Fees: new(big.Rat).Set(e.fees)}
+
+	assets := make([]string, 0, len(e.pools))
+	for asset := range e.pools {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	for _, asset := range assets {
+		p := e.pools[asset]
+		a := AssetPnL{
+			Asset:     asset,
+			Quantity:  new(big.Rat),
+			CostBasis: new(big.Rat),
+			Realized:  new(big.Rat).Set(p.realized),
+			Disposals: p.disposals,
+		}
+		for _, lot := range p.lots {
+			a.Quantity.Add(a.Quantity, lot.Quantity)
+			a.CostBasis.Add(a.CostBasis, new(big.Rat).Mul(lot.Quantity, lot.UnitCost))
+			a.OpenLots = append(a.OpenLots, *lot)
+		}
+
+		if a.Quantity.Sign() != 0 {
+			mark, err := e.prices.Price(asset, e.currency, at)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Errorf("%s: failed to get mark price: %w", asset, err))
+			} else {
+				a.MarkPrice = mark
+				a.Unrealized = new(big.Rat).Mul(a.Quantity, mark)
+				a.Unrealized.Sub(a.Unrealized, a.CostBasis)
+			}
+		} else {
+			a.Unrealized = new(big.Rat)
+		}
+
+		report.Assets = append(report.Assets, a)
+	}
+
+	return report
+}
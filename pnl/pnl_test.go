@@ -0,0 +1,143 @@
+package pnl_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/pnl"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+var (
+	btcusdt = models.SymbolInfo{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT"}
+	ethbtc  = models.SymbolInfo{Symbol: "ETHBTC", BaseAsset: "ETH", QuoteAsset: "BTC"}
+	prices  = pnl.StaticPrices{"BTC/USDT": "50000", "ETH/USDT": "3000", "BNB/USDT": "500"}
+)
+
+// checkRat fails the test if got does not equal the decimal want
+func checkRat(t *testing.T, name string, got *big.Rat, want string) {
+	t.Helper()
+	w, err := utils.ParseDecimal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Cmp(w) != 0 {
+		t.Errorf("%s: expected %s, got %v", name, want, got)
+	}
+}
+
+// asset returns the result of one asset, failing the test if it is missing
+func asset(t *testing.T, r *pnl.Report, name string) pnl.AssetPnL {
+	t.Helper()
+	for _, a := range r.Assets {
+		if a.Asset == name {
+			return a
+		}
+	}
+	t.Fatalf("no result for %s in %+v", name, r.Assets)
+	return pnl.AssetPnL{}
+}
+
+func TestMethods(t *testing.T) {
+	trades := []models.Trade{
+		{Symbol: "BTCUSDT", Id: 1, Time: 1000, Price: "40000", Qty: "1", QuoteQty: "40000", IsBuyer: true},
+		{Symbol: "BTCUSDT", Id: 2, Time: 2000, Price: "60000", Qty: "1", QuoteQty: "60000", IsBuyer: true},
+		{Symbol: "BTCUSDT", Id: 3, Time: 3000, Price: "55000", Qty: "1", QuoteQty: "55000"},
+	}
+
+	tests := []struct {
+		method         pnl.Method
+		wantRealized   string
+		wantCostBasis  string
+		wantUnrealized string
+		wantLotTradeId int64
+	}{
+		{method: pnl.FIFO, wantRealized: "15000", wantCostBasis: "60000", wantUnrealized: "-10000", wantLotTradeId: 1},
+		{method: pnl.LIFO, wantRealized: "-5000", wantCostBasis: "40000", wantUnrealized: "10000", wantLotTradeId: 2},
+		{method: pnl.AverageCost, wantRealized: "5000", wantCostBasis: "50000", wantUnrealized: "0", wantLotTradeId: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			e := pnl.NewEngine(tt.method, []models.SymbolInfo{btcusdt}, prices, "USDT")
+			if err := e.AddTrades(trades); err != nil {
+				t.Fatalf("AddTrades: %v", err)
+			}
+
+			report := e.Report(time.Now())
+			if len(report.Assets) != 1 {
+				t.Fatalf("expected only BTC to be tracked, got %+v", report.Assets)
+			}
+			btc := asset(t, report, "BTC")
+			checkRat(t, "quantity", btc.Quantity, "1")
+			checkRat(t, "realized", btc.Realized, tt.wantRealized)
+			checkRat(t, "cost basis", btc.CostBasis, tt.wantCostBasis)
+			checkRat(t, "unrealized", btc.Unrealized, tt.wantUnrealized)
+			if len(btc.Disposals) == 0 || btc.Disposals[0].LotTradeId != tt.wantLotTradeId {
+				t.Errorf("expected the sale to be matched against trade %d, got %+v", tt.wantLotTradeId, btc.Disposals)
+			}
+		})
+	}
+}
+
+func TestQuoteLegAndCommission(t *testing.T) {
+	e := pnl.NewEngine(pnl.FIFO, []models.SymbolInfo{btcusdt, ethbtc}, prices, "USDT")
+	if err := e.AddOpeningLot("BNB", "1", "400", time.UnixMilli(0)); err != nil {
+		t.Fatalf("AddOpeningLot: %v", err)
+	}
+
+	// BTC bought at 40000 pays for ETH when BTC is worth 50000, with the
+	// commission paid in BNB
+	err := e.AddTrades([]models.Trade{
+		{Symbol: "BTCUSDT", Id: 1, Time: 1000, Price: "40000", Qty: "1", QuoteQty: "40000", IsBuyer: true},
+		{Symbol: "ETHBTC", Id: 2, Time: 2000, Price: "0.05", Qty: "10", QuoteQty: "0.5", Commission: "0.01", CommissionAsset: "BNB", IsBuyer: true},
+	})
+	if err != nil {
+		t.Fatalf("AddTrades: %v", err)
+	}
+
+	report := e.Report(time.Now())
+	checkRat(t, "fees", report.Fees, "5")
+
+	btc := asset(t, report, "BTC")
+	checkRat(t, "BTC quantity", btc.Quantity, "0.5")
+	checkRat(t, "BTC realized", btc.Realized, "5000")
+	if len(btc.Disposals) != 1 || btc.Disposals[0].Symbol != "ETHBTC" || btc.Disposals[0].TradeId != 2 {
+		t.Errorf("expected the ETHBTC buy to dispose of BTC, got %+v", btc.Disposals)
+	}
+
+	eth := asset(t, report, "ETH")
+	checkRat(t, "ETH cost basis", eth.CostBasis, "25005")
+	checkRat(t, "ETH unrealized", eth.Unrealized, "4995")
+
+	bnb := asset(t, report, "BNB")
+	checkRat(t, "BNB quantity", bnb.Quantity, "0.99")
+	checkRat(t, "BNB realized", bnb.Realized, "1")
+}
+
+func TestAddTradesRejectsEmptyAcquisition(t *testing.T) {
+	tests := []struct {
+		name  string
+		trade models.Trade
+	}{
+		{
+			name:  "buy consumed by base commission",
+			trade: models.Trade{Symbol: "BTCUSDT", Id: 1, Time: 1000, Price: "50000", Qty: "0.001", QuoteQty: "50", Commission: "0.001", CommissionAsset: "BTC", IsBuyer: true},
+		},
+		{
+			name:  "sale consumed by quote commission",
+			trade: models.Trade{Symbol: "ETHBTC", Id: 2, Time: 1000, Price: "0.05", Qty: "0.001", QuoteQty: "0.00005", Commission: "0.0001", CommissionAsset: "BTC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := pnl.NewEngine(pnl.FIFO, []models.SymbolInfo{btcusdt, ethbtc}, prices, "USDT")
+			if err := e.AddTrades([]models.Trade{tt.trade}); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package pnl
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// ErrNoPrice is returned when no market converts one asset into another
+var ErrNoPrice = errors.New("no price available")
+
+// PriceSource returns the price of one unit of asset in quote at a point in time
+type PriceSource interface {
+	Price(asset, quote string, at time.Time) (*big.Rat, error)
+}
+
+// bridgeAssets are tried as intermediate assets when no market trades asset against quote directly
+var bridgeAssets = []string{"USDT", "BTC", "BNB", "ETH", "FDUSD", "USDC"}
+
+// KlinePriceSource prices assets with the close of the one minute kline
+// containing the requested time. Pairs without a market are priced through the
+// inverse market or through USDT, BTC, BNB, ETH, FDUSD or USDC.
+type KlinePriceSource struct {
+	market *endpoints.MarketDataService
+
+	mu      sync.Mutex
+	symbols map[[2]string]string
+	cache   map[string]*big.Rat
+}
+
+// NewKlinePriceSource creates a price source using market
+func NewKlinePriceSource(market *endpoints.MarketDataService) *KlinePriceSource {
+	return &KlinePriceSource{
+		market: market,
+		cache:  make(map[string]*big.Rat),
+	}
+}
+
+// Price returns the price of asset in quote at the given time
+func (p *KlinePriceSource) Price(asset, quote string, at time.Time) (*big.Rat, error) {
+	if asset == quote {
+		return big.NewRat(1, 1), nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.loadSymbols(); err != nil {
+		return nil, err
+	}
+
+	// A market that fails, for example because it has no kline at the time,
+	// does not stop the search; its error is reported if nothing else prices the pair
+	var errs []error
+	price, ok, err := p.pairPrice(asset, quote, at)
+	if ok {
+		return price, nil
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, bridge := range bridgeAssets {
+		if bridge == asset || bridge == quote {
+			continue
+		}
+		first, ok, err := p.pairPrice(asset, bridge, at)
+		if !ok {
+			if err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		second, ok, err := p.pairPrice(bridge, quote, at)
+		if ok {
+			return new(big.Rat).Mul(first, second), nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w for %s in %s: %w", ErrNoPrice, asset, quote, errors.Join(errs...))
+	}
+	return nil, fmt.Errorf("%w for %s in %s", ErrNoPrice, asset, quote)
+}
+
+// loadSymbols fetches the symbol list once
+func (p *KlinePriceSource) loadSymbols() error {
+	if p.symbols != nil {
+		return nil
+	}
+
+	info, err := p.market.GetExchangeInfo(models.ExchangeInfoRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to load symbols: %w", err)
+	}
+
+	p.symbols = make(map[[2]string]string, len(info.Symbols))
+	for _, s := range info.Symbols {
+		p.symbols[[2]string{s.BaseAsset, s.QuoteAsset}] = s.Symbol
+	}
+	return nil
+}
+
+// pairPrice prices asset in quote through the direct market, or the inverse
+// market if the direct one does not exist or fails; ok is false when neither
+// prices the pair, with the error of the last market tried if any
+func (p *KlinePriceSource) pairPrice(asset, quote string, at time.Time) (*big.Rat, bool, error) {
+	var err error
+	if symbol, exists := p.symbols[[2]string{asset, quote}]; exists {
+		var price *big.Rat
+		if price, err = p.klineClose(symbol, at); err == nil {
+			return price, true, nil
+		}
+	}
+
+	if symbol, exists := p.symbols[[2]string{quote, asset}]; exists {
+		price, inverseErr := p.klineClose(symbol, at)
+		switch {
+		case inverseErr != nil:
+			err = inverseErr
+		case price.Sign() == 0:
+			err = fmt.Errorf("%w: %s has a zero price", ErrNoPrice, symbol)
+		default:
+			return new(big.Rat).Inv(price), true, nil
+		}
+	}
+
+	return nil, false, err
+}
+
+// klineClose returns the close of the one minute kline of symbol containing at
+func (p *KlinePriceSource) klineClose(symbol string, at time.Time) (*big.Rat, error) {
+	minute := at.Truncate(time.Minute).UnixMilli()
+	key := fmt.Sprintf("%s:%d", symbol, minute)
+	if price, ok := p.cache[key]; ok {
+		return price, nil
+	}
+
+	klines, err := p.market.GetKlines(models.KlineRequest{
+		Symbol:    symbol,
+		Interval:  models.Interval1m,
+		StartTime: minute,
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("%w: no %s kline at %s", ErrNoPrice, symbol, at.UTC().Format(time.RFC3339))
+	}
+
+	price, err := utils.ParseDecimal(klines[0].Close)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cache[key] = price
+	return price, nil
+}
+
+// StaticPrices is a PriceSource with fixed prices, keyed by asset and quote
+// such as "BNB/USDT". Inverse pairs are derived automatically.
+type StaticPrices map[string]string
+
+// Price returns the fixed price of asset in quote
+func (s StaticPrices) Price(asset, quote string, at time.Time) (*big.Rat, error) {
+	if asset == quote {
+		return big.NewRat(1, 1), nil
+	}
+	if v, ok := s[asset+"/"+quote]; ok {
+		return utils.ParseDecimal(v)
+	}
+	if v, ok := s[quote+"/"+asset]; ok {
+		price, err := utils.ParseDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		if price.Sign() != 0 {
+			return new(big.Rat).Inv(price), nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s in %s", ErrNoPrice, asset, quote)
+}
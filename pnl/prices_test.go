@@ -0,0 +1,86 @@
+package pnl_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/pnl"
+)
+
+func TestKlinePriceSource(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 15, 0, time.UTC)
+	minute := at.Truncate(time.Minute).UnixMilli()
+	kline := func(last string) []models.Kline {
+		return []models.Kline{{OpenTime: minute, Close: last, CloseTime: minute + 59999}}
+	}
+
+	tests := []struct {
+		name string
+		// klines maps symbols to their close at the requested minute; other
+		// symbols have no kline, so pricing through them fails
+		klines    map[string]string
+		asset     string
+		quote     string
+		wantPrice string
+		wantErr   bool
+	}{
+		{
+			name:      "direct market",
+			klines:    map[string]string{"BNBUSDT": "500"},
+			asset:     "BNB",
+			quote:     "USDT",
+			wantPrice: "500",
+		},
+		{
+			name:      "inverse market",
+			klines:    map[string]string{"BTCUSDT": "50000"},
+			asset:     "USDT",
+			quote:     "BTC",
+			wantPrice: "1/50000",
+		},
+		{
+			name:      "a failing bridge is skipped for the next one",
+			klines:    map[string]string{"XYZBNB": "2", "BNBUSDT": "500"},
+			asset:     "XYZ",
+			quote:     "USDT",
+			wantPrice: "1000",
+		},
+		{
+			name:    "every bridge fails",
+			asset:   "XYZ",
+			quote:   "USDT",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := binancetest.NewServer()
+			defer srv.Close()
+			// XYZ trades against BTC and BNB only
+			srv.AddSymbol("XYZBTC", "XYZ", "BTC", "0.00002")
+			srv.AddSymbol("XYZBNB", "XYZ", "BNB", "2")
+			for symbol, last := range tt.klines {
+				srv.SetKlines(symbol, models.Interval1m, kline(last))
+			}
+
+			prices := pnl.NewKlinePriceSource(srv.NewClient().Market)
+			price, err := prices.Price(tt.asset, tt.quote, at)
+			if tt.wantErr {
+				if !errors.Is(err, pnl.ErrNoPrice) {
+					t.Fatalf("expected ErrNoPrice, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Price: %v", err)
+			}
+			if price.RatString() != tt.wantPrice {
+				t.Errorf("expected %s, got %s", tt.wantPrice, price.RatString())
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package reconciliation
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// MovementKind classifies a balance change
+type MovementKind string
+
+const (
+	KindDeposit       MovementKind = "DEPOSIT"
+	KindWithdrawal    MovementKind = "WITHDRAWAL"
+	KindWithdrawalFee MovementKind = "WITHDRAWAL_FEE"
+	KindTrade         MovementKind = "TRADE"
+	KindCommission    MovementKind = "COMMISSION"
+	KindTransfer      MovementKind = "TRANSFER"
+	KindDust          MovementKind = "DUST"
+)
+
+// Movement is a signed change of one asset's spot balance
+type Movement struct {
+	Kind   MovementKind
+	Asset  string
+	Amount *big.Rat
+	Time   time.Time
+	// Reference identifies the source record, e.g. "deposit:<id>" or "trade:BTCUSDT:<id>"
+	Reference string
+}
+
+// Activity holds the raw records fetched for a time range
+type Activity struct {
+	StartTime   time.Time
+	EndTime     time.Time
+	Deposits    []models.DepositHistory
+	Withdrawals []models.WithdrawalHistory
+	Trades      []models.Trade
+	Transfers   []models.TransferRecord
+	Dust        []models.DustConversion
+	// Symbols maps each traded symbol to its base and quote asset
+	Symbols map[string]models.SymbolInfo
+}
+
+// Movements converts the activity into spot balance changes, oldest first.
+// Funding wallet deposits and withdrawals, failed records and transfers that
+// neither start nor end in the spot wallet are left out.
+func (a *Activity) Movements() ([]Movement, error) {
+	var movements []Movement
+
+	for _, d := range a.Deposits {
+		if d.WalletType != models.WalletTypeSpot || !d.Status.IsCredited() {
+			continue
+		}
+		amount, err := utils.ParseDecimal(d.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount of deposit %s: %w", d.Id, err)
+		}
+		movements = append(movements, Movement{
+			Kind:      KindDeposit,
+			Asset:     d.Coin,
+			Amount:    amount,
+			Time:      time.UnixMilli(d.InsertTime),
+			Reference: "deposit:" + d.Id,
+		})
+	}
+
+	for _, w := range a.Withdrawals {
+		if w.WalletType != models.WalletTypeSpot || !withdrawalDebited(w.Status) {
+			continue
+		}
+		t, err := time.Parse(models.WithdrawalTimeLayout, w.ApplyTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse apply time of withdrawal %s: %w", w.Id, err)
+		}
+		var p utils.DecimalParser
+		amount, fee := p.Parse(w.Amount), p.Parse(w.TransactionFee)
+		if p.Err != nil {
+			return nil, fmt.Errorf("invalid amount of withdrawal %s: %w", w.Id, p.Err)
+		}
+		movements = append(movements,
+			Movement{Kind: KindWithdrawal, Asset: w.Coin, Amount: neg(amount), Time: t, Reference: "withdrawal:" + w.Id},
+			Movement{Kind: KindWithdrawalFee, Asset: w.Coin, Amount: neg(fee), Time: t, Reference: "withdrawal:" + w.Id},
+		)
+	}
+
+	for _, t := range a.Trades {
+		info, ok := a.Symbols[t.Symbol]
+		if !ok {
+			return nil, fmt.Errorf("unknown symbol %s", t.Symbol)
+		}
+
+		var p utils.DecimalParser
+		qty, quoteQty, commission := p.Parse(t.Qty), p.Parse(t.QuoteQty), p.Parse(t.Commission)
+		if p.Err != nil {
+			return nil, fmt.Errorf("invalid amount of trade %s:%d: %w", t.Symbol, t.Id, p.Err)
+		}
+		if t.IsBuyer {
+			quoteQty = neg(quoteQty)
+		} else {
+			qty = neg(qty)
+		}
+
+		at := time.UnixMilli(t.Time)
+		ref := "trade:" + t.Symbol + ":" + strconv.FormatInt(t.Id, 10)
+		movements = append(movements,
+			Movement{Kind: KindTrade, Asset: info.BaseAsset, Amount: qty, Time: at, Reference: ref},
+			Movement{Kind: KindTrade, Asset: info.QuoteAsset, Amount: quoteQty, Time: at, Reference: ref},
+		)
+
+		if commission.Sign() != 0 {
+			movements = append(movements, Movement{Kind: KindCommission, Asset: t.CommissionAsset, Amount: neg(commission), Time: at, Reference: ref})
+		}
+	}
+
+	for _, t := range a.Transfers {
+		if t.Status != "CONFIRMED" {
+			continue
+		}
+		amount, err := utils.ParseDecimal(t.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount of transfer %d: %w", t.TranId, err)
+		}
+		switch {
+		case IntoSpot(t.Type):
+		case OutOfSpot(t.Type):
+			amount = neg(amount)
+		default:
+			continue
+		}
+		movements = append(movements, Movement{
+			Kind:      KindTransfer,
+			Asset:     t.Asset,
+			Amount:    amount,
+			Time:      time.UnixMilli(t.Timestamp),
+			Reference: "transfer:" + strconv.FormatInt(t.TranId, 10),
+		})
+	}
+
+	for _, d := range a.Dust {
+		for _, item := range d.UserAssetDribbletDetails {
+			at := time.UnixMilli(item.OperateTime)
+			ref := "dust:" + strconv.FormatInt(item.TransId, 10) + ":" + item.FromAsset
+			var p utils.DecimalParser
+			amount, received := p.Parse(item.Amount), p.Parse(item.TransferedAmount)
+			if p.Err != nil {
+				return nil, fmt.Errorf("invalid amount of %s: %w", ref, p.Err)
+			}
+			movements = append(movements,
+				Movement{Kind: KindDust, Asset: item.FromAsset, Amount: neg(amount), Time: at, Reference: ref},
+				Movement{Kind: KindDust, Asset: "BNB", Amount: received, Time: at, Reference: ref},
+			)
+		}
+	}
+
+	sort.SliceStable(movements, func(i, j int) bool { return movements[i].Time.Before(movements[j].Time) })
+	return movements, nil
+}
+
+// withdrawalDebited reports whether a withdrawal in status s has left the balance
+func withdrawalDebited(s models.WithdrawalStatus) bool {
+	switch s {
+	case models.WithdrawalStatusCancelled, models.WithdrawalStatusRejected, models.WithdrawalStatusFailure:
+		return false
+	}
+	return true
+}
+
+// IntoSpot reports whether transfers of type t credit the spot wallet
+func IntoSpot(t models.UniversalTransferType) bool {
+	return strings.HasSuffix(string(t), "_MAIN")
+}
+
+// OutOfSpot reports whether transfers of type t debit the spot wallet
+func OutOfSpot(t models.UniversalTransferType) bool {
+	return strings.HasPrefix(string(t), "MAIN_")
+}
+
+// SpotTransferTypes returns every transfer type that moves funds in or out of the spot wallet
+func SpotTransferTypes() []models.UniversalTransferType {
+	var types []models.UniversalTransferType
+	for _, t := range models.UniversalTransferTypes {
+		if IntoSpot(t) || OutOfSpot(t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func neg(r *big.Rat) *big.Rat {
+	return new(big.Rat).Neg(r)
+}
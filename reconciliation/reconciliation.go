@@ -0,0 +1,317 @@
+// Package reconciliation checks that the spot wallet balance changes over a
+// time range are explained by the deposits, withdrawals, trades, transfers and
+// dust conversions recorded in that range.
+package reconciliation
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	binance "github.com/MartianPay/go-binance"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// ErrNoSnapshot is returned when no account snapshot exists at or before the requested time
+var ErrNoSnapshot = errors.New("no account snapshot available")
+
+// Request describes a reconciliation
+type Request struct {
+	// Opening is the balance at the start of the range. When nil, the latest
+	// daily spot snapshot at or before StartTime is used.
+	Opening *models.BalanceTable
+	// Closing is the balance at the end of the range. When nil, the latest daily
+	// spot snapshot at or before EndTime is used, or the current balance when
+	// EndTime is zero.
+	Closing   *models.BalanceTable
+	StartTime time.Time
+	EndTime   time.Time
+	// Symbols lists the symbols traded in the range; trades can only be fetched per symbol
+	Symbols []string
+	// TransferTypes defaults to every transfer type in or out of the spot wallet
+	TransferTypes []models.UniversalTransferType
+	// Tolerance is the largest difference treated as a match (default 0)
+	Tolerance string
+}
+
+// Reconciler fetches account activity through the SDK services and compares it with balances
+type Reconciler struct {
+	client *binance.BinanceClient
+}
+
+// New creates a reconciler using c
+func New(c *binance.BinanceClient) *Reconciler {
+	return &Reconciler{client: c}
+}
+
+// Reconcile resolves the opening and closing balances, fetches the activity in
+// between and compares the expected and actual balance of every asset
+func (r *Reconciler) Reconcile(req Request) (*Report, error) {
+	opening := req.Opening
+	if opening == nil {
+		if req.StartTime.IsZero() {
+			return nil, errors.New("opening balance or start time is required")
+		}
+		table, err := r.SpotBalancesAt(req.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get opening balance: %w", err)
+		}
+		opening = table
+	}
+
+	closing := req.Closing
+	if closing == nil {
+		var table *models.BalanceTable
+		var err error
+		if req.EndTime.IsZero() {
+			table, err = r.CurrentSpotBalances()
+		} else {
+			table, err = r.SpotBalancesAt(req.EndTime)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get closing balance: %w", err)
+		}
+		closing = table
+	}
+
+	if !closing.Time.After(opening.Time) {
+		return nil, fmt.Errorf("closing balance at %s is not after opening balance at %s", closing.Time, opening.Time)
+	}
+
+	tolerance, err := utils.ParseDecimal(req.Tolerance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tolerance: %w", err)
+	}
+
+	// Activity at the opening time is already reflected in the opening balance
+	activity, err := r.FetchActivity(opening.Time.Add(time.Millisecond), closing.Time, req.Symbols, req.TransferTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := activity.Movements()
+	if err != nil {
+		return nil, err
+	}
+
+	return Compare(opening, closing, movements, tolerance)
+}
+
+// FetchActivity fetches every deposit, withdrawal, trade of the given symbols,
+// transfer of the given types and dust conversion between start and end
+func (r *Reconciler) FetchActivity(start, end time.Time, symbols []string, transferTypes []models.UniversalTransferType) (*Activity, error) {
+	activity := &Activity{StartTime: start, EndTime: end, Symbols: make(map[string]models.SymbolInfo)}
+
+	deposits, err := r.client.Deposit.IterateDepositHistory(models.DepositHistoryRequest{StartTime: start, EndTime: end}).All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deposits: %w", err)
+	}
+	activity.Deposits = deposits
+
+	withdrawals, err := r.client.Withdrawal.IterateWithdrawalHistory(models.WithdrawalHistoryRequest{StartTime: start, EndTime: end}).All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch withdrawals: %w", err)
+	}
+	activity.Withdrawals = withdrawals
+
+	if len(symbols) > 0 {
+		info, err := r.client.Market.GetExchangeInfo(models.ExchangeInfoRequest{Symbols: symbols})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch symbols: %w", err)
+		}
+		for _, s := range info.Symbols {
+			activity.Symbols[s.Symbol] = s
+		}
+	}
+
+	for _, symbol := range symbols {
+		trades, err := r.client.Trading.IterateMyTrades(models.MyTradesRequest{Symbol: symbol, StartTime: start, EndTime: end}).All()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s trades: %w", symbol, err)
+		}
+		activity.Trades = append(activity.Trades, trades...)
+	}
+
+	if transferTypes == nil {
+		transferTypes = SpotTransferTypes()
+	}
+	for _, t := range transferTypes {
+		transfers, err := r.client.Account.IterateTransferHistory(models.TransferHistoryRequest{Type: t, StartTime: start, EndTime: end}).All()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s transfers: %w", t, err)
+		}
+		activity.Transfers = append(activity.Transfers, transfers...)
+	}
+
+	dust, err := r.client.Account.GetDustLog(models.DustLogRequest{StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dust conversions: %w", err)
+	}
+	activity.Dust = dust.UserAssetDribblets
+
+	return activity, nil
+}
+
+// SpotBalancesAt returns the latest daily spot snapshot at or before t.
+// Snapshots are only kept for the last month.
+func (r *Reconciler) SpotBalancesAt(t time.Time) (*models.BalanceTable, error) {
+	snapshots, err := r.client.Account.IterateSpotSnapshots(models.AccountSnapshotRequest{
+		StartTime: t.Add(-7 * 24 * time.Hour),
+		EndTime:   t,
+	}).All()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("%w at %s", ErrNoSnapshot, t)
+	}
+
+	return endpoints.SpotSnapshotBalanceTable(snapshots[len(snapshots)-1])
+}
+
+// CurrentSpotBalances returns the current spot balances, stamped with the
+// local time. The account updateTime is the last balance change, which can be
+// long before an opening balance on an idle account.
+func (r *Reconciler) CurrentSpotBalances() (*models.BalanceTable, error) {
+	now := time.Now()
+	info, err := r.client.Trading.GetAccountInfo(0)
+	if err != nil {
+		return nil, err
+	}
+	return endpoints.NewSpotBalanceTable(info.Balances, now)
+}
+
+// AssetResult is the reconciliation of one asset
+type AssetResult struct {
+	Asset    string
+	Opening  *big.Rat
+	Expected *big.Rat
+	Actual   *big.Rat
+	// Difference is Actual minus Expected
+	Difference *big.Rat
+	// Changes sums the movements of each kind
+	Changes   map[MovementKind]*big.Rat
+	Movements []Movement
+	Matched   bool
+}
+
+// Report is the result of a reconciliation
+type Report struct {
+	Opening *models.BalanceTable
+	Closing *models.BalanceTable
+	// Assets holds every asset with a balance or a movement, in alphabetical order
+	Assets []AssetResult
+}
+
+// OK reports whether every asset matched
+func (r *Report) OK() bool {
+	return len(r.Discrepancies()) == 0
+}
+
+// Discrepancies returns the assets whose actual balance differs from the expected one
+func (r *Report) Discrepancies() []AssetResult {
+	var out []AssetResult
+	for _, a := range r.Assets {
+		if !a.Matched {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Compare applies movements to the opening balances and compares the result
+// with the closing balances
+func Compare(opening, closing *models.BalanceTable, movements []Movement, tolerance *big.Rat) (*Report, error) {
+	if tolerance == nil {
+		tolerance = new(big.Rat)
+	}
+
+	results := make(map[string]*AssetResult)
+	result := func(asset string) (*AssetResult, error) {
+		a, ok := results[asset]
+		if ok {
+			return a, nil
+		}
+		openingTotal, err := endpoints.BalanceTableTotal(opening, asset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid opening balance: %w", err)
+		}
+		closingTotal, err := endpoints.BalanceTableTotal(closing, asset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid closing balance: %w", err)
+		}
+		a = &AssetResult{
+			Asset:    asset,
+			Opening:  openingTotal,
+			Expected: new(big.Rat).Set(openingTotal),
+			Actual:   closingTotal,
+			Changes:  make(map[MovementKind]*big.Rat),
+		}
+		results[asset] = a
+		return a, nil
+	}
+
+	for _, asset := range append(opening.Assets(), closing.Assets()...) {
+		if _, err := result(asset); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range movements {
+		a, err := result(m.Asset)
+		if err != nil {
+			return nil, err
+		}
+		a.Expected.Add(a.Expected, m.Amount)
+		if a.Changes[m.Kind] == nil {
+			a.Changes[m.Kind] = new(big.Rat)
+		}
+		a.Changes[m.Kind].Add(a.Changes[m.Kind], m.Amount)
+		a.Movements = append(a.Movements, m)
+	}
+
+	report := &Report{Opening: opening, Closing: closing}
+	for _, a := range results {
+		a.Difference = new(big.Rat).Sub(a.Actual, a.Expected)
+		a.Matched = new(big.Rat).Abs(a.Difference).Cmp(tolerance) <= 0
+		report.Assets = append(report.Assets, *a)
+	}
+	sort.Slice(report.Assets, func(i, j int) bool { return report.Assets[i].Asset < report.Assets[j].Asset })
+
+	return report, nil
+}
+
+// String formats the report as a table with one row per asset, followed by
+// the movements of every asset that did not match
+func (r *Report) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ASSET\tOPENING\tEXPECTED\tACTUAL\tDIFFERENCE\tSTATUS")
+	for _, a := range r.Assets {
+		status := "OK"
+		if !a.Matched {
+			status = "MISMATCH"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.Asset,
+			utils.FormatDecimal(a.Opening, 8), utils.FormatDecimal(a.Expected, 8),
+			utils.FormatDecimal(a.Actual, 8), utils.FormatDecimal(a.Difference, 8), status)
+	}
+	w.Flush()
+
+	for _, a := range r.Discrepancies() {
+		fmt.Fprintf(&b, "\n%s movements:\n", a.Asset)
+		for _, m := range a.Movements {
+			fmt.Fprintf(&b, "  %s  %-14s %s  %s\n", m.Time.UTC().Format(time.RFC3339), m.Kind, utils.FormatDecimal(m.Amount, 8), m.Reference)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package reconciliation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/binancetest"
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/reconciliation"
+)
+
+func TestReconcileCurrentBalances(t *testing.T) {
+	tests := []struct {
+		name string
+		// activity runs between the opening and the closing balance
+		activity func(srv *binancetest.Server)
+		want     map[string]string
+	}{
+		{
+			name:     "idle account",
+			activity: func(srv *binancetest.Server) {},
+			want:     map[string]string{"BTC": "1", "USDT": "500"},
+		},
+		{
+			name: "deposit",
+			activity: func(srv *binancetest.Server) {
+				srv.AddDeposit(models.DepositHistory{Coin: "USDT", Amount: "250", Status: models.DepositStatusSuccess})
+			},
+			want: map[string]string{"BTC": "1", "USDT": "750"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := binancetest.NewServer()
+			defer srv.Close()
+			c := srv.NewClient()
+
+			srv.SetBalance("BTC", "1")
+			srv.SetBalance("USDT", "500")
+			info, err := c.Trading.GetAccountInfo(0)
+			if err != nil {
+				t.Fatalf("GetAccountInfo: %v", err)
+			}
+
+			// The opening balance is taken after the account last changed, so
+			// the account updateTime is older than it
+			time.Sleep(10 * time.Millisecond)
+			opening, err := endpoints.NewSpotBalanceTable(info.Balances, time.Now())
+			if err != nil {
+				t.Fatalf("NewSpotBalanceTable: %v", err)
+			}
+			time.Sleep(10 * time.Millisecond)
+			tt.activity(srv)
+
+			report, err := reconciliation.New(c).Reconcile(reconciliation.Request{Opening: opening})
+			if err != nil {
+				t.Fatalf("Reconcile: %v", err)
+			}
+			if !report.OK() {
+				t.Errorf("expected every asset to match, got discrepancies %+v", report.Discrepancies())
+			}
+			for asset, want := range tt.want {
+				if got := report.Closing.Balances[asset].Total; got != want {
+					t.Errorf("%s: expected closing balance %s, got %s", asset, want, got)
+				}
+			}
+		})
+	}
+}
@@ -1,9 +1,17 @@
 package utils
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/url"
 	"sort"
@@ -12,32 +20,161 @@ import (
 	"time"
 )
 
+// KeyType is the kind of API key, which determines how requests are signed
+type KeyType string
+
+const (
+	KeyTypeHMAC    KeyType = "HMAC"
+	KeyTypeRSA     KeyType = "RSA"
+	KeyTypeEd25519 KeyType = "ED25519"
+)
+
 type Signer struct {
 	APIKey    string
 	SecretKey string
+	KeyType   KeyType
+
+	// privateKey signs requests for RSA and Ed25519 keys
+	privateKey crypto.Signer
+	// provider, when set, supplies the credentials of every request instead of the fields above
+	provider CredentialProvider
+}
+
+// NewProviderSigner creates a signer that asks provider for credentials on
+// every request, so keys can be rotated without recreating the client
+func NewProviderSigner(provider CredentialProvider) *Signer {
+	return &Signer{provider: provider}
 }
 
 func NewSigner(apiKey, secretKey string) *Signer {
 	return &Signer{
 		APIKey:    apiKey,
 		SecretKey: secretKey,
+		KeyType:   KeyTypeHMAC,
 	}
 }
 
-func (s *Signer) Sign(params map[string]string) string {
-	if params == nil {
-		params = make(map[string]string)
+// NewRSASigner creates a signer for an RSA API key
+func NewRSASigner(apiKey string, key *rsa.PrivateKey) *Signer {
+	return &Signer{APIKey: apiKey, KeyType: KeyTypeRSA, privateKey: key}
+}
+
+// NewEd25519Signer creates a signer for an Ed25519 API key
+func NewEd25519Signer(apiKey string, key ed25519.PrivateKey) *Signer {
+	return &Signer{APIKey: apiKey, KeyType: KeyTypeEd25519, privateKey: key}
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS#8 or PKCS#1 private key, as
+// generated for RSA and Ed25519 API keys
+func ParsePrivateKey(pemData []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("no PEM block found in private key")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return key, nil
+}
+
+// NewSignerFromPEM creates an RSA or Ed25519 signer from a PEM encoded private
+// key, checking that the key matches keyType
+func NewSignerFromPEM(apiKey string, keyType KeyType, pemData []byte) (*Signer, error) {
+	key, err := ParsePrivateKey(pemData)
+	if err != nil {
+		return nil, err
 	}
 
-	params["timestamp"] = strconv.FormatInt(time.Now().UnixMilli(), 10)
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if keyType != KeyTypeRSA {
+			return nil, fmt.Errorf("private key is RSA but key type is %s", keyType)
+		}
+		return NewRSASigner(apiKey, k), nil
+	case ed25519.PrivateKey:
+		if keyType != KeyTypeEd25519 {
+			return nil, fmt.Errorf("private key is Ed25519 but key type is %s", keyType)
+		}
+		return NewEd25519Signer(apiKey, k), nil
+	}
 
-	queryString := s.BuildQueryString(params)
-	signature := s.GenerateSignature(queryString)
+	return nil, fmt.Errorf("unsupported private key type %T", key)
+}
 
-	return queryString + "&signature=" + signature
+// credentials returns the credentials to sign with; the caller must release them
+func (s *Signer) credentials() (*Credentials, error) {
+	if s.provider != nil {
+		return s.provider.Credentials()
+	}
+	return &Credentials{APIKey: s.APIKey, KeyType: s.KeyType, Secret: []byte(s.SecretKey), PrivateKey: s.privateKey}, nil
+}
+
+// SignRequest builds the query string of a request, signed when sign is set,
+// and the headers carrying the API key. Both use the same credentials even if
+// the provider rotates them concurrently.
+func (s *Signer) SignRequest(params url.Values, sign bool) (string, map[string]string, error) {
+	creds, err := s.credentials()
+	if err != nil {
+		return "", nil, err
+	}
+	defer creds.Release()
+
+	headers := map[string]string{
+		"X-MBX-APIKEY": creds.APIKey,
+	}
+
+	if !sign {
+		return s.BuildQueryStringValues(params), headers, nil
+	}
+
+	signed := make(url.Values, len(params)+1)
+	for k, v := range params {
+		signed[k] = v
+	}
+	signed.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+
+	queryString := s.BuildQueryStringValues(signed)
+	signature, err := signWith(creds, queryString)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// RSA and Ed25519 signatures are base64, which must be escaped
+	return queryString + "&signature=" + url.QueryEscape(signature), headers, nil
+}
+
+// Sign returns the signed query string of params, or "" if signing fails.
+//
+// Deprecated: Sign cannot report credential or signing errors; use SignValues
+// or SignRequest.
+func (s *Signer) Sign(params map[string]string) string {
+	queryString, _ := s.SignValues(toValues(params))
+	return queryString
+}
+
+// SignValues signs params, which may repeat, such as asset=BTC&asset=ETH
+func (s *Signer) SignValues(params url.Values) (string, error) {
+	queryString, _, err := s.SignRequest(params, true)
+	return queryString, err
 }
 
 func (s *Signer) BuildQueryString(params map[string]string) string {
+	return s.BuildQueryStringValues(toValues(params))
+}
+
+// BuildQueryStringValues builds a query string with keys in sorted order and
+// repeated values in the order given, skipping empty values
+func (s *Signer) BuildQueryStringValues(params url.Values) string {
 	keys := make([]string, 0, len(params))
 	for k := range params {
 		keys = append(keys, k)
@@ -46,22 +183,89 @@ func (s *Signer) BuildQueryString(params map[string]string) string {
 
 	var pairs []string
 	for _, k := range keys {
-		if v := params[k]; v != "" {
-			pairs = append(pairs, fmt.Sprintf("%s=%s", k, url.QueryEscape(v)))
+		for _, v := range params[k] {
+			if v != "" {
+				pairs = append(pairs, fmt.Sprintf("%s=%s", k, url.QueryEscape(v)))
+			}
 		}
 	}
 
 	return strings.Join(pairs, "&")
 }
 
+func toValues(params map[string]string) url.Values {
+	values := make(url.Values, len(params))
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	return values
+}
+
+// GenerateSignature signs queryString like SignQuery, returning "" if signing fails.
+//
+// Deprecated: GenerateSignature cannot report credential or signing errors;
+// use SignQuery.
 func (s *Signer) GenerateSignature(queryString string) string {
-	h := hmac.New(sha256.New, []byte(s.SecretKey))
+	signature, _ := s.SignQuery(queryString)
+	return signature
+}
+
+// SignQuery signs queryString: hex HMAC-SHA256 for HMAC keys, base64
+// PKCS#1 v1.5 SHA-256 for RSA keys and base64 Ed25519 for Ed25519 keys
+func (s *Signer) SignQuery(queryString string) (string, error) {
+	creds, err := s.credentials()
+	if err != nil {
+		return "", err
+	}
+	defer creds.Release()
+
+	return signWith(creds, queryString)
+}
+
+func signWith(creds *Credentials, queryString string) (string, error) {
+	switch creds.KeyType {
+	case KeyTypeRSA:
+		digest := sha256.Sum256([]byte(queryString))
+		sig, err := creds.PrivateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+		if err != nil {
+			return "", fmt.Errorf("failed to sign request: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(sig), nil
+
+	case KeyTypeEd25519:
+		sig, err := creds.PrivateKey.Sign(rand.Reader, []byte(queryString), crypto.Hash(0))
+		if err != nil {
+			return "", fmt.Errorf("failed to sign request: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(sig), nil
+	}
+
+	h := hmac.New(sha256.New, creds.Secret)
 	h.Write([]byte(queryString))
-	return hex.EncodeToString(h.Sum(nil))
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
+// GetHeaders returns the API key header, or no headers if the credentials
+// cannot be loaded.
+//
+// Deprecated: GetHeaders cannot report credential errors; use Headers.
 func (s *Signer) GetHeaders() map[string]string {
-	return map[string]string{
-		"X-MBX-APIKEY": s.APIKey,
+	headers, err := s.Headers()
+	if err != nil {
+		return map[string]string{}
 	}
+	return headers
+}
+
+// Headers returns the header carrying the API key
+func (s *Signer) Headers() (map[string]string, error) {
+	creds, err := s.credentials()
+	if err != nil {
+		return nil, err
+	}
+	defer creds.Release()
+
+	return map[string]string{
+		"X-MBX-APIKEY": creds.APIKey,
+	}, nil
 }
@@ -0,0 +1,325 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials is an API key with its signing material. Credentials handed out
+// by a CredentialProvider must be released after use; retired credentials are
+// zeroized once the last user releases them, as far as zero allows.
+type Credentials struct {
+	APIKey  string
+	KeyType KeyType
+	// Secret is the secret of an HMAC key
+	Secret []byte
+	// PrivateKey signs requests for RSA and Ed25519 keys
+	PrivateKey crypto.Signer
+
+	mu      sync.Mutex
+	refs    int
+	retired bool
+	managed bool
+}
+
+// acquire marks the credentials as in use
+func (c *Credentials) acquire() *Credentials {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refs++
+	c.managed = true
+	return c
+}
+
+// Release ends a use of credentials returned by a provider
+func (c *Credentials) Release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refs > 0 {
+		c.refs--
+	}
+	if c.retired && c.refs == 0 {
+		c.zero()
+	}
+}
+
+// retire zeroizes the credentials as soon as they are no longer in use
+func (c *Credentials) retire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retired = true
+	if c.refs == 0 {
+		c.zero()
+	}
+}
+
+// zero overwrites the secret and private key material in place. HMAC secrets
+// and Ed25519 keys are wiped completely. For RSA keys only the exported fields
+// are: crypto/rsa keeps its own copies of the modulus and primes in unexported
+// precomputed values, which stay in memory until the key is garbage collected.
+func (c *Credentials) zero() {
+	for i := range c.Secret {
+		c.Secret[i] = 0
+	}
+
+	switch k := c.PrivateKey.(type) {
+	case ed25519.PrivateKey:
+		for i := range k {
+			k[i] = 0
+		}
+	case *rsa.PrivateKey:
+		zeroInt(k.D)
+		for _, p := range k.Primes {
+			zeroInt(p)
+		}
+		zeroInt(k.Precomputed.Dp)
+		zeroInt(k.Precomputed.Dq)
+		zeroInt(k.Precomputed.Qinv)
+	}
+}
+
+func zeroInt(n *big.Int) {
+	if n == nil {
+		return
+	}
+	words := n.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	n.SetInt64(0)
+}
+
+// CredentialProvider supplies the credentials used to sign each request
+type CredentialProvider interface {
+	// Credentials returns the current credentials. The caller must call
+	// Release on them when the request has been signed.
+	Credentials() (*Credentials, error)
+}
+
+// RotatingProvider caches credentials from a loader and replaces them when
+// they are older than the refresh interval or when Rotate is called. Replaced
+// credentials are zeroized once in-flight requests have released them.
+type RotatingProvider struct {
+	load    func() (*Credentials, error)
+	refresh time.Duration
+
+	mu       sync.Mutex
+	current  *Credentials
+	loadedAt time.Time
+
+	// failures counts the loads that failed in a row; no load is attempted
+	// before retryAt, so a broken loader does not run on every request
+	failures int
+	retryAt  time.Time
+	lastErr  error
+}
+
+// maxReloadRetryDelay caps the backoff between failed loads
+const maxReloadRetryDelay = time.Minute
+
+// NewRotatingProvider creates a provider that loads credentials with load. A
+// refresh of zero keeps credentials until Rotate or Set is called.
+func NewRotatingProvider(load func() (*Credentials, error), refresh time.Duration) *RotatingProvider {
+	return &RotatingProvider{load: load, refresh: refresh}
+}
+
+// Credentials returns the cached credentials, loading them when missing or stale.
+// If a refresh fails the previous credentials stay in use, and the load is
+// retried with a backoff that doubles from one second up to a minute.
+func (p *RotatingProvider) Credentials() (*Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stale := p.refresh > 0 && time.Since(p.loadedAt) >= p.refresh
+	if (p.current == nil || stale) && !time.Now().Before(p.retryAt) {
+		p.reload()
+	}
+	if p.current == nil {
+		return nil, p.lastErr
+	}
+
+	return p.current.acquire(), nil
+}
+
+// Rotate loads new credentials immediately
+func (p *RotatingProvider) Rotate() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.reload()
+}
+
+// Set replaces the credentials with c
+func (p *RotatingProvider) Set(c *Credentials) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.replace(c)
+}
+
+func (p *RotatingProvider) reload() error {
+	c, err := p.load()
+	if err != nil {
+		delay := time.Second << p.failures
+		if delay <= 0 || delay > maxReloadRetryDelay {
+			delay = maxReloadRetryDelay
+		} else {
+			p.failures++
+		}
+		p.retryAt = time.Now().Add(delay)
+		p.lastErr = fmt.Errorf("failed to load credentials: %w", err)
+		return p.lastErr
+	}
+	p.replace(c)
+	return nil
+}
+
+func (p *RotatingProvider) replace(c *Credentials) {
+	old := p.current
+	p.current = c
+	p.loadedAt = time.Now()
+	p.failures = 0
+	p.retryAt = time.Time{}
+	p.lastErr = nil
+	if old != nil && old != c {
+		old.retire()
+	}
+}
+
+// NewStaticProvider returns a provider that always returns c
+func NewStaticProvider(c *Credentials) *RotatingProvider {
+	p := NewRotatingProvider(func() (*Credentials, error) {
+		return nil, errors.New("static credentials cannot be reloaded")
+	}, 0)
+	p.Set(c)
+	return p
+}
+
+// NewEnvProvider reads BINANCE_API_KEY with BINANCE_SECRET_KEY, or with
+// BINANCE_KEY_TYPE and BINANCE_PRIVATE_KEY_FILE for RSA and Ed25519 keys, and
+// reads them again every refresh interval
+func NewEnvProvider(refresh time.Duration) *RotatingProvider {
+	return NewRotatingProvider(func() (*Credentials, error) {
+		return CredentialSpec{
+			APIKey:         os.Getenv("BINANCE_API_KEY"),
+			KeyType:        KeyType(os.Getenv("BINANCE_KEY_TYPE")),
+			SecretKey:      os.Getenv("BINANCE_SECRET_KEY"),
+			PrivateKeyFile: os.Getenv("BINANCE_PRIVATE_KEY_FILE"),
+		}.Credentials()
+	}, refresh)
+}
+
+// NewFileProvider reads credentials from a JSON CredentialSpec file and reads it
+// again every refresh interval, so a rotated file takes effect without a restart
+func NewFileProvider(path string, refresh time.Duration) *RotatingProvider {
+	return NewRotatingProvider(func() (*Credentials, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		defer wipe(data)
+		return parseCredentialSpec(data)
+	}, refresh)
+}
+
+// NewCommandProvider runs a command, such as a secrets manager CLI, that prints
+// a JSON CredentialSpec, and runs it again every refresh interval
+func NewCommandProvider(name string, args []string, refresh time.Duration) *RotatingProvider {
+	return NewRotatingProvider(func() (*Credentials, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("credential command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+
+		data := stdout.Bytes()
+		defer wipe(data)
+		return parseCredentialSpec(data)
+	}, refresh)
+}
+
+// CredentialSpec is the JSON form of credentials read by the file and command providers
+type CredentialSpec struct {
+	APIKey  string  `json:"api_key"`
+	KeyType KeyType `json:"key_type,omitempty"`
+	// SecretKey is the secret of an HMAC key
+	SecretKey string `json:"secret_key,omitempty"`
+	// PrivateKey is a PEM private key; PrivateKeyFile a path to one
+	PrivateKey     string `json:"private_key,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+}
+
+func parseCredentialSpec(data []byte) (*Credentials, error) {
+	var spec CredentialSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	return spec.Credentials()
+}
+
+// Credentials builds credentials from the spec, reading the private key file if set
+func (s CredentialSpec) Credentials() (*Credentials, error) {
+	if s.APIKey == "" {
+		return nil, errors.New("api key is not set")
+	}
+
+	keyType := KeyType(strings.ToUpper(string(s.KeyType)))
+	if keyType == "" {
+		keyType = KeyTypeHMAC
+	}
+
+	c := &Credentials{APIKey: s.APIKey, KeyType: keyType}
+	switch keyType {
+	case KeyTypeHMAC:
+		if s.SecretKey == "" {
+			return nil, errors.New("secret key is not set")
+		}
+		c.Secret = []byte(s.SecretKey)
+
+	case KeyTypeRSA, KeyTypeEd25519:
+		pemData := []byte(s.PrivateKey)
+		if len(pemData) == 0 {
+			if s.PrivateKeyFile == "" {
+				return nil, fmt.Errorf("private key is required for %s keys", keyType)
+			}
+			data, err := os.ReadFile(s.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read private key file: %w", err)
+			}
+			pemData = data
+		}
+		defer wipe(pemData)
+
+		signer, err := NewSignerFromPEM(s.APIKey, keyType, pemData)
+		if err != nil {
+			return nil, err
+		}
+		c.PrivateKey = signer.privateKey
+
+	default:
+		return nil, fmt.Errorf("unknown key type %q", s.KeyType)
+	}
+
+	return c, nil
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,148 @@
+package utils_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/MartianPay/go-binance/utils"
+)
+
+func TestRotatingProviderBackoff(t *testing.T) {
+	loads := 0
+	fail := false
+	p := utils.NewRotatingProvider(func() (*utils.Credentials, error) {
+		loads++
+		if fail {
+			return nil, errors.New("secrets manager unavailable")
+		}
+		return &utils.Credentials{APIKey: "key", KeyType: utils.KeyTypeHMAC, Secret: []byte("secret")}, nil
+	}, time.Nanosecond)
+
+	get := func() {
+		t.Helper()
+		c, err := p.Credentials()
+		if err != nil {
+			t.Fatalf("Credentials: %v", err)
+		}
+		c.Release()
+	}
+
+	get()
+	fail = true
+	// The failed refresh keeps the previous credentials in use
+	get()
+	if loads != 2 {
+		t.Fatalf("expected 2 loads, got %d", loads)
+	}
+
+	// Until the backoff has passed, requests do not run the loader again
+	for i := 0; i < 10; i++ {
+		get()
+	}
+	if loads != 2 {
+		t.Errorf("expected no loads during the backoff, got %d", loads-2)
+	}
+
+	// Rotate loads immediately regardless of the backoff
+	fail = false
+	if err := p.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if loads != 3 {
+		t.Errorf("expected Rotate to load, got %d loads", loads)
+	}
+}
+
+func TestRotatingProviderInitialLoadFails(t *testing.T) {
+	loads := 0
+	p := utils.NewRotatingProvider(func() (*utils.Credentials, error) {
+		loads++
+		return nil, errors.New("no credentials")
+	}, 0)
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.Credentials(); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if loads != 1 {
+		t.Errorf("expected 1 load, got %d", loads)
+	}
+}
+
+func TestRetiredCredentialsAreWiped(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		creds    *utils.Credentials
+		material func(c *utils.Credentials) []byte
+	}{
+		{
+			name:     "hmac",
+			creds:    &utils.Credentials{APIKey: "key", KeyType: utils.KeyTypeHMAC, Secret: []byte("secret")},
+			material: func(c *utils.Credentials) []byte { return c.Secret },
+		},
+		{
+			name:     "ed25519",
+			creds:    &utils.Credentials{APIKey: "key", KeyType: utils.KeyTypeEd25519, PrivateKey: edKey},
+			material: func(c *utils.Credentials) []byte { return c.PrivateKey.(ed25519.PrivateKey) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := utils.NewStaticProvider(tt.creds)
+			inUse, err := p.Credentials()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p.Set(&utils.Credentials{APIKey: "next", KeyType: utils.KeyTypeHMAC, Secret: []byte("next")})
+			zero := make([]byte, len(tt.material(tt.creds)))
+			if bytes.Equal(tt.material(tt.creds), zero) {
+				t.Fatal("credentials were wiped while still in use")
+			}
+
+			inUse.Release()
+			if !bytes.Equal(tt.material(tt.creds), zero) {
+				t.Error("retired credentials were not wiped after release")
+			}
+		})
+	}
+}
+
+func TestSignerReturnsProviderErrors(t *testing.T) {
+	s := utils.NewProviderSigner(utils.NewRotatingProvider(func() (*utils.Credentials, error) {
+		return nil, errors.New("no credentials")
+	}, 0))
+
+	if _, err := s.SignValues(url.Values{"symbol": {"BTCUSDT"}}); err == nil {
+		t.Error("SignValues: expected an error")
+	}
+	if _, err := s.SignQuery("symbol=BTCUSDT"); err == nil {
+		t.Error("SignQuery: expected an error")
+	}
+	if _, err := s.Headers(); err == nil {
+		t.Error("Headers: expected an error")
+	}
+
+	// the deprecated forms keep their signatures and return empty values
+	if q := s.Sign(map[string]string{"symbol": "BTCUSDT"}); q != "" {
+		t.Errorf("Sign: expected an empty query string, got %q", q)
+	}
+	if sig := s.GenerateSignature("symbol=BTCUSDT"); sig != "" {
+		t.Errorf("GenerateSignature: expected an empty signature, got %q", sig)
+	}
+	if h := s.GetHeaders(); len(h) != 0 {
+		t.Errorf("GetHeaders: expected no headers, got %v", h)
+	}
+}
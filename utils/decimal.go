@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ParseDecimal parses a decimal string as returned by the API (e.g. "0.00100000")
+// into an exact rational number. An empty string is treated as zero.
+func ParseDecimal(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return new(big.Rat), nil
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	return r, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid input. It is
+// meant for constants in code; parse API responses and configuration with
+// ParseDecimal or DecimalParser and handle the error.
+func MustParseDecimal(s string) *big.Rat {
+	r, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// DecimalParser parses several decimals in a row and keeps the first error,
+// so a record with many amounts needs only one error check:
+//
+//	var p utils.DecimalParser
+//	qty, price := p.Parse(t.Qty), p.Parse(t.Price)
+//	if p.Err != nil { ... }
+type DecimalParser struct {
+	Err error
+}
+
+// Parse parses s, returning zero and recording the error if it is invalid
+func (p *DecimalParser) Parse(s string) *big.Rat {
+	r, err := ParseDecimal(s)
+	if err != nil {
+		if p.Err == nil {
+			p.Err = err
+		}
+		return new(big.Rat)
+	}
+	return r
+}
+
+// FormatDecimal formats r with the given number of decimal places and trims
+// trailing zeros, so 1.50000000 becomes "1.5" and 2.00000000 becomes "2"
+func FormatDecimal(r *big.Rat, prec int) string {
+	if r == nil {
+		return "0"
+	}
+
+	s := r.FloatString(prec)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+
+	return s
+}
@@ -0,0 +1,247 @@
+// Package valuation values balances in a chosen asset such as USDT, BTC or EUR,
+// converting through ticker prices along the shortest route of markets.
+package valuation
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/MartianPay/go-binance/endpoints"
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/utils"
+)
+
+// DefaultMaxHops is the longest route Value searches by default
+const DefaultMaxHops = 3
+
+// Holding is an amount of an asset to value
+type Holding struct {
+	Asset  string
+	Amount string
+}
+
+// FromBalances converts spot balances, such as TradingAccountInfo.Balances,
+// into holdings of free plus locked amounts
+func FromBalances(balances []models.Balance) ([]Holding, error) {
+	var holdings []Holding
+	for _, b := range balances {
+		var p utils.DecimalParser
+		total := new(big.Rat).Add(p.Parse(b.Free), p.Parse(b.Locked))
+		if p.Err != nil {
+			return nil, fmt.Errorf("invalid %s balance: %w", b.Asset, p.Err)
+		}
+		if total.Sign() != 0 {
+			holdings = append(holdings, Holding{Asset: b.Asset, Amount: utils.FormatDecimal(total, 8)})
+		}
+	}
+	return holdings, nil
+}
+
+// FromUserAssets converts GetUserAsset or GetFundingAsset results into holdings
+// of free, locked, frozen and withdrawing amounts
+func FromUserAssets(assets []models.UserAsset) ([]Holding, error) {
+	var holdings []Holding
+	for _, a := range assets {
+		var p utils.DecimalParser
+		total := new(big.Rat)
+		for _, v := range []string{a.Free, a.Locked, a.Freeze, a.Withdrawing} {
+			total.Add(total, p.Parse(v))
+		}
+		if p.Err != nil {
+			return nil, fmt.Errorf("invalid %s balance: %w", a.Asset, p.Err)
+		}
+		if total.Sign() != 0 {
+			holdings = append(holdings, Holding{Asset: a.Asset, Amount: utils.FormatDecimal(total, 8)})
+		}
+	}
+	return holdings, nil
+}
+
+// Hop is one market on a conversion route
+type Hop struct {
+	Symbol string
+	From   string
+	To     string
+	// Price is the ticker price of Symbol; Inverted is set when converting from
+	// the quote asset to the base asset, so the rate is 1/Price
+	Price    string
+	Inverted bool
+}
+
+// AssetValue is the value of one holding
+type AssetValue struct {
+	Asset  string
+	Amount *big.Rat
+	// Rate is the price of one unit in the target asset; Value is Amount × Rate.
+	// Both are nil when NoRoute is set.
+	Rate    *big.Rat
+	Value   *big.Rat
+	Route   []Hop
+	NoRoute bool
+}
+
+// Valuation is the value of a set of holdings in a target asset
+type Valuation struct {
+	Target string
+	// Total sums the values of every holding with a route
+	Total  *big.Rat
+	Assets []AssetValue
+	// Unpriced lists the assets without a route to Target
+	Unpriced []string
+}
+
+// Options configures Value
+type Options struct {
+	// MaxHops limits the number of markets on a route (default DefaultMaxHops)
+	MaxHops int
+}
+
+// Value values holdings in target using symbols (only those trading are used)
+// and the ticker prices keyed by symbol. Each asset is converted along the route
+// with the fewest markets; ties go to the alphabetically first symbols, so
+// results are deterministic.
+func Value(holdings []Holding, target string, symbols []models.SymbolInfo, prices map[string]string, opts Options) (*Valuation, error) {
+	if opts.MaxHops <= 0 {
+		opts.MaxHops = DefaultMaxHops
+	}
+
+	routes, err := findRoutes(target, symbols, prices, opts.MaxHops)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := make(map[string]*big.Rat)
+	for _, h := range holdings {
+		amount, err := utils.ParseDecimal(h.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s amount: %w", h.Asset, err)
+		}
+		if amounts[h.Asset] == nil {
+			amounts[h.Asset] = new(big.Rat)
+		}
+		amounts[h.Asset].Add(amounts[h.Asset], amount)
+	}
+
+	v := &Valuation{Target: target, Total: new(big.Rat)}
+	for asset, amount := range amounts {
+		av := AssetValue{Asset: asset, Amount: amount}
+
+		r, ok := routes[asset]
+		if !ok {
+			av.NoRoute = true
+			v.Unpriced = append(v.Unpriced, asset)
+			v.Assets = append(v.Assets, av)
+			continue
+		}
+
+		av.Route = r.hops
+		av.Rate = new(big.Rat).Set(r.rate)
+		av.Value = new(big.Rat).Mul(amount, av.Rate)
+		v.Total.Add(v.Total, av.Value)
+		v.Assets = append(v.Assets, av)
+	}
+
+	sort.Slice(v.Assets, func(i, j int) bool { return v.Assets[i].Asset < v.Assets[j].Asset })
+	sort.Strings(v.Unpriced)
+	return v, nil
+}
+
+// edge is a market linking two assets
+type edge struct {
+	symbol string
+	base   string
+	quote  string
+	price  string
+	// rate is the parsed price
+	rate *big.Rat
+}
+
+// route converts an asset into the target through hops at rate
+type route struct {
+	hops []Hop
+	rate *big.Rat
+}
+
+// findRoutes searches outwards from target and returns, for every reachable
+// asset, the route converting it into target
+func findRoutes(target string, symbols []models.SymbolInfo, prices map[string]string, maxHops int) (map[string]route, error) {
+	adjacent := make(map[string][]edge)
+	for _, s := range symbols {
+		if s.Status != "" && s.Status != "TRADING" {
+			continue
+		}
+		price, ok := prices[s.Symbol]
+		if !ok {
+			continue
+		}
+		p, err := utils.ParseDecimal(price)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price of %s: %w", s.Symbol, err)
+		}
+		if p.Sign() <= 0 {
+			continue
+		}
+		e := edge{symbol: s.Symbol, base: s.BaseAsset, quote: s.QuoteAsset, price: price, rate: p}
+		adjacent[s.BaseAsset] = append(adjacent[s.BaseAsset], e)
+		adjacent[s.QuoteAsset] = append(adjacent[s.QuoteAsset], e)
+	}
+	for _, edges := range adjacent {
+		sort.Slice(edges, func(i, j int) bool { return edges[i].symbol < edges[j].symbol })
+	}
+
+	routes := map[string]route{target: {rate: big.NewRat(1, 1)}}
+	frontier := []string{target}
+	for depth := 0; depth < maxHops && len(frontier) > 0; depth++ {
+		var next []string
+		for _, asset := range frontier {
+			for _, e := range adjacent[asset] {
+				// The neighbour converts into asset through e, then along asset's route
+				neighbour, hop := e.base, Hop{Symbol: e.symbol, From: e.base, To: e.quote, Price: e.price}
+				rate := new(big.Rat).Mul(e.rate, routes[asset].rate)
+				if e.base == asset {
+					neighbour, hop = e.quote, Hop{Symbol: e.symbol, From: e.quote, To: e.base, Price: e.price, Inverted: true}
+					rate.Quo(routes[asset].rate, e.rate)
+				}
+				if _, ok := routes[neighbour]; ok {
+					continue
+				}
+				routes[neighbour] = route{hops: append([]Hop{hop}, routes[asset].hops...), rate: rate}
+				next = append(next, neighbour)
+			}
+		}
+		frontier = next
+	}
+
+	return routes, nil
+}
+
+// Valuer values holdings using live exchange information and ticker prices
+type Valuer struct {
+	market *endpoints.MarketDataService
+}
+
+// NewValuer creates a valuer using market
+func NewValuer(market *endpoints.MarketDataService) *Valuer {
+	return &Valuer{market: market}
+}
+
+// Value fetches the symbol list and every ticker price and values holdings in target
+func (v *Valuer) Value(holdings []Holding, target string, opts Options) (*Valuation, error) {
+	info, err := v.market.GetExchangeInfo(models.ExchangeInfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbols: %w", err)
+	}
+
+	tickers, err := v.market.GetTickerPrices(models.TickerPriceRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]string, len(tickers))
+	for _, t := range tickers {
+		prices[t.Symbol] = t.Price
+	}
+
+	return Value(holdings, target, info.Symbols, prices, opts)
+}
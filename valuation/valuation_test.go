@@ -0,0 +1,125 @@
+package valuation_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/MartianPay/go-binance/models"
+	"github.com/MartianPay/go-binance/valuation"
+)
+
+func TestValue(t *testing.T) {
+	symbols := []models.SymbolInfo{
+		{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", Status: "TRADING"},
+		{Symbol: "ETHBTC", BaseAsset: "ETH", QuoteAsset: "BTC", Status: "TRADING"},
+		{Symbol: "XYZETH", BaseAsset: "XYZ", QuoteAsset: "ETH", Status: "TRADING"},
+		{Symbol: "OLDUSDT", BaseAsset: "OLD", QuoteAsset: "USDT", Status: "BREAK"},
+	}
+	prices := map[string]string{"BTCUSDT": "50000", "ETHBTC": "0.05", "XYZETH": "0.001", "OLDUSDT": "1"}
+
+	tests := []struct {
+		name      string
+		holding   valuation.Holding
+		target    string
+		maxHops   int
+		wantRate  string
+		wantValue string
+		wantRoute []string
+		wantNone  bool
+	}{
+		{
+			name:      "target asset",
+			holding:   valuation.Holding{Asset: "USDT", Amount: "10"},
+			target:    "USDT",
+			wantRate:  "1",
+			wantValue: "10",
+		},
+		{
+			name:      "direct market",
+			holding:   valuation.Holding{Asset: "BTC", Amount: "0.5"},
+			target:    "USDT",
+			wantRate:  "50000",
+			wantValue: "25000",
+			wantRoute: []string{"BTCUSDT"},
+		},
+		{
+			name:      "inverse market",
+			holding:   valuation.Holding{Asset: "USDT", Amount: "1000"},
+			target:    "BTC",
+			wantRate:  "1/50000",
+			wantValue: "1/50",
+			wantRoute: []string{"BTCUSDT"},
+		},
+		{
+			name:      "multi-hop route",
+			holding:   valuation.Holding{Asset: "XYZ", Amount: "1000"},
+			target:    "USDT",
+			wantRate:  "5/2",
+			wantValue: "2500",
+			wantRoute: []string{"XYZETH", "ETHBTC", "BTCUSDT"},
+		},
+		{
+			name:     "route longer than MaxHops",
+			holding:  valuation.Holding{Asset: "XYZ", Amount: "1000"},
+			target:   "USDT",
+			maxHops:  2,
+			wantNone: true,
+		},
+		{
+			name:     "market not trading",
+			holding:  valuation.Holding{Asset: "OLD", Amount: "1"},
+			target:   "USDT",
+			wantNone: true,
+		},
+		{
+			name:     "no market at all",
+			holding:  valuation.Holding{Asset: "NOPE", Amount: "1"},
+			target:   "USDT",
+			wantNone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := valuation.Value([]valuation.Holding{tt.holding}, tt.target, symbols, prices, valuation.Options{MaxHops: tt.maxHops})
+			if err != nil {
+				t.Fatalf("Value: %v", err)
+			}
+			if len(v.Assets) != 1 {
+				t.Fatalf("expected one asset, got %+v", v.Assets)
+			}
+			av := v.Assets[0]
+
+			if tt.wantNone {
+				if !av.NoRoute || !reflect.DeepEqual(v.Unpriced, []string{tt.holding.Asset}) || v.Total.Sign() != 0 {
+					t.Errorf("expected %s to be unpriced, got %+v and unpriced %v", tt.holding.Asset, av, v.Unpriced)
+				}
+				return
+			}
+
+			wantRate, _ := new(big.Rat).SetString(tt.wantRate)
+			wantValue, _ := new(big.Rat).SetString(tt.wantValue)
+			if av.NoRoute || av.Rate.Cmp(wantRate) != 0 || av.Value.Cmp(wantValue) != 0 || v.Total.Cmp(wantValue) != 0 {
+				t.Errorf("expected rate %s and value %s, got %+v with total %v", tt.wantRate, tt.wantValue, av, v.Total)
+			}
+
+			var route []string
+			for _, hop := range av.Route {
+				route = append(route, hop.Symbol)
+			}
+			if !reflect.DeepEqual(route, tt.wantRoute) {
+				t.Errorf("expected route %v, got %v", tt.wantRoute, route)
+			}
+		})
+	}
+}
+
+func TestValueRejectsInvalidPrice(t *testing.T) {
+	symbols := []models.SymbolInfo{{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT"}}
+	holdings := []valuation.Holding{{Asset: "BTC", Amount: "1"}}
+
+	if _, err := valuation.Value(holdings, "USDT", symbols, map[string]string{"BTCUSDT": "n/a"}, valuation.Options{}); err == nil {
+		t.Fatal("expected an invalid price error")
+	}
+}